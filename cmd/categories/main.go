@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/aidosgal/alem.core-service/internal/config"
+	"github.com/aidosgal/alem.core-service/internal/repository"
+	"github.com/aidosgal/alem.core-service/internal/service"
+	_ "github.com/lib/pq"
+)
+
+// Checks the category nested set for inconsistencies and, with -rebuild,
+// recomputes it from parent_id.
+func main() {
+	var rebuild bool
+	flag.BoolVar(&rebuild, "rebuild", false, "rebuild the nested set from parent_id when issues are found")
+	cfg := config.MustLoad()
+	flag.Parse()
+
+	postgresURL := fmt.Sprintf(
+		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.Database.Host,
+		cfg.Database.Port,
+		cfg.Database.Name,
+		cfg.Database.SSLMode,
+	)
+
+	db, err := sql.Open("postgres", postgresURL)
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	categoryService := service.NewCategoryService(
+		repository.NewCategoryRepository(db),
+		repository.NewCategoryAttributeRepository(logger, db),
+		repository.NewVacancyRepository(logger, db),
+		repository.NewResumeRepository(logger, db),
+		logger,
+	)
+
+	ctx := context.Background()
+
+	issues, err := categoryService.CheckCategoryTree(ctx)
+	if err != nil {
+		log.Fatalf("failed to check category tree: %v", err)
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("category tree is consistent")
+		return
+	}
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	fmt.Printf("found %d issues\n", len(issues))
+
+	if !rebuild {
+		os.Exit(1)
+	}
+
+	if err := categoryService.RebuildCategoryTree(ctx); err != nil {
+		log.Fatalf("failed to rebuild category tree: %v", err)
+	}
+
+	issues, err = categoryService.CheckCategoryTree(ctx)
+	if err != nil {
+		log.Fatalf("failed to check category tree: %v", err)
+	}
+	if len(issues) > 0 {
+		log.Fatalf("category tree still has %d issues after rebuild", len(issues))
+	}
+
+	fmt.Println("category tree rebuilt successfully")
+}
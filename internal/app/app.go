@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log/slog"
@@ -12,6 +13,7 @@ import (
 	"github.com/aidosgal/alem.core-service/internal/config"
 	"github.com/aidosgal/alem.core-service/internal/http/handler"
 	auth "github.com/aidosgal/alem.core-service/internal/http/middleware"
+	"github.com/aidosgal/alem.core-service/internal/model"
 	"github.com/aidosgal/alem.core-service/internal/repository"
 	"github.com/aidosgal/alem.core-service/internal/service"
 	"github.com/go-chi/chi/v5"
@@ -46,10 +48,11 @@ func (s *Server) Run() error {
 	router := chi.NewRouter()
 	router.Use(middleware.Logger)
 	router.Use(middleware.URLFormat)
+	router.Use(auth.Locale)
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Accept-Language", "Authorization", "Content-Type", "X-CSRF-Token"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: true,
 		MaxAge:           300,
@@ -78,16 +81,64 @@ func (s *Server) Run() error {
 	organizationService := service.NewOrganizationService(s.log, organizationRepository, userService)
 	organizationHandler := handler.NewOrganizationHandler(s.log, organizationService)
 
+	vacancyRepository := repository.NewVacancyRepository(s.log, db)
+	resumeRepository := repository.NewResumeRepository(s.log, db)
+
 	categoryRepository := repository.NewCategoryRepository(db)
-	categoryService := service.NewCategoryService(categoryRepository, s.log)
+	categoryAttributeRepository := repository.NewCategoryAttributeRepository(s.log, db)
+	categoryService := service.NewCategoryService(categoryRepository, categoryAttributeRepository, vacancyRepository, resumeRepository, s.log)
 	categoryHandler := handler.NewCategoryHandler(s.log, categoryService)
 
-	vacancyRepository := repository.NewVacancyRepository(s.log, db)
+	notificationRepository := repository.NewNotificationRepository(s.log, db)
+	notificationService := service.NewNotificationService(s.log, notificationRepository)
+	notificationHandler := handler.NewNotificationHandler(s.log, notificationService)
+
+	currencyRepository := repository.NewCurrencyRepository(s.log, db)
+	currencyService := service.NewCurrencyService(s.log, currencyRepository)
+	currencyHandler := handler.NewCurrencyHandler(s.log, currencyService)
+
+	locationRepository := repository.NewLocationRepository(s.log, db)
+	locationService := service.NewLocationService(s.log, locationRepository, vacancyRepository)
+	locationHandler := handler.NewLocationHandler(s.log, locationService)
+	if err := locationService.Seed(context.Background()); err != nil {
+		return err
+	}
+
 	vacancyDetailRepository := repository.NewVacancyDetailRepository(s.log, db)
-	vacancyService := service.NewVacancyService(s.log, vacancyRepository, vacancyDetailRepository, organizationService)
+	favoriteRepository := repository.NewFavoriteRepository(s.log, db)
+	vacancyStatsRepository := repository.NewVacancyStatsRepository(s.log, db)
+	moderationRepository := repository.NewModerationRepository(s.log, db)
+	vacancyFingerprintRepository := repository.NewVacancyFingerprintRepository(s.log, db)
+	vacancyTranslationRepository := repository.NewVacancyTranslationRepository(s.log, db)
+	translator, err := service.NewTranslator(s.cfg.Translation)
+	if err != nil {
+		return err
+	}
+	vacancyService := service.NewVacancyService(s.log, s.cfg.Vacancy, vacancyRepository, vacancyDetailRepository, favoriteRepository, vacancyStatsRepository, moderationRepository, vacancyFingerprintRepository, vacancyTranslationRepository, translator, categoryService, organizationService, notificationService, currencyService, locationService)
 	vacancyHandler := handler.NewVacancyHandler(s.log, vacancyService)
+	moderationHandler := handler.NewModerationHandler(s.log, vacancyService)
+
+	go vacancyService.RunJobs(context.Background())
+
+	vacancyTemplateRepository := repository.NewVacancyTemplateRepository(s.log, db)
+	vacancyTemplateService := service.NewVacancyTemplateService(s.log, vacancyTemplateRepository, vacancyService)
+	vacancyTemplateHandler := handler.NewVacancyTemplateHandler(s.log, vacancyTemplateService)
+
+	vacancyImportRepository := repository.NewVacancyImportRepository(s.log, db)
+	vacancyImportService := service.NewVacancyImportService(s.log, s.cfg.Import, vacancyImportRepository, vacancyService, categoryService, notificationService)
+	vacancyImportHandler := handler.NewVacancyImportHandler(s.log, vacancyImportService)
+
+	go vacancyImportService.RunImports(context.Background())
+
+	feedService := service.NewFeedService(s.log, s.cfg.Feed, vacancyRepository, organizationService, categoryService, locationService)
+	feedHandler := handler.NewFeedHandler(s.log, feedService, s.cfg.Feed.CacheTTL)
+
+	savedSearchRepository := repository.NewSavedSearchRepository(s.log, db)
+	savedSearchService := service.NewSavedSearchService(s.log, s.cfg.SavedSearch, savedSearchRepository, vacancyService, notificationService)
+	savedSearchHandler := handler.NewSavedSearchHandler(s.log, savedSearchService)
+
+	go savedSearchService.RunMatcher(context.Background())
 
-	resumeRepository := repository.NewResumeRepository(s.log, db)
 	resumeExperienceRepository := repository.NewResumeExperienceRepository(s.log, db)
 	resumeSkillRepository := repository.NewResumeSkillRepository(s.log, db)
 	resumeService := service.NewResumeService(
@@ -112,27 +163,122 @@ func (s *Server) Run() error {
 		apiRouter.Route("/organization", func(organizationRouter chi.Router) {
 			organizationRouter.Use(auth.AuthMiddleware)
 			organizationRouter.Get("/", organizationHandler.GetAllOrganizations)
+			organizationRouter.Get("/hidden", organizationHandler.ListHiddenOrganizations)
 			organizationRouter.Get("/{id}", organizationHandler.GetOrganization)
 			organizationRouter.Post("/", organizationHandler.CreateOrganization)
+			organizationRouter.Post("/{id}/hide", organizationHandler.HideOrganization)
+			organizationRouter.Delete("/{id}/hide", organizationHandler.UnhideOrganization)
+			organizationRouter.With(auth.RequireRole(model.UserRoleAdmin)).Put("/{id}/verified", organizationHandler.VerifyOrganization)
 		})
 		apiRouter.Route("/category", func(categoryRouter chi.Router) {
 			categoryRouter.Use(auth.AuthMiddleware)
 			categoryRouter.Get("/", categoryHandler.GetCategoryTree)
-			categoryRouter.Post("/", categoryHandler.CreateCategory)
+			categoryRouter.Get("/tree", categoryHandler.GetCategoryNestedTree)
 			categoryRouter.Get("/{id}", categoryHandler.GetCategoryByID)
+			categoryRouter.Get("/{id}/ancestors", categoryHandler.GetCategoryAncestors)
+			categoryRouter.Get("/{id}/attributes", categoryHandler.ListCategoryAttributes)
+			categoryRouter.Group(func(adminRouter chi.Router) {
+				adminRouter.Use(auth.RequireRole(model.UserRoleAdmin))
+				adminRouter.Post("/", categoryHandler.CreateCategory)
+				adminRouter.Post("/reorder", categoryHandler.ReorderCategories)
+				adminRouter.Put("/{id}", categoryHandler.UpdateCategory)
+				adminRouter.Delete("/{id}", categoryHandler.DeleteCategory)
+				adminRouter.Post("/{id}/move", categoryHandler.MoveCategory)
+				adminRouter.Get("/export", categoryHandler.ExportCategories)
+				adminRouter.Post("/import", categoryHandler.ImportCategories)
+				adminRouter.Get("/{id}/translations", categoryHandler.ListCategoryTranslations)
+				adminRouter.Put("/{id}/translations/{locale}", categoryHandler.UpsertCategoryTranslation)
+				adminRouter.Delete("/{id}/translations/{locale}", categoryHandler.DeleteCategoryTranslation)
+				adminRouter.Put("/{id}/attributes/{key}", categoryHandler.UpsertCategoryAttribute)
+				adminRouter.Delete("/{id}/attributes/{key}", categoryHandler.DeleteCategoryAttribute)
+			})
 		})
 		apiRouter.Route("/vacancy", func(vacancyRouter chi.Router) {
 			vacancyRouter.Use(auth.AuthMiddleware)
 			vacancyRouter.Post("/", vacancyHandler.CreateVacancy)
 			vacancyRouter.Get("/", vacancyHandler.ListVacancies)
+			vacancyRouter.Get("/favorites", vacancyHandler.ListFavorites)
+			vacancyRouter.Post("/imports", vacancyImportHandler.UploadVacancies)
+			vacancyRouter.Get("/imports/{id}", vacancyImportHandler.GetVacancyImport)
+			vacancyRouter.Post("/imports/{id}/commit", vacancyImportHandler.CommitVacancyImport)
+			vacancyRouter.Post("/templates", vacancyTemplateHandler.CreateTemplate)
+			vacancyRouter.Get("/templates", vacancyTemplateHandler.ListTemplates)
+			vacancyRouter.Get("/templates/{id}", vacancyTemplateHandler.GetTemplate)
+			vacancyRouter.Put("/templates/{id}", vacancyTemplateHandler.UpdateTemplate)
+			vacancyRouter.Delete("/templates/{id}", vacancyTemplateHandler.DeleteTemplate)
+			vacancyRouter.Get("/templates/{id}/versions", vacancyTemplateHandler.ListTemplateVersions)
+			vacancyRouter.Post("/templates/{id}/vacancies", vacancyTemplateHandler.CreateVacancyFromTemplate)
 			vacancyRouter.Get("/{id}", vacancyHandler.GetVacancy)
 			vacancyRouter.Put("/{id}", vacancyHandler.UpdateVacancy)
+			vacancyRouter.Delete("/{id}", vacancyHandler.DeleteVacancy)
+			vacancyRouter.Post("/{id}/restore", vacancyHandler.RestoreVacancy)
+			vacancyRouter.Post("/{id}/template", vacancyTemplateHandler.SaveVacancyAsTemplate)
+			vacancyRouter.Post("/{id}/favorite", vacancyHandler.AddFavorite)
+			vacancyRouter.Delete("/{id}/favorite", vacancyHandler.RemoveFavorite)
+			vacancyRouter.Post("/{id}/status", vacancyHandler.ChangeVacancyStatus)
+			vacancyRouter.Post("/{id}/apply", vacancyHandler.ApplyToVacancy)
+			vacancyRouter.Get("/{id}/stats", vacancyHandler.GetVacancyStats)
+			vacancyRouter.Get("/{id}/moderation", moderationHandler.GetVacancyModerationLog)
+			vacancyRouter.Get("/{id}/versions", vacancyHandler.ListVacancyVersions)
+			vacancyRouter.Get("/{id}/versions/{version}", vacancyHandler.GetVacancyVersion)
+			vacancyRouter.Get("/{id}/translations", vacancyHandler.ListVacancyTranslations)
+			vacancyRouter.Put("/{id}/translations/{locale}", vacancyHandler.UpsertVacancyTranslation)
+			vacancyRouter.Delete("/{id}/translations/{locale}", vacancyHandler.DeleteVacancyTranslation)
+			vacancyRouter.Post("/{id}/translations/{locale}/draft", vacancyHandler.DraftVacancyTranslation)
+		})
+		apiRouter.Route("/moderation", func(moderationRouter chi.Router) {
+			moderationRouter.Use(auth.AuthMiddleware)
+			moderationRouter.Use(auth.RequireRole(model.UserRoleModerator, model.UserRoleAdmin))
+			moderationRouter.Get("/vacancies", moderationHandler.ListQueue)
+			moderationRouter.Post("/vacancies/{id}/approve", moderationHandler.ApproveVacancy)
+			moderationRouter.Post("/vacancies/{id}/reject", moderationHandler.RejectVacancy)
+			moderationRouter.Post("/vacancies/{id}/request-changes", moderationHandler.RequestVacancyChanges)
+			moderationRouter.Get("/vacancies/{id}/log", moderationHandler.GetModerationLog)
+			moderationRouter.Get("/vacancies/{id}/versions", vacancyHandler.ListModeratedVacancyVersions)
+			moderationRouter.Get("/vacancies/{id}/versions/{version}", vacancyHandler.GetModeratedVacancyVersion)
+			moderationRouter.Get("/blacklist", moderationHandler.ListBlacklist)
+			moderationRouter.Post("/blacklist", moderationHandler.AddBlacklistEntry)
+			moderationRouter.Delete("/blacklist/{id}", moderationHandler.DeleteBlacklistEntry)
+		})
+		apiRouter.Route("/saved-searches", func(savedSearchRouter chi.Router) {
+			savedSearchRouter.Get("/unsubscribe/{token}", savedSearchHandler.Unsubscribe)
+			savedSearchRouter.Group(func(userRouter chi.Router) {
+				userRouter.Use(auth.AuthMiddleware)
+				userRouter.Post("/", savedSearchHandler.CreateSavedSearch)
+				userRouter.Get("/", savedSearchHandler.ListSavedSearches)
+				userRouter.Delete("/{id}", savedSearchHandler.DeleteSavedSearch)
+			})
+		})
+		apiRouter.Route("/notifications", func(notificationRouter chi.Router) {
+			notificationRouter.Use(auth.AuthMiddleware)
+			notificationRouter.Get("/", notificationHandler.ListNotifications)
+			notificationRouter.Post("/{id}/read", notificationHandler.MarkRead)
+		})
+		apiRouter.Route("/currencies", func(currencyRouter chi.Router) {
+			currencyRouter.Use(auth.AuthMiddleware)
+			currencyRouter.Get("/", currencyHandler.ListCurrencies)
+			currencyRouter.With(auth.RequireRole(model.UserRoleAdmin)).Put("/{code}", currencyHandler.UpsertCurrency)
+		})
+		apiRouter.Route("/locations", func(locationRouter chi.Router) {
+			locationRouter.Use(auth.AuthMiddleware)
+			locationRouter.Get("/countries", locationHandler.ListCountries)
+			locationRouter.Get("/countries/open", locationHandler.ListOpenCountries)
+			locationRouter.Get("/countries/{code}/regions", locationHandler.ListRegions)
+			locationRouter.Get("/regions/{id}/cities", locationHandler.ListCities)
+		})
+		// The feed is public; URLFormat strips the .xml and .jsonld
+		// extensions crawlers usually request.
+		apiRouter.Route("/feed", func(feedRouter chi.Router) {
+			feedRouter.Get("/jobs", feedHandler.JobFeed)
+			feedRouter.Get("/jobs/{id}", feedHandler.JobPosting)
 		})
 		apiRouter.Route("/resumes", func(resumeRouter chi.Router) {
 			resumeRouter.Use(auth.AuthMiddleware)
 			resumeRouter.Post("/", resumeHandler.CreateResume)
 			resumeRouter.Get("/", resumeHandler.ListResume)
 			resumeRouter.Get("/{resume_id}", resumeHandler.GetResume)
+			resumeRouter.Put("/{resume_id}", resumeHandler.UpdateResume)
+			resumeRouter.Delete("/{resume_id}", resumeHandler.DeleteResume)
 		})
 		apiRouter.Get("/messages/ws", wsHandler.HandleWebSocket)
 		apiRouter.Route("/messages", func(wsRouter chi.Router) {
@@ -3,14 +3,20 @@ package config
 import (
 	"flag"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
-	Env      string         `yaml:"env" env-default:"local"`
-	Database DatabaseConfig `yaml:"database"`
-	Port     int            `yaml:"port"`
+	Env         string            `yaml:"env" env-default:"local"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Port        int               `yaml:"port"`
+	Vacancy     VacancyConfig     `yaml:"vacancy"`
+	SavedSearch SavedSearchConfig `yaml:"saved_search"`
+	Import      ImportConfig      `yaml:"import"`
+	Feed        FeedConfig        `yaml:"feed"`
+	Translation TranslationConfig `yaml:"translation"`
 }
 
 type DatabaseConfig struct {
@@ -22,6 +28,71 @@ type DatabaseConfig struct {
 	SSLMode  string `yaml:"sslmode"`
 }
 
+// VacancyConfig controls how long published vacancies stay live, how long
+// deleted ones are kept and how often the background jobs run.
+type VacancyConfig struct {
+	TTL           time.Duration    `yaml:"ttl" env-default:"720h"`
+	ExpiryWarning time.Duration    `yaml:"expiry_warning" env-default:"72h"`
+	RestoreWindow time.Duration    `yaml:"restore_window" env-default:"168h"`
+	Retention     time.Duration    `yaml:"retention" env-default:"2160h"`
+	JobInterval   time.Duration    `yaml:"job_interval" env-default:"1h"`
+	Moderation    ModerationConfig `yaml:"moderation"`
+	Duplicates    DuplicateConfig  `yaml:"duplicates"`
+}
+
+// DuplicateConfig controls near-duplicate detection. Vacancies in the same
+// category and location (country, region and city) whose texts are at least Similarity alike (0 to 1)
+// are duplicates. Vacancies created before detection existed are
+// fingerprinted BackfillBatch at a time by the vacancy jobs.
+type DuplicateConfig struct {
+	Similarity    float64 `yaml:"similarity" env-default:"0.8"`
+	BackfillBatch int     `yaml:"backfill_batch" env-default:"500"`
+}
+
+// ModerationConfig controls the automatic risk rules. Vacancies scoring at
+// least ReviewScore go to the moderator queue. A salary above SalaryFactor
+// times the category median is unrealistic once the category has
+// SalarySamples published vacancies with a salary; until then MaxSalary, a
+// monthly amount in the base currency, is the limit.
+type ModerationConfig struct {
+	ReviewScore   int     `yaml:"review_score" env-default:"50"`
+	SalaryFactor  float64 `yaml:"salary_factor" env-default:"3"`
+	SalarySamples int     `yaml:"salary_samples" env-default:"10"`
+	MaxSalary     float64 `yaml:"max_salary" env-default:"10000"`
+}
+
+// SavedSearchConfig controls the saved search matcher. Instant searches are
+// checked every MatchInterval, daily ones once a day.
+type SavedSearchConfig struct {
+	MatchInterval time.Duration `yaml:"match_interval" env-default:"10m"`
+	MaxPerUser    int           `yaml:"max_per_user" env-default:"20"`
+}
+
+// ImportConfig limits uploaded vacancy files and controls how often the
+// import job looks for committed imports. A running import whose progress
+// has not been saved for Lease is taken to be abandoned and queued again.
+type ImportConfig struct {
+	MaxRows      int           `yaml:"max_rows" env-default:"500"`
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"5s"`
+	Lease        time.Duration `yaml:"lease" env-default:"5m"`
+}
+
+// FeedConfig controls the public job feed. SiteURL is the public address of
+// the web app used for vacancy links; they are left out when it is empty.
+type FeedConfig struct {
+	Publisher string        `yaml:"publisher" env-default:"Alem"`
+	SiteURL   string        `yaml:"site_url"`
+	CacheTTL  time.Duration `yaml:"cache_ttl" env-default:"15m"`
+	MaxItems  int           `yaml:"max_items" env-default:"5000"`
+}
+
+// TranslationConfig selects the machine translator that drafts vacancy
+// translations. The "noop" provider copies the original text for the
+// employer to translate.
+type TranslationConfig struct {
+	Provider string `yaml:"provider" env-default:"noop"`
+}
+
 func MustLoad() *Config {
 	path := fetchConfigPath()
 	if path == "" {
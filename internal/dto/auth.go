@@ -27,6 +27,7 @@ type User struct {
 	Organization   Organization `json:"organization"`
 	Phone          string       `json:"phone"`
 	Password       string       `json:"password"`
+	Role           string       `json:"role,omitempty"`
 	AvatarURL      string       `json:"avatar_url"`
 	Balance        float64      `json:"balance"`
 	CreatedAt      string       `json:"created_at"`
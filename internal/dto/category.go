@@ -1,5 +1,7 @@
 package dto
 
+import "encoding/json"
+
 type CreateCategory struct {
 	Name     string `json:"name"`
 	ParentID *int   `json:"parent_id"`
@@ -9,6 +11,32 @@ type UpdateCategory struct {
 	ID       int     `json:"id"`
 	Name     string  `json:"name"`
 	ParentID *int    `json:"parent_id"`
+	// Move is set when the body has parent_id, so that a missing parent_id
+	// keeps the category where it is and a null one moves it to the top.
+	Move bool `json:"-"`
+}
+
+func (c *UpdateCategory) UnmarshalJSON(data []byte) error {
+	type category UpdateCategory
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*category)(c)); err != nil {
+		return err
+	}
+	_, c.Move = fields["parent_id"]
+	return nil
+}
+
+type MoveCategory struct {
+	ParentID *int `json:"parent_id"`
+	Position *int `json:"position"`
+}
+
+type ReorderCategories struct {
+	ParentID *int  `json:"parent_id"`
+	IDs      []int `json:"ids"`
 }
 
 type CategoryResponse struct {
@@ -19,3 +47,39 @@ type CategoryResponse struct {
 	Right    int     `json:"rgt"`
 	Depth    int     `json:"depth"`
 }
+
+// CategoryNode is a category with its children nested inside it. Counts are
+// only filled when requested and include every descendant.
+type CategoryNode struct {
+	ID           int            `json:"id"`
+	Name         string         `json:"name"`
+	ParentID     *int           `json:"parent_id"`
+	Depth        int            `json:"depth"`
+	VacancyCount *int           `json:"vacancy_count,omitempty"`
+	ResumeCount  *int           `json:"resume_count,omitempty"`
+	Children     []CategoryNode `json:"children"`
+}
+
+type CategoryTranslation struct {
+	Locale string `json:"locale"`
+	Name   string `json:"name"`
+}
+
+type UpsertCategoryTranslation struct {
+	Name string `json:"name"`
+}
+
+// CategoryExportNode is the JSON shape used to export and import the whole
+// category tree. Nodes without an ID are created on import.
+type CategoryExportNode struct {
+	ID           int                  `json:"id,omitempty"`
+	Name         string               `json:"name"`
+	Translations map[string]string    `json:"translations,omitempty"`
+	Children     []CategoryExportNode `json:"children,omitempty"`
+}
+
+type CategoryImportResult struct {
+	Created      int `json:"created"`
+	Updated      int `json:"updated"`
+	Translations int `json:"translations"`
+}
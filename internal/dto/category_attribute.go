@@ -0,0 +1,40 @@
+package dto
+
+// CategoryAttribute is an attribute in the schema of a category. Inherited
+// attributes are defined on one of its ancestors, in CategoryID.
+type CategoryAttribute struct {
+	Key        string   `json:"key"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Options    []string `json:"options,omitempty"`
+	Unit       string   `json:"unit,omitempty"`
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	Required   bool     `json:"required"`
+	CategoryID int      `json:"category_id"`
+	Inherited  bool     `json:"inherited"`
+}
+
+// UpsertCategoryAttribute defines an attribute of a category. The type of a
+// key is the same in every category and cannot be changed once defined.
+type UpsertCategoryAttribute struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options"`
+	Unit     string   `json:"unit"`
+	Min      *float64 `json:"min"`
+	Max      *float64 `json:"max"`
+	Required bool     `json:"required"`
+}
+
+// AttributeFilter is a parsed attr[key] filter of a vacancy list. Booleans
+// match Bool, enums any of the lowercased Values, numbers fall within From
+// and To and ranges overlap them.
+type AttributeFilter struct {
+	Key    string
+	Type   string
+	Bool   bool
+	Values []string
+	From   *float64
+	To     *float64
+}
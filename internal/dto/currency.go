@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type Currency struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Rate      float64   `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UpsertCurrency struct {
+	Name string  `json:"name"`
+	Rate float64 `json:"rate"`
+}
+
+type ListCurrenciesResponse struct {
+	Base       string     `json:"base"`
+	Currencies []Currency `json:"currencies"`
+}
+
+// Salary is a vacancy salary converted into a monthly amount in the
+// requested display currency.
+type Salary struct {
+	From     *float64 `json:"from,omitempty"`
+	To       *float64 `json:"to,omitempty"`
+	Exact    *float64 `json:"exact,omitempty"`
+	Currency string   `json:"currency"`
+	Period   string   `json:"period"`
+}
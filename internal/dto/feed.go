@@ -0,0 +1,99 @@
+package dto
+
+import "encoding/xml"
+
+// JobFeed is the XML job feed read by job aggregators, in the widely
+// supported Indeed layout. Truncated is set when more vacancies match than
+// the feed lists; a feed of changes then continues with NextSince and
+// NextAfter passed as since and after.
+type JobFeed struct {
+	XMLName       xml.Name  `xml:"source"`
+	Publisher     string    `xml:"publisher"`
+	PublisherURL  string    `xml:"publisherurl,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Truncated     bool      `xml:"truncated,omitempty"`
+	NextSince     string    `xml:"nextsince,omitempty"`
+	NextAfter     string    `xml:"nextafter,omitempty"`
+	Jobs          []FeedJob `xml:"job"`
+}
+
+type FeedJob struct {
+	Title           CDATA  `xml:"title"`
+	Date            string `xml:"date"`
+	ReferenceNumber string `xml:"referencenumber"`
+	URL             string `xml:"url,omitempty"`
+	Company         CDATA  `xml:"company"`
+	City            string `xml:"city,omitempty"`
+	State           string `xml:"state,omitempty"`
+	Country         string `xml:"country,omitempty"`
+	Description     CDATA  `xml:"description"`
+	Salary          string `xml:"salary,omitempty"`
+	Category        string `xml:"category,omitempty"`
+	ExpirationDate  string `xml:"expirationdate,omitempty"`
+}
+
+// CDATA is text written as an XML CDATA section.
+type CDATA struct {
+	Value string `xml:",cdata"`
+}
+
+// JobPosting is the schema.org JobPosting of a vacancy, served as JSON-LD.
+type JobPosting struct {
+	Context              string             `json:"@context"`
+	Type                 string             `json:"@type"`
+	Title                string             `json:"title"`
+	Description          string             `json:"description"`
+	Identifier           PropertyValue      `json:"identifier"`
+	DatePosted           string             `json:"datePosted"`
+	ValidThrough         string             `json:"validThrough,omitempty"`
+	URL                  string             `json:"url,omitempty"`
+	HiringOrganization   JobPostingEmployer `json:"hiringOrganization"`
+	JobLocation          *JobPostingPlace   `json:"jobLocation,omitempty"`
+	BaseSalary           *JobPostingSalary  `json:"baseSalary,omitempty"`
+	OccupationalCategory string             `json:"occupationalCategory,omitempty"`
+}
+
+type PropertyValue struct {
+	Type  string `json:"@type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type JobPostingEmployer struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type JobPostingPlace struct {
+	Type    string            `json:"@type"`
+	Address JobPostingAddress `json:"address"`
+	Geo     *JobPostingGeo    `json:"geo,omitempty"`
+}
+
+type JobPostingAddress struct {
+	Type            string `json:"@type"`
+	AddressLocality string `json:"addressLocality,omitempty"`
+	AddressRegion   string `json:"addressRegion,omitempty"`
+	AddressCountry  string `json:"addressCountry"`
+}
+
+type JobPostingGeo struct {
+	Type      string  `json:"@type"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type JobPostingSalary struct {
+	Type     string                `json:"@type"`
+	Currency string                `json:"currency"`
+	Value    JobPostingSalaryValue `json:"value"`
+}
+
+// JobPostingSalaryValue holds either Value or a MinValue/MaxValue range.
+type JobPostingSalaryValue struct {
+	Type     string   `json:"@type"`
+	Value    *float64 `json:"value,omitempty"`
+	MinValue *float64 `json:"minValue,omitempty"`
+	MaxValue *float64 `json:"maxValue,omitempty"`
+	UnitText string   `json:"unitText"`
+}
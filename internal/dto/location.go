@@ -0,0 +1,37 @@
+package dto
+
+type Country struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	VacancyCount *int   `json:"vacancy_count,omitempty"`
+}
+
+type Region struct {
+	ID          int    `json:"id"`
+	CountryCode string `json:"country_code"`
+	Name        string `json:"name"`
+}
+
+type City struct {
+	ID        int      `json:"id"`
+	RegionID  int      `json:"region_id"`
+	Name      string   `json:"name"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// Location describes where a vacancy is, with names resolved from the
+// reference data.
+type Location struct {
+	CountryCode string `json:"country_code"`
+	Country     string `json:"country"`
+	RegionID    *int   `json:"region_id,omitempty"`
+	Region      string `json:"region,omitempty"`
+	CityID      *int   `json:"city_id,omitempty"`
+	City        string `json:"city,omitempty"`
+}
+
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
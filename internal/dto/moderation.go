@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+// ModerationDecision carries the moderator's reason. It is required to
+// reject a vacancy or request changes and shown to the employer.
+type ModerationDecision struct {
+	Reason string `json:"reason"`
+}
+
+type RiskFlag struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+// ModerationLogEntry is an automatic flag or a moderator decision. Risk
+// details are only included for moderators.
+type ModerationLogEntry struct {
+	ID          int64      `json:"id"`
+	Action      string     `json:"action"`
+	Reason      string     `json:"reason"`
+	ModeratorID *int64     `json:"moderator_id,omitempty"`
+	RiskScore   *int       `json:"risk_score,omitempty"`
+	Flags       []RiskFlag `json:"flags,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type ModerationQueueItem struct {
+	Vacancy   Vacancy    `json:"vacancy"`
+	RiskScore int        `json:"risk_score"`
+	Flags     []RiskFlag `json:"flags"`
+}
+
+type ModerationQueue struct {
+	Items []ModerationQueueItem `json:"items"`
+	Total int                   `json:"total"`
+}
+
+type CreateBlacklistEntry struct {
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+type BlacklistEntry struct {
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
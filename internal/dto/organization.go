@@ -5,4 +5,11 @@ type Organization struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Users       []User `json:"users"`
+	Verified    bool   `json:"verified"`
+}
+
+// VerifyOrganization marks an organization as checked by an administrator,
+// or withdraws the mark.
+type VerifyOrganization struct {
+	Verified bool `json:"verified"`
 }
@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+// SavedSearchFilters are the ListVacancyRequest filters a saved search
+// remembers.
+type SavedSearchFilters struct {
+	CategoryIDs  []int     `json:"category_ids,omitempty"`
+	SalaryFrom   float64   `json:"salary_from,omitempty"`
+	SalaryTo     float64   `json:"salary_to,omitempty"`
+	Currency     string    `json:"currency,omitempty"`
+	Search       string    `json:"search,omitempty"`
+	CountryCodes []string  `json:"country_codes,omitempty"`
+	RegionIDs    []int     `json:"region_ids,omitempty"`
+	CityIDs      []int     `json:"city_ids,omitempty"`
+	Near         *GeoPoint `json:"near,omitempty"`
+	RadiusKm     float64   `json:"radius_km,omitempty"`
+	// Attributes are attr[key] filters on typed category attributes.
+	Attributes map[string][]string `json:"attributes,omitempty"`
+	Verified   *bool               `json:"verified,omitempty"`
+}
+
+type CreateSavedSearch struct {
+	Name      string             `json:"name"`
+	Filters   SavedSearchFilters `json:"filters"`
+	Frequency string             `json:"frequency"`
+}
+
+type SavedSearch struct {
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`
+	Filters       SavedSearchFilters `json:"filters"`
+	Frequency     string             `json:"frequency"`
+	Active        bool               `json:"active"`
+	LastCheckedAt *time.Time         `json:"last_checked_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
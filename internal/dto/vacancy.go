@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type CreateVacancyRequest struct {
 	Vacancy Vacancy `json:"vacancy"`
 }
@@ -10,24 +12,114 @@ type CreateVacancyResponse struct {
 
 type UpdateVacancyRequest struct {
 	Vacancy Vacancy `json:"vacancy"`
+	// AuthorID is the user making the change, recorded in the vacancy's
+	// version history.
+	AuthorID int64 `json:"-"`
 }
 
 type UpdateVacancyResponse struct {
 	Vacancy Vacancy `json:"vacancy"`
 }
 
+const (
+	VacancySortNewest     = "newest"
+	VacancySortSalaryAsc  = "salary_asc"
+	VacancySortSalaryDesc = "salary_desc"
+	VacancySortPublished  = "published"
+	// VacancySortDistance only applies together with Near.
+	VacancySortDistance = "distance"
+	// VacancySortTrending ranks vacancies by views and applications over
+	// the last week.
+	VacancySortTrending = "trending"
+	// VacancySortRelevance ranks matches of Search in the title above
+	// matches elsewhere. It only applies together with Search.
+	VacancySortRelevance = "relevance"
+)
+
+// Totals of a vacancy list. An approximate total is the planner's estimate
+// for large lists and an exact count for small ones.
+const (
+	VacancyTotalExact       = "exact"
+	VacancyTotalApproximate = "approximate"
+	VacancyTotalNone        = "none"
+)
+
+// ListVacancyRequest lists published vacancies. With Mine set it lists every
+// vacancy of the viewer's organization instead, optionally narrowed to
+// Statuses. SalaryFrom and SalaryTo are monthly amounts in Currency, which
+// defaults to the base currency.
 type ListVacancyRequest struct {
-	CategoryID int     `json:"category_id"`
-	SalaryFrom float64 `json:"salary_from"`
-	SalaryTo   float64 `json:"salary_to"`
-	Search     string  `json:"search"`
-	Limit      int     `json:"limit"`
-	Offset     int     `json:"offset"`
+	CategoryIDs          []int     `json:"category_ids"`
+	SalaryFrom           float64   `json:"salary_from"`
+	SalaryTo             float64   `json:"salary_to"`
+	Currency             string    `json:"currency"`
+	Sort                 string    `json:"sort"`
+	CountryCodes         []string  `json:"country_codes"`
+	RegionIDs            []int     `json:"region_ids"`
+	CityIDs              []int     `json:"city_ids"`
+	Near                 *GeoPoint `json:"near"`
+	RadiusKm             float64   `json:"radius_km"`
+	Search               string    `json:"search"`
+	Mine                 bool      `json:"mine"`
+	Statuses             []string  `json:"statuses"`
+	ViewerOrganizationID int64     `json:"-"`
+	// ViewerUserID hides the vacancies of organizations the user has hidden.
+	ViewerUserID int64 `json:"-"`
+	// FavoritesOf limits the list to vacancies the user has bookmarked.
+	FavoritesOf int64 `json:"-"`
+	// PublishedAfter limits the list to vacancies published after the
+	// (PublishedAfter, PublishedAfterID) position, for saved search alerts.
+	PublishedAfter   *time.Time `json:"-"`
+	PublishedAfterID int64      `json:"-"`
+	Limit            int        `json:"limit"`
+	Offset           int        `json:"offset"`
+	// CollapseDuplicates lists each cluster of near-duplicate vacancies
+	// from different organizations once. It is ignored with Mine.
+	CollapseDuplicates bool `json:"collapse_duplicates"`
+	// Attributes filters on typed category attributes by key, as given in
+	// attr[key] query parameters. The service resolves them into
+	// AttributeFilters.
+	Attributes       map[string][]string `json:"attributes"`
+	AttributeFilters []AttributeFilter   `json:"-"`
+	// Cursor continues a list from the NextCursor of its previous page,
+	// which must have had the same filters and Sort. Offset is ignored with
+	// a cursor. The service decodes it into After.
+	Cursor string         `json:"cursor"`
+	After  *VacancyCursor `json:"-"`
+	// Total is one of the VacancyTotal modes. It defaults to approximate
+	// on the first page and to none on the following ones.
+	Total string `json:"total"`
+	// Verified limits the list to vacancies of verified employers, or of
+	// unverified ones when false.
+	Verified *bool `json:"verified"`
+	// Facets adds the counts of each filter option to the response.
+	Facets bool `json:"facets"`
+}
+
+// VacancyCursor is the position of the last vacancy of a page: its sort key
+// as text and its ID.
+type VacancyCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int64  `json:"i"`
+}
+
+// ChangeVacancyStatusRequest moves a vacancy to another status. ExpiresAt is
+// only used when publishing.
+type ChangeVacancyStatusRequest struct {
+	Status    string     `json:"status"`
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
+// ListVacancyResponse is a page of vacancies. Total is left out when it was
+// not asked for, and NextCursor when there are no more pages.
 type ListVacancyResponse struct {
-	Vacancie []Vacancy `json:"vacancies"`
-	Total    int       `json:"total"`
+	Vacancie         []Vacancy `json:"vacancies"`
+	Total            *int      `json:"total,omitempty"`
+	TotalApproximate bool      `json:"total_approximate,omitempty"`
+	NextCursor       string    `json:"next_cursor,omitempty"`
+	// Facets are only set when asked for.
+	Facets *VacancyFacets `json:"facets,omitempty"`
 }
 
 type Vacancy struct {
@@ -39,14 +131,39 @@ type Vacancy struct {
 	SalaryExact    *float64                `json:"salary_exact,omitempty"`
 	SalaryType     string                  `json:"salary_type"`
 	SalaryCurrency string                  `json:"salary_currency"`
+	DisplaySalary  *Salary                 `json:"display_salary,omitempty"`
 	OrganizationID int64                   `json:"organization_id"`
 	Organization   Organization            `json:"organization"`
 	CategoryID     int64                   `json:"category_id"`
 	Country        string                  `json:"country"`
+	CountryCode    string                  `json:"country_code,omitempty"`
+	RegionID       *int                    `json:"region_id,omitempty"`
+	CityID         *int                    `json:"city_id,omitempty"`
+	Location       *Location               `json:"location,omitempty"`
+	Latitude       *float64                `json:"latitude,omitempty"`
+	Longitude      *float64                `json:"longitude,omitempty"`
+	DistanceKm     *float64                `json:"distance_km,omitempty"`
+	IsFavorite     bool                    `json:"is_favorite"`
 	Category       CategoryResponse        `json:"category"`
 	Details        []VacancyDetailResponse `json:"details"`
+	Status         string                  `json:"status"`
+	PublishedAt    *time.Time              `json:"published_at,omitempty"`
+	ExpiresAt      *time.Time              `json:"expires_at,omitempty"`
 	CreatedAt      string                  `json:"created_at"`
 	UpdatedAt      string                  `json:"updated_at"`
+	// DuplicateClusterID groups near-duplicates posted by other
+	// organizations; Duplicates counts the ones a collapsed list hides.
+	DuplicateClusterID *int64 `json:"duplicate_cluster_id,omitempty"`
+	Duplicates         int    `json:"duplicates,omitempty"`
+	// PrimaryLocale is the language the vacancy is written in and Locale
+	// the one Title and Description are returned in. Translations are only
+	// read when a vacancy is created.
+	PrimaryLocale string               `json:"primary_locale,omitempty"`
+	Locale        string               `json:"locale,omitempty"`
+	Translations  []VacancyTranslation `json:"translations,omitempty"`
+	// Version is the version of the vacancy text; applications record the
+	// version the candidate saw.
+	Version int `json:"version,omitempty"`
 }
 
 type VacancyDetailResponse struct {
@@ -58,4 +175,6 @@ type VacancyDetailResponse struct {
 	VacancyID int64  `json:"vacancy_id"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
+	// Key is set on details holding a typed attribute of the category.
+	Key string `json:"key,omitempty"`
 }
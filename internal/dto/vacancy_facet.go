@@ -0,0 +1,31 @@
+package dto
+
+// VacancyFacets tells how many vacancies each filter option would give.
+// Every facet applies the other filters of the list but not its own, and
+// leaves out options without vacancies.
+type VacancyFacets struct {
+	Categories []FacetCount  `json:"categories"`
+	Countries  []FacetCount  `json:"countries"`
+	Salaries   []SalaryFacet `json:"salaries"`
+	Currencies []FacetCount  `json:"currencies"`
+	Verified   []FacetCount  `json:"verified"`
+}
+
+// FacetCount is a filter option with its number of vacancies. Value is what
+// the filter takes: a top-level category ID, a country code, a currency
+// code or true and false for verified employers.
+type FacetCount struct {
+	Value string `json:"value"`
+	Name  string `json:"name,omitempty"`
+	Count int    `json:"count"`
+}
+
+// SalaryFacet is a salary range in the display currency of the list, from
+// inclusive to exclusive. The lowest range has no From and the highest no
+// To.
+type SalaryFacet struct {
+	From     *float64 `json:"from,omitempty"`
+	To       *float64 `json:"to,omitempty"`
+	Currency string   `json:"currency"`
+	Count    int      `json:"count"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+// VacancyImportRow is one row of an uploaded file. Line is the line of the
+// row in a CSV or XLSX file and the 1-based index of the object in a JSON
+// array.
+type VacancyImportRow struct {
+	Line    int     `json:"line"`
+	Vacancy Vacancy `json:"vacancy"`
+}
+
+type VacancyImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// VacancyImport is the dry-run report of an upload and, once committed, the
+// progress of the import job.
+type VacancyImport struct {
+	ID            int64                `json:"id"`
+	FileName      string               `json:"file_name"`
+	Format        string               `json:"format"`
+	Status        string               `json:"status"`
+	TotalRows     int                  `json:"total_rows"`
+	ValidRows     int                  `json:"valid_rows"`
+	ProcessedRows int                  `json:"processed_rows"`
+	CreatedRows   int                  `json:"created_rows"`
+	Errors        []VacancyImportError `json:"errors"`
+	Preview       []VacancyImportRow   `json:"preview,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	StartedAt     *time.Time           `json:"started_at,omitempty"`
+	FinishedAt    *time.Time           `json:"finished_at,omitempty"`
+}
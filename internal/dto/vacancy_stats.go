@@ -0,0 +1,33 @@
+package dto
+
+// ApplyVacancyRequest applies to a vacancy. VacancyVersion is the version
+// the candidate saw; applying fails when the vacancy has changed since.
+// Without it the current version is recorded.
+type ApplyVacancyRequest struct {
+	ResumeID       *int64 `json:"resume_id"`
+	VacancyVersion *int   `json:"vacancy_version"`
+}
+
+type VacancyApplication struct {
+	ID        int64  `json:"id"`
+	VacancyID int64  `json:"vacancy_id"`
+	ResumeID  *int64 `json:"resume_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+	// VacancyVersion is the version of the vacancy the candidate saw.
+	VacancyVersion int `json:"vacancy_version"`
+}
+
+type VacancyDailyStats struct {
+	Day          string `json:"day"`
+	Views        int    `json:"views"`
+	Applications int    `json:"applications"`
+}
+
+type VacancyStats struct {
+	VacancyID     int64               `json:"vacancy_id"`
+	Days          int                 `json:"days"`
+	Views         int                 `json:"views"`
+	UniqueViewers int                 `json:"unique_viewers"`
+	Applications  int                 `json:"applications"`
+	Daily         []VacancyDailyStats `json:"daily"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// VacancyTemplate is a reusable vacancy. Vacancy holds the fields copied
+// into new vacancies, in its latest version unless another one was asked
+// for.
+type VacancyTemplate struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Shared    bool      `json:"shared"`
+	AuthorID  int64     `json:"author_id"`
+	Version   int       `json:"version"`
+	Vacancy   Vacancy   `json:"vacancy"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type VacancyTemplateVersion struct {
+	Version   int       `json:"version"`
+	AuthorID  int64     `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateVacancyTemplate struct {
+	Name    string  `json:"name"`
+	Shared  bool    `json:"shared"`
+	Vacancy Vacancy `json:"vacancy"`
+}
+
+// UpdateVacancyTemplate changes the fields that are set. A new Vacancy is
+// saved as a new version.
+type UpdateVacancyTemplate struct {
+	Name    *string  `json:"name"`
+	Shared  *bool    `json:"shared"`
+	Vacancy *Vacancy `json:"vacancy"`
+}
+
+// SaveVacancyAsTemplate saves an existing vacancy as a new template.
+type SaveVacancyAsTemplate struct {
+	Name   string `json:"name"`
+	Shared bool   `json:"shared"`
+}
+
+// CreateVacancyFromTemplate creates a vacancy from a version of a template,
+// the latest one by default. Overrides is a partial vacancy merged into the
+// template as a JSON merge patch: its fields replace the template's and
+// null removes them.
+type CreateVacancyFromTemplate struct {
+	Version   int             `json:"version"`
+	Overrides json.RawMessage `json:"overrides"`
+}
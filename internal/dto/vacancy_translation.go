@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// VacancyTranslation is the title and description of a vacancy in a
+// locale. Machine translations are drafts that job seekers only see once
+// the employer has saved them.
+type VacancyTranslation struct {
+	Locale      string     `json:"locale"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Primary     bool       `json:"primary,omitempty"`
+	Machine     bool       `json:"machine,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+type UpsertVacancyTranslation struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// VacancyVersion is a stored version of a vacancy with the fields that
+// changed since the previous one. The first stored version has no changes
+// and no author: it is the vacancy as it was before its first update.
+type VacancyVersion struct {
+	Version   int                  `json:"version"`
+	AuthorID  *int64               `json:"author_id,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+	Changes   []VacancyFieldChange `json:"changes"`
+}
+
+// VacancyFieldChange is a changed field of a vacancy. Details are named
+// details.<key> after their attribute key, or details.<group>.<name>
+// without one. From is null for added fields and To for removed ones.
+type VacancyFieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// VacancyVersionResponse is a version of a vacancy with its full content.
+type VacancyVersionResponse struct {
+	VacancyVersion
+	Vacancy json.RawMessage `json:"vacancy"`
+}
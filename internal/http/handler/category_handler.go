@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/aidosgal/alem.core-service/internal/dto"
 	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
 	"github.com/aidosgal/alem.core-service/internal/service"
 	"github.com/go-chi/chi/v5"
 	"log/slog"
@@ -35,7 +37,7 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 	category, err := h.service.CreateCategory(r.Context(), req)
 	if err != nil {
 		h.log.Error("Failed to create category", slog.Any("error", err))
-		lib.WriteError(w, http.StatusInternalServerError, err)
+		lib.WriteError(w, categoryErrorStatus(err), err)
 		return
 	}
 
@@ -64,19 +66,28 @@ func (h *CategoryHandler) GetCategoryByID(w http.ResponseWriter, r *http.Request
 	lib.WriteJSON(w, http.StatusOK, category)
 }
 
-// UpdateCategory handles updating a category
+// UpdateCategory handles renaming a category or changing its parent
 func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid category ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
 	var req dto.UpdateCategory
 	if err := lib.ParseJSON(r, &req); err != nil {
 		h.log.Warn("Failed to parse request body", slog.Any("error", err))
 		lib.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
+	req.ID = id
 
-	err := h.service.UpdateCategory(r.Context(), req)
+	err = h.service.UpdateCategory(r.Context(), req)
 	if err != nil {
 		h.log.Warn("Failed to update category", slog.Any("error", err))
-		lib.WriteError(w, http.StatusInternalServerError, err)
+		lib.WriteError(w, categoryErrorStatus(err), err)
 		return
 	}
 
@@ -84,7 +95,8 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 	lib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Category updated successfully"})
 }
 
-// DeleteCategory handles deleting a category
+// DeleteCategory handles deleting a category. With ?cascade=true the whole
+// subtree is removed, otherwise the children move up to the deleted parent.
 func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
@@ -94,10 +106,12 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.service.DeleteCategory(r.Context(), id)
+	cascade, _ := strconv.ParseBool(r.URL.Query().Get("cascade"))
+
+	err = h.service.DeleteCategory(r.Context(), id, cascade)
 	if err != nil {
-		h.log.Warn("Failed to delete category", slog.Int("id", id))
-		lib.WriteError(w, http.StatusInternalServerError, err)
+		h.log.Warn("Failed to delete category", slog.Int("id", id), slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
 		return
 	}
 
@@ -105,6 +119,54 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 	lib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Category deleted successfully"})
 }
 
+// MoveCategory handles moving a category subtree under a new parent
+func (h *CategoryHandler) MoveCategory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid category ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req dto.MoveCategory
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.MoveCategory(r.Context(), id, req)
+	if err != nil {
+		h.log.Warn("Failed to move category", slog.Int("id", id), slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Category moved successfully", slog.Int("id", id))
+	lib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Category moved successfully"})
+}
+
+// ReorderCategories handles changing the order of sibling categories
+func (h *CategoryHandler) ReorderCategories(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReorderCategories
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err := h.service.ReorderCategories(r.Context(), req)
+	if err != nil {
+		h.log.Warn("Failed to reorder categories", slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Categories reordered successfully", slog.Int("count", len(req.IDs)))
+	lib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Categories reordered successfully"})
+}
+
 // GetCategoryTree handles retrieving the category tree
 func (h *CategoryHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
 	categories, err := h.service.GetCategoryTree(r.Context())
@@ -117,3 +179,249 @@ func (h *CategoryHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request
 	h.log.Info("Category tree retrieved successfully", slog.Int("count", len(categories)))
 	lib.WriteJSON(w, http.StatusOK, categories)
 }
+
+// GetCategoryNestedTree handles retrieving categories as a nested tree,
+// optionally with vacancy and resume counts (?counts=true)
+func (h *CategoryHandler) GetCategoryNestedTree(w http.ResponseWriter, r *http.Request) {
+	withCounts, _ := strconv.ParseBool(r.URL.Query().Get("counts"))
+
+	tree, err := h.service.GetCategoryNestedTree(r.Context(), withCounts)
+	if err != nil {
+		h.log.Error("Failed to retrieve nested category tree", slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.log.Info("Nested category tree retrieved successfully", slog.Int("roots", len(tree)))
+	lib.WriteJSON(w, http.StatusOK, tree)
+}
+
+// GetCategoryAncestors handles retrieving the breadcrumb path of a category
+func (h *CategoryHandler) GetCategoryAncestors(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid category ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	ancestors, err := h.service.GetCategoryAncestors(r.Context(), id)
+	if err != nil {
+		h.log.Warn("Failed to retrieve category ancestors", slog.Int("id", id), slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Category ancestors retrieved successfully", slog.Int("id", id))
+	lib.WriteJSON(w, http.StatusOK, ancestors)
+}
+
+// ListCategoryTranslations handles retrieving the names of a category in
+// every locale
+func (h *CategoryHandler) ListCategoryTranslations(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid category ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	translations, err := h.service.ListCategoryTranslations(r.Context(), id)
+	if err != nil {
+		h.log.Warn("Failed to retrieve category translations", slog.Int("id", id), slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, translations)
+}
+
+// UpsertCategoryTranslation handles setting the name of a category in a locale
+func (h *CategoryHandler) UpsertCategoryTranslation(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid category ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	locale := chi.URLParam(r, "locale")
+
+	var req dto.UpsertCategoryTranslation
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.UpsertCategoryTranslation(r.Context(), id, locale, req)
+	if err != nil {
+		h.log.Warn("Failed to save category translation", slog.Int("id", id), slog.String("locale", locale), slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Category translation saved successfully", slog.Int("id", id), slog.String("locale", locale))
+	lib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Category translation saved successfully"})
+}
+
+// DeleteCategoryTranslation handles removing the name of a category in a locale
+func (h *CategoryHandler) DeleteCategoryTranslation(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid category ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	locale := chi.URLParam(r, "locale")
+
+	err = h.service.DeleteCategoryTranslation(r.Context(), id, locale)
+	if err != nil {
+		h.log.Warn("Failed to delete category translation", slog.Int("id", id), slog.String("locale", locale), slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Category translation deleted successfully", slog.Int("id", id), slog.String("locale", locale))
+	lib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Category translation deleted successfully"})
+}
+
+// ExportCategories handles exporting the whole tree with translations as
+// JSON or, with ?format=csv, as CSV
+func (h *CategoryHandler) ExportCategories(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="categories.csv"`)
+		if err := h.service.ExportCategoriesCSV(r.Context(), w); err != nil {
+			h.log.Error("Failed to export categories", slog.Any("error", err))
+		}
+		return
+	}
+
+	tree, err := h.service.ExportCategories(r.Context())
+	if err != nil {
+		h.log.Error("Failed to export categories", slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, tree)
+}
+
+// ImportCategories handles importing a tree in the export format. The body is
+// JSON by default or CSV with ?format=csv. A failed import changes nothing.
+func (h *CategoryHandler) ImportCategories(w http.ResponseWriter, r *http.Request) {
+	var (
+		result *dto.CategoryImportResult
+		err    error
+	)
+
+	if r.URL.Query().Get("format") == "csv" {
+		result, err = h.service.ImportCategoriesCSV(r.Context(), r.Body)
+	} else {
+		var nodes []dto.CategoryExportNode
+		if err := lib.ParseJSON(r, &nodes); err != nil {
+			h.log.Warn("Failed to parse request body", slog.Any("error", err))
+			lib.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		result, err = h.service.ImportCategories(r.Context(), nodes)
+	}
+	if err != nil {
+		h.log.Warn("Failed to import categories", slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Categories imported successfully", slog.Int("created", result.Created), slog.Int("updated", result.Updated))
+	lib.WriteJSON(w, http.StatusOK, result)
+}
+
+// ListCategoryAttributes handles retrieving the attribute schema of a
+// category, including the attributes inherited from its ancestors
+func (h *CategoryHandler) ListCategoryAttributes(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid category ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	attributes, err := h.service.ListCategoryAttributes(r.Context(), id)
+	if err != nil {
+		h.log.Warn("Failed to retrieve category attributes", slog.Int("id", id), slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, attributes)
+}
+
+// UpsertCategoryAttribute handles defining an attribute of a category
+func (h *CategoryHandler) UpsertCategoryAttribute(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid category ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	key := chi.URLParam(r, "key")
+
+	var req dto.UpsertCategoryAttribute
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	attribute, err := h.service.UpsertCategoryAttribute(r.Context(), id, key, req)
+	if err != nil {
+		h.log.Warn("Failed to save category attribute", slog.Int("id", id), slog.String("key", key), slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Category attribute saved successfully", slog.Int("id", id), slog.String("key", key))
+	lib.WriteJSON(w, http.StatusOK, attribute)
+}
+
+// DeleteCategoryAttribute handles removing an attribute from a category
+func (h *CategoryHandler) DeleteCategoryAttribute(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid category ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	key := chi.URLParam(r, "key")
+
+	err = h.service.DeleteCategoryAttribute(r.Context(), id, key)
+	if err != nil {
+		h.log.Warn("Failed to delete category attribute", slog.Int("id", id), slog.String("key", key), slog.Any("error", err))
+		lib.WriteError(w, categoryErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Category attribute deleted successfully", slog.Int("id", id), slog.String("key", key))
+	lib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Category attribute deleted successfully"})
+}
+
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrCategoryNotFound), errors.Is(err, model.ErrCategoryAttributeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrCategoryCycle), errors.Is(err, model.ErrCategoryNotSibling),
+		errors.Is(err, model.ErrUnsupportedLocale), errors.Is(err, model.ErrInvalidCategoryAttribute):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrCategoryInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type CurrencyHandler struct {
+	log     *slog.Logger
+	service *service.CurrencyService
+}
+
+func NewCurrencyHandler(log *slog.Logger, service *service.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{
+		log:     log,
+		service: service,
+	}
+}
+
+func (h *CurrencyHandler) ListCurrencies(w http.ResponseWriter, r *http.Request) {
+	currencies, err := h.service.ListCurrencies(r.Context())
+	if err != nil {
+		h.log.Error("Failed to list currencies", slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, currencies)
+}
+
+// UpsertCurrency sets the exchange rate of a currency. Admin only.
+func (h *CurrencyHandler) UpsertCurrency(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpsertCurrency
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	code := chi.URLParam(r, "code")
+	currency, err := h.service.UpsertCurrency(r.Context(), code, req)
+	if err != nil {
+		h.log.Warn("Failed to update currency", slog.String("code", code), slog.Any("error", err))
+		lib.WriteError(w, currencyErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Currency updated successfully", slog.String("code", currency.Code))
+	lib.WriteJSON(w, http.StatusOK, currency)
+}
+
+func currencyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrUnsupportedCurrency), errors.Is(err, model.ErrInvalidCurrencyRate),
+		errors.Is(err, model.ErrInvalidSalaryType):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
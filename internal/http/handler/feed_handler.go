@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type FeedHandler struct {
+	log      *slog.Logger
+	service  *service.FeedService
+	cacheTTL time.Duration
+}
+
+func NewFeedHandler(log *slog.Logger, service *service.FeedService, cacheTTL time.Duration) *FeedHandler {
+	return &FeedHandler{
+		log:      log,
+		service:  service,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// JobFeed serves the public XML feed of published vacancies. The optional
+// since parameter (RFC 3339 or YYYY-MM-DD) limits it to vacancies changed
+// after that time. A truncated feed is continued by passing its nextsince
+// and nextafter as since and after.
+func (h *FeedHandler) JobFeed(w http.ResponseWriter, r *http.Request) {
+	var since *time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := parseSince(value)
+		if err != nil {
+			h.log.Warn("Invalid since parameter", slog.String("since", value))
+			lib.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		since = &parsed
+	}
+	var after *int64
+	if value := r.URL.Query().Get("after"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || since == nil {
+			h.log.Warn("Invalid after parameter", slog.String("after", value))
+			lib.WriteError(w, http.StatusBadRequest, errors.New("after must be a vacancy ID given with since"))
+			return
+		}
+		after = &parsed
+	}
+
+	body, builtAt, err := h.service.JobFeed(r.Context(), since, after)
+	if err != nil {
+		h.log.Error("Failed to build job feed", slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", h.cacheControl())
+	http.ServeContent(w, r, "jobs.xml", builtAt, bytes.NewReader(body))
+}
+
+// JobPosting serves the schema.org JobPosting of a published vacancy as
+// JSON-LD.
+func (h *FeedHandler) JobPosting(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	posting, err := h.service.JobPosting(r.Context(), id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, model.ErrVacancyNotFound) {
+			status = http.StatusNotFound
+		}
+		h.log.Warn("Failed to get job posting", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, status, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/ld+json")
+	w.Header().Set("Cache-Control", h.cacheControl())
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(posting)
+}
+
+func (h *FeedHandler) cacheControl() string {
+	return fmt.Sprintf("public, max-age=%d", int(h.cacheTTL.Seconds()))
+}
+
+// parseSince accepts RFC 3339 timestamps and plain dates.
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type LocationHandler struct {
+	log     *slog.Logger
+	service *service.LocationService
+}
+
+func NewLocationHandler(log *slog.Logger, service *service.LocationService) *LocationHandler {
+	return &LocationHandler{
+		log:     log,
+		service: service,
+	}
+}
+
+func (h *LocationHandler) ListCountries(w http.ResponseWriter, r *http.Request) {
+	countries, err := h.service.ListCountries(r.Context())
+	if err != nil {
+		h.log.Error("Failed to list countries", slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, countries)
+}
+
+// ListOpenCountries lists the countries that currently have published
+// vacancies, with counts, for the country picker.
+func (h *LocationHandler) ListOpenCountries(w http.ResponseWriter, r *http.Request) {
+	countries, err := h.service.ListOpenCountries(r.Context())
+	if err != nil {
+		h.log.Error("Failed to list countries with vacancies", slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, countries)
+}
+
+func (h *LocationHandler) ListRegions(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+	regions, err := h.service.ListRegions(r.Context(), code)
+	if err != nil {
+		h.log.Warn("Failed to list regions", slog.String("country", code), slog.Any("error", err))
+		lib.WriteError(w, locationErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, regions)
+}
+
+func (h *LocationHandler) ListCities(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid region ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cities, err := h.service.ListCities(r.Context(), id)
+	if err != nil {
+		h.log.Warn("Failed to list cities", slog.Int("region_id", id), slog.Any("error", err))
+		lib.WriteError(w, locationErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, cities)
+}
+
+func locationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrCountryNotFound), errors.Is(err, model.ErrRegionNotFound),
+		errors.Is(err, model.ErrCityNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrLocationMismatch):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/http/middleware"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type ModerationHandler struct {
+	log     *slog.Logger
+	service *service.VacancyService
+}
+
+func NewModerationHandler(log *slog.Logger, service *service.VacancyService) *ModerationHandler {
+	return &ModerationHandler{
+		log:     log,
+		service: service,
+	}
+}
+
+// ListQueue lists vacancies waiting for a moderator, riskiest first.
+func (h *ModerationHandler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	queue, err := h.service.ListModerationQueue(r.Context(), limit, offset)
+	if err != nil {
+		h.log.Error("Failed to list moderation queue", slog.Any("error", err))
+		lib.WriteError(w, moderationErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, queue)
+}
+
+func (h *ModerationHandler) ApproveVacancy(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, model.ModerationActionApproved)
+}
+
+func (h *ModerationHandler) RejectVacancy(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, model.ModerationActionRejected)
+}
+
+func (h *ModerationHandler) RequestVacancyChanges(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, model.ModerationActionChangesRequested)
+}
+
+// moderate applies the moderator's decision. The body with the reason is
+// optional when approving.
+func (h *ModerationHandler) moderate(w http.ResponseWriter, r *http.Request, action string) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req dto.ModerationDecision
+	if err := lib.ParseJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	moderatorID, _ := middleware.GetUserID(r)
+	vacancy, err := h.service.ModerateVacancy(r.Context(), moderatorID, id, action, req)
+	if err != nil {
+		h.log.Warn("Failed to moderate vacancy", slog.Int64("id", id), slog.String("action", action), slog.Any("error", err))
+		lib.WriteError(w, moderationErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy moderated successfully", slog.Int64("id", id), slog.String("action", action))
+	lib.WriteJSON(w, http.StatusOK, vacancy)
+}
+
+// GetModerationLog returns the full moderation history of a vacancy,
+// including the risk rules that flagged it.
+func (h *ModerationHandler) GetModerationLog(w http.ResponseWriter, r *http.Request) {
+	h.moderationLog(w, r, true)
+}
+
+// GetVacancyModerationLog returns the moderation history of the
+// organization's own vacancy with the moderators' reasons.
+func (h *ModerationHandler) GetVacancyModerationLog(w http.ResponseWriter, r *http.Request) {
+	h.moderationLog(w, r, false)
+}
+
+func (h *ModerationHandler) moderationLog(w http.ResponseWriter, r *http.Request, moderator bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	entries, err := h.service.GetModerationLog(r.Context(), id, organizationID, moderator)
+	if err != nil {
+		h.log.Warn("Failed to get moderation log", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, moderationErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, entries)
+}
+
+func (h *ModerationHandler) ListBlacklist(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.service.ListBlacklist(r.Context())
+	if err != nil {
+		h.log.Error("Failed to list blacklist", slog.Any("error", err))
+		lib.WriteError(w, moderationErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, entries)
+}
+
+func (h *ModerationHandler) AddBlacklistEntry(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateBlacklistEntry
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	entry, err := h.service.AddBlacklistEntry(r.Context(), req)
+	if err != nil {
+		h.log.Warn("Failed to add blacklist entry", slog.Any("error", err))
+		lib.WriteError(w, moderationErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Blacklist entry added successfully", slog.Int64("id", entry.ID))
+	lib.WriteJSON(w, http.StatusCreated, entry)
+}
+
+func (h *ModerationHandler) DeleteBlacklistEntry(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid blacklist entry ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteBlacklistEntry(r.Context(), id); err != nil {
+		h.log.Warn("Failed to delete blacklist entry", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, moderationErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Blacklist entry deleted successfully", slog.Int64("id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func moderationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrBlacklistEntryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrInvalidModerationAction), errors.Is(err, model.ErrModerationReasonMissing),
+		errors.Is(err, model.ErrInvalidBlacklistEntry):
+		return http.StatusBadRequest
+	default:
+		return vacancyErrorStatus(err)
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aidosgal/alem.core-service/internal/http/middleware"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type NotificationHandler struct {
+	log     *slog.Logger
+	service *service.NotificationService
+}
+
+func NewNotificationHandler(log *slog.Logger, service *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		log:     log,
+		service: service,
+	}
+}
+
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r)
+	if !ok {
+		lib.WriteError(w, http.StatusUnauthorized, fmt.Errorf("Unauthorized"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	unreadOnly, _ := strconv.ParseBool(r.URL.Query().Get("unread"))
+
+	notifications, err := h.service.ListNotifications(r.Context(), userID, unreadOnly, limit, offset)
+	if err != nil {
+		h.log.Error("Failed to list notifications", slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, notifications)
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r)
+	if !ok {
+		lib.WriteError(w, http.StatusUnauthorized, fmt.Errorf("Unauthorized"))
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid notification ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	found, err := h.service.MarkRead(r.Context(), id, userID)
+	if err != nil {
+		h.log.Error("Failed to mark notification as read", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		lib.WriteError(w, http.StatusNotFound, fmt.Errorf("notification not found"))
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Notification marked as read"})
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/http/middleware"
 	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
 	"github.com/aidosgal/alem.core-service/internal/service"
 	"github.com/go-chi/chi/v5"
 	"log/slog"
@@ -79,3 +82,90 @@ func (h *OrganizationHandler) GetAllOrganizations(w http.ResponseWriter, r *http
 	lib.WriteJSON(w, http.StatusOK, orgs)
 }
 
+// HideOrganization hides every vacancy of the organization from the current
+// user.
+func (h *OrganizationHandler) HideOrganization(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid organization ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	if err := h.service.HideOrganization(r.Context(), userID, id); err != nil {
+		h.log.Warn("Failed to hide organization", slog.Int("id", id), slog.Any("error", err))
+		status := http.StatusInternalServerError
+		if errors.Is(err, model.ErrOrganizationNotFound) {
+			status = http.StatusNotFound
+		}
+		lib.WriteError(w, status, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) UnhideOrganization(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid organization ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	if err := h.service.UnhideOrganization(r.Context(), userID, id); err != nil {
+		h.log.Error("Failed to unhide organization", slog.Int("id", id), slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *OrganizationHandler) ListHiddenOrganizations(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.GetUserID(r)
+	orgs, err := h.service.ListHiddenOrganizations(r.Context(), userID)
+	if err != nil {
+		h.log.Error("Failed to list hidden organizations", slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, orgs)
+}
+
+// VerifyOrganization handles an administrator marking an organization as
+// verified or withdrawing the mark
+func (h *OrganizationHandler) VerifyOrganization(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.log.Warn("Invalid organization ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req dto.VerifyOrganization
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.VerifyOrganization(r.Context(), id, req); err != nil {
+		h.log.Warn("Failed to verify organization", slog.Int("id", id), slog.Any("error", err))
+		status := http.StatusInternalServerError
+		if errors.Is(err, model.ErrOrganizationNotFound) {
+			status = http.StatusNotFound
+		}
+		lib.WriteError(w, status, err)
+		return
+	}
+
+	h.log.Info("Organization verification updated successfully", slog.Int("id", id), slog.Bool("verified", req.Verified))
+	w.WriteHeader(http.StatusNoContent)
+}
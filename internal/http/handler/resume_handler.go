@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -59,9 +60,7 @@ func (h *ResumeHandler) ListResume(w http.ResponseWriter, r *http.Request) {
 	user_id := 0
 	user_id, _ = strconv.Atoi(user_id_str)
 
-	category_id_str := query.Get("category_id")
-	category_id := 0
-	category_id, _ = strconv.Atoi(category_id_str)
+	category_ids := lib.ParseIntList(query["category_id"])
 	
 	limit := 10
 	offset := 0
@@ -74,7 +73,7 @@ func (h *ResumeHandler) ListResume(w http.ResponseWriter, r *http.Request) {
 	
 	filters := map[string]interface{}{
 		"user_id": user_id,
-		"category_id": category_id,
+		"category_ids": category_ids,
 	}
 
 	resumes, err := h.service.ListResumes(r.Context(), filters, limit, offset)
@@ -97,10 +96,79 @@ func (h *ResumeHandler) GetResume(w http.ResponseWriter, r *http.Request) {
 
 	resume, err := h.service.GetResume(r.Context(), resume_id)
 	if err != nil {
-		lib.WriteError(w, http.StatusInternalServerError, err)
+		lib.WriteError(w, resumeErrorStatus(err), err)
 		return
 	}
 
 	lib.WriteJSON(w, http.StatusOK, map[string]interface{}{"resume": resume})
 	return
 }
+
+// UpdateResume replaces one of the user's resumes, including its skills and
+// experiences.
+func (h *ResumeHandler) UpdateResume(w http.ResponseWriter, r *http.Request) {
+	resume_id_str := chi.URLParam(r, "resume_id")
+	resume_id, err := strconv.Atoi(resume_id_str)
+	if err != nil {
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resume := &model.Resume{}
+	if err := lib.ParseJSON(r, &resume); err != nil {
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user_id, ok := middleware.GetUserID(r)
+	if !ok {
+		lib.WriteError(w, http.StatusUnauthorized, fmt.Errorf("Unauthorized"))
+		return
+	}
+
+	resume.Id = resume_id
+	response, err := h.service.UpdateResume(r.Context(), int(user_id), resume)
+	if err != nil {
+		h.log.Warn("Failed to update resume", slog.Int("id", resume_id), slog.Any("error", err))
+		lib.WriteError(w, resumeErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, map[string]interface{}{"resume": response})
+}
+
+func (h *ResumeHandler) DeleteResume(w http.ResponseWriter, r *http.Request) {
+	resume_id_str := chi.URLParam(r, "resume_id")
+	resume_id, err := strconv.Atoi(resume_id_str)
+	if err != nil {
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user_id, ok := middleware.GetUserID(r)
+	if !ok {
+		lib.WriteError(w, http.StatusUnauthorized, fmt.Errorf("Unauthorized"))
+		return
+	}
+
+	if err := h.service.DeleteResume(r.Context(), resume_id, int(user_id)); err != nil {
+		h.log.Warn("Failed to delete resume", slog.Int("id", resume_id), slog.Any("error", err))
+		lib.WriteError(w, resumeErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func resumeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrResumeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrResumeForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, model.ErrResumeSkillNotFound), errors.Is(err, model.ErrResumeExperienceNotFound):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/http/middleware"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type SavedSearchHandler struct {
+	log     *slog.Logger
+	service *service.SavedSearchService
+}
+
+func NewSavedSearchHandler(log *slog.Logger, service *service.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		log:     log,
+		service: service,
+	}
+}
+
+func (h *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r)
+	if !ok {
+		lib.WriteError(w, http.StatusUnauthorized, errors.New("Unauthorized"))
+		return
+	}
+
+	var req dto.CreateSavedSearch
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	search, err := h.service.CreateSavedSearch(r.Context(), userID, req)
+	if err != nil {
+		h.log.Warn("Failed to create saved search", slog.Any("error", err))
+		lib.WriteError(w, savedSearchErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Saved search created successfully", slog.Int64("id", search.ID))
+	lib.WriteJSON(w, http.StatusCreated, search)
+}
+
+func (h *SavedSearchHandler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r)
+	if !ok {
+		lib.WriteError(w, http.StatusUnauthorized, errors.New("Unauthorized"))
+		return
+	}
+
+	searches, err := h.service.ListSavedSearches(r.Context(), userID)
+	if err != nil {
+		h.log.Error("Failed to list saved searches", slog.Any("error", err))
+		lib.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, searches)
+}
+
+func (h *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r)
+	if !ok {
+		lib.WriteError(w, http.StatusUnauthorized, errors.New("Unauthorized"))
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid saved search ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteSavedSearch(r.Context(), id, userID); err != nil {
+		h.log.Warn("Failed to delete saved search", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, savedSearchErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unsubscribe is the one-tap link sent with saved search alerts. It is not
+// behind authentication; the token identifies the saved search.
+func (h *SavedSearchHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	if err := h.service.Unsubscribe(r.Context(), token); err != nil {
+		h.log.Warn("Failed to unsubscribe from saved search", slog.Any("error", err))
+		lib.WriteError(w, savedSearchErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, map[string]string{"message": "Unsubscribed from saved search alerts"})
+}
+
+func savedSearchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrSavedSearchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrInvalidSavedSearch):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrSavedSearchLimit):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
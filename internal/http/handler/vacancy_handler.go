@@ -1,11 +1,18 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/http/middleware"
 	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
 	"github.com/aidosgal/alem.core-service/internal/service"
 	"github.com/go-chi/chi/v5"
 	"log/slog"
@@ -31,10 +38,17 @@ func (h *VacancyHandler) CreateVacancy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	organizationID, _ := middleware.GetOrganizationID(r)
+	if organizationID == 0 {
+		lib.WriteError(w, http.StatusForbidden, errors.New("only organizations can publish vacancies"))
+		return
+	}
+	req.Vacancy.OrganizationID = organizationID
+
 	vacancy, err := h.service.CreateVacancy(r.Context(), req)
 	if err != nil {
 		h.log.Error("Failed to create vacancy", slog.Any("error", err))
-		lib.WriteError(w, http.StatusInternalServerError, err)
+		writeVacancyError(w, err)
 		return
 	}
 
@@ -51,7 +65,9 @@ func (h *VacancyHandler) GetVacancy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	vacancy, err := h.service.GetVacancyByID(r.Context(), id)
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	vacancy, err := h.service.GetVacancyByID(r.Context(), id, service.VacancyViewer{UserID: userID, OrganizationID: organizationID})
 	if err != nil {
 		h.log.Error("Failed to retrieve vacancy", slog.Int64("id", id), slog.Any("error", err))
 		lib.WriteError(w, http.StatusInternalServerError, err)
@@ -59,7 +75,7 @@ func (h *VacancyHandler) GetVacancy(w http.ResponseWriter, r *http.Request) {
 	}
 	if vacancy == nil {
 		h.log.Warn("Vacancy not found", slog.Int64("id", id))
-		lib.WriteError(w, http.StatusNotFound, nil)
+		lib.WriteError(w, http.StatusNotFound, model.ErrVacancyNotFound)
 		return
 	}
 
@@ -70,28 +86,92 @@ func (h *VacancyHandler) GetVacancy(w http.ResponseWriter, r *http.Request) {
 func (h *VacancyHandler) ListVacancies(w http.ResponseWriter, r *http.Request) {
 	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	categoryID, _ := strconv.Atoi(r.URL.Query().Get("category_id"))
+	cursor := r.URL.Query().Get("cursor")
+	total := r.URL.Query().Get("total")
+	categoryIDs := lib.ParseIntList(r.URL.Query()["category_id"])
 	search := r.URL.Query().Get("search")
 	salaryFrom, _ := strconv.Atoi(r.URL.Query().Get("salary_from"))
 	salaryTo, _ := strconv.Atoi(r.URL.Query().Get("salary_to"))
+	currency := r.URL.Query().Get("currency")
+	sort := r.URL.Query().Get("sort")
+	countryCodes := lib.ParseStringList(r.URL.Query()["country"])
+	for i, code := range countryCodes {
+		countryCodes[i] = strings.ToUpper(code)
+	}
+	regionIDs := lib.ParseIntList(r.URL.Query()["region_id"])
+	cityIDs := lib.ParseIntList(r.URL.Query()["city_id"])
+	radiusKm, _ := strconv.ParseFloat(r.URL.Query().Get("radius_km"), 64)
+
+	var near *dto.GeoPoint
+	if value := r.URL.Query().Get("near"); value != "" {
+		point, err := parseGeoPoint(value)
+		if err != nil {
+			h.log.Warn("Invalid near parameter", slog.String("near", value))
+			lib.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		near = point
+	}
+	mine, _ := strconv.ParseBool(r.URL.Query().Get("mine"))
+	collapse := true
+	if value := r.URL.Query().Get("collapse_duplicates"); value != "" {
+		collapse, _ = strconv.ParseBool(value)
+	}
+	statuses := lib.ParseStringList(r.URL.Query()["status"])
+	var verified *bool
+	if value := r.URL.Query().Get("verified"); value != "" {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			h.log.Warn("Invalid verified parameter", slog.String("verified", value))
+			lib.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		verified = &v
+	}
+	facets, _ := strconv.ParseBool(r.URL.Query().Get("facets"))
+	attributes := parseAttributeFilters(r.URL.Query())
+	organizationID, _ := middleware.GetOrganizationID(r)
+	userID, _ := middleware.GetUserID(r)
+
+	if mine && organizationID == 0 {
+		lib.WriteError(w, http.StatusForbidden, errors.New("only organizations have their own vacancies"))
+		return
+	}
 
 	req := dto.ListVacancyRequest{
-		Offset:     offset,
-		Limit:      limit,
-		CategoryID: int(categoryID),
-		Search:     search,
-		SalaryFrom: float64(salaryFrom),
-		SalaryTo:   float64(salaryTo),
+		Offset:               offset,
+		Limit:                limit,
+		CategoryIDs:          categoryIDs,
+		Search:               search,
+		SalaryFrom:           float64(salaryFrom),
+		SalaryTo:             float64(salaryTo),
+		Currency:             currency,
+		Sort:                 sort,
+		CountryCodes:         countryCodes,
+		RegionIDs:            regionIDs,
+		CityIDs:              cityIDs,
+		Near:                 near,
+		RadiusKm:             radiusKm,
+		Mine:                 mine,
+		Statuses:             statuses,
+		ViewerOrganizationID: organizationID,
+		ViewerUserID:         userID,
+		CollapseDuplicates:   collapse,
+		Attributes:           attributes,
+		Cursor:               cursor,
+		Total:                total,
+		Verified:             verified,
+		Facets:               facets,
 	}
 
 	vacancies, err := h.service.ListVacancies(r.Context(), req)
 	if err != nil {
 		h.log.Error("Failed to list vacancies", slog.Any("error", err))
-		lib.WriteError(w, http.StatusInternalServerError, err)
+		lib.WriteError(w, vacancyErrorStatus(err), err)
 		return
 	}
 
-	h.log.Info("Vacancies retrieved successfully", slog.Int("total", vacancies.Total))
+	h.log.Info("Vacancies retrieved successfully", slog.Int("count", len(vacancies.Vacancie)))
 	lib.WriteJSON(w, http.StatusOK, vacancies)
 }
 
@@ -103,10 +183,21 @@ func (h *VacancyHandler) UpdateVacancy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	req.Vacancy.ID = id
+	req.Vacancy.OrganizationID, _ = middleware.GetOrganizationID(r)
+	req.AuthorID, _ = middleware.GetUserID(r)
+
 	vacancy, err := h.service.UpdateVacancy(r.Context(), req)
 	if err != nil {
 		h.log.Error("Failed to update vacancy", slog.Any("error", err))
-		lib.WriteError(w, http.StatusInternalServerError, err)
+		writeVacancyError(w, err)
 		return
 	}
 
@@ -114,6 +205,157 @@ func (h *VacancyHandler) UpdateVacancy(w http.ResponseWriter, r *http.Request) {
 	lib.WriteJSON(w, http.StatusOK, vacancy)
 }
 
+// ListVacancyVersions returns the change history of the organization's
+// vacancy.
+func (h *VacancyHandler) ListVacancyVersions(w http.ResponseWriter, r *http.Request) {
+	h.listVacancyVersions(w, r, false)
+}
+
+// ListModeratedVacancyVersions returns the change history of any vacancy.
+func (h *VacancyHandler) ListModeratedVacancyVersions(w http.ResponseWriter, r *http.Request) {
+	h.listVacancyVersions(w, r, true)
+}
+
+func (h *VacancyHandler) listVacancyVersions(w http.ResponseWriter, r *http.Request, moderator bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	versions, err := h.service.ListVacancyVersions(r.Context(), id, organizationID, moderator)
+	if err != nil {
+		h.log.Warn("Failed to list vacancy versions", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, versions)
+}
+
+// GetVacancyVersion returns a version of the organization's vacancy.
+func (h *VacancyHandler) GetVacancyVersion(w http.ResponseWriter, r *http.Request) {
+	h.getVacancyVersion(w, r, false)
+}
+
+// GetModeratedVacancyVersion returns a version of any vacancy.
+func (h *VacancyHandler) GetModeratedVacancyVersion(w http.ResponseWriter, r *http.Request) {
+	h.getVacancyVersion(w, r, true)
+}
+
+func (h *VacancyHandler) getVacancyVersion(w http.ResponseWriter, r *http.Request, moderator bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	versionStr := chi.URLParam(r, "version")
+	version, err := strconv.Atoi(versionStr)
+	if err != nil {
+		h.log.Warn("Invalid vacancy version", slog.String("version", versionStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	response, err := h.service.GetVacancyVersion(r.Context(), id, organizationID, version, moderator)
+	if err != nil {
+		h.log.Warn("Failed to get vacancy version", slog.Int64("id", id), slog.Int("version", version), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, response)
+}
+
+// ChangeVacancyStatus moves one of the organization's vacancies to another
+// status.
+func (h *VacancyHandler) ChangeVacancyStatus(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req dto.ChangeVacancyStatusRequest
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	vacancy, err := h.service.ChangeStatus(r.Context(), id, organizationID, req)
+	if err != nil {
+		h.log.Warn("Failed to change vacancy status", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy status changed successfully", slog.Int64("id", id), slog.String("status", vacancy.Status))
+	lib.WriteJSON(w, http.StatusOK, vacancy)
+}
+
+func (h *VacancyHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	if err := h.service.AddFavorite(r.Context(), userID, id); err != nil {
+		h.log.Warn("Failed to add favorite", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *VacancyHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	if err := h.service.RemoveFavorite(r.Context(), userID, id); err != nil {
+		h.log.Error("Failed to remove favorite", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *VacancyHandler) ListFavorites(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	userID, _ := middleware.GetUserID(r)
+
+	vacancies, err := h.service.ListFavorites(r.Context(), userID, limit, offset, r.URL.Query().Get("cursor"))
+	if err != nil {
+		h.log.Error("Failed to list favorite vacancies", slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, vacancies)
+}
+
 func (h *VacancyHandler) DeleteVacancy(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -123,13 +365,171 @@ func (h *VacancyHandler) DeleteVacancy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.DeleteVacancy(r.Context(), id)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	err = h.service.DeleteVacancy(r.Context(), id, organizationID)
 	if err != nil {
 		h.log.Error("Failed to delete vacancy", slog.Int64("id", id), slog.Any("error", err))
-		lib.WriteError(w, http.StatusInternalServerError, err)
+		lib.WriteError(w, vacancyErrorStatus(err), err)
 		return
 	}
 
 	h.log.Info("Vacancy deleted successfully", slog.Int64("id", id))
-	lib.WriteJSON(w, http.StatusNoContent, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreVacancy undoes a recent soft delete of one of the organization's
+// vacancies.
+func (h *VacancyHandler) RestoreVacancy(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	vacancy, err := h.service.RestoreVacancy(r.Context(), id, organizationID)
+	if err != nil {
+		h.log.Warn("Failed to restore vacancy", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy restored successfully", slog.Int64("id", id))
+	lib.WriteJSON(w, http.StatusOK, vacancy)
+}
+
+// ApplyToVacancy records the user's application, optionally with one of
+// their resumes. The request body may be omitted.
+func (h *VacancyHandler) ApplyToVacancy(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req dto.ApplyVacancyRequest
+	if err := lib.ParseJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	application, err := h.service.Apply(r.Context(), userID, id, req)
+	if err != nil {
+		h.log.Warn("Failed to apply to vacancy", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Applied to vacancy successfully", slog.Int64("id", id), slog.Int64("application_id", application.ID))
+	lib.WriteJSON(w, http.StatusCreated, application)
+}
+
+// GetVacancyStats returns view and application counts of one of the
+// organization's vacancies for the last `days` days (30 by default).
+func (h *VacancyHandler) GetVacancyStats(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	stats, err := h.service.GetVacancyStats(r.Context(), id, organizationID, days)
+	if err != nil {
+		h.log.Warn("Failed to get vacancy stats", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, stats)
+}
+
+// parseGeoPoint parses "lat,lng".
+func parseGeoPoint(value string) (*dto.GeoPoint, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%w: expected lat,lng", model.ErrInvalidCoordinates)
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidCoordinates, err)
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidCoordinates, err)
+	}
+	return &dto.GeoPoint{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// parseAttributeFilters collects attr[key]=value query parameters by key.
+func parseAttributeFilters(query url.Values) map[string][]string {
+	var attributes map[string][]string
+	for name, values := range query {
+		key, ok := strings.CutPrefix(name, "attr[")
+		if !ok || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		if attributes == nil {
+			attributes = make(map[string][]string)
+		}
+		key = strings.TrimSuffix(key, "]")
+		attributes[key] = append(attributes[key], values...)
+	}
+	return attributes
+}
+
+// writeVacancyError writes the error of saving a vacancy. A duplicate
+// points to the organization's existing vacancy.
+func writeVacancyError(w http.ResponseWriter, err error) {
+	var duplicate *model.DuplicateVacancyError
+	if errors.As(err, &duplicate) {
+		lib.WriteJSON(w, http.StatusConflict, map[string]any{"error": err.Error(), "duplicate_of": duplicate.VacancyID})
+		return
+	}
+	lib.WriteError(w, vacancyErrorStatus(err), err)
+}
+
+func vacancyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrVacancyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrVacancyDetailNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrVacancyForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, model.ErrInvalidVacancyStatus), errors.Is(err, model.ErrUnsupportedCurrency),
+		errors.Is(err, model.ErrInvalidSalaryType), errors.Is(err, model.ErrCountryNotFound),
+		errors.Is(err, model.ErrRegionNotFound), errors.Is(err, model.ErrCityNotFound),
+		errors.Is(err, model.ErrLocationMismatch), errors.Is(err, model.ErrInvalidCoordinates):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrInvalidVacancyTransition):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrApplicationResume):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrAlreadyApplied), errors.Is(err, model.ErrVacancyVersionChanged):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrVacancyRestoreExpired):
+		return http.StatusGone
+	case errors.Is(err, model.ErrDuplicateVacancy), errors.Is(err, model.ErrVacancyTranslationEdited):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrVacancyTranslationNotFound), errors.Is(err, model.ErrVacancyVersionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrUnsupportedLocale), errors.Is(err, model.ErrPrimaryLocaleTranslation),
+		errors.Is(err, model.ErrInvalidVacancyTranslation):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrInvalidVacancyAttribute), errors.Is(err, model.ErrInvalidAttributeFilter),
+		errors.Is(err, model.ErrInvalidVacancySort), errors.Is(err, model.ErrInvalidVacancyCursor), errors.Is(err, model.ErrInvalidVacancyTotal):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
 }
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aidosgal/alem.core-service/internal/http/middleware"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxVacancyImportSize limits the size of an uploaded vacancy file.
+const maxVacancyImportSize = 10 << 20
+
+type VacancyImportHandler struct {
+	log     *slog.Logger
+	service *service.VacancyImportService
+}
+
+func NewVacancyImportHandler(log *slog.Logger, service *service.VacancyImportService) *VacancyImportHandler {
+	return &VacancyImportHandler{
+		log:     log,
+		service: service,
+	}
+}
+
+// UploadVacancies validates a multipart "file" upload of vacancies as CSV,
+// XLSX or JSON and returns the dry-run report. The format comes from the
+// optional "format" field or the file extension.
+func (h *VacancyImportHandler) UploadVacancies(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxVacancyImportSize)
+	if err := r.ParseMultipartForm(maxVacancyImportSize); err != nil {
+		h.log.Warn("Failed to parse form", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.log.Warn("Missing import file", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	report, err := h.service.UploadVacancies(r.Context(), organizationID, userID, header.Filename, r.FormValue("format"), file)
+	if err != nil {
+		h.log.Warn("Failed to validate vacancy import", slog.Any("error", err))
+		lib.WriteError(w, vacancyImportErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy import validated successfully", slog.Int64("id", report.ID))
+	lib.WriteJSON(w, http.StatusCreated, report)
+}
+
+// GetVacancyImport returns the report and, once committed, the progress of
+// an import.
+func (h *VacancyImportHandler) GetVacancyImport(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy import ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	report, err := h.service.GetVacancyImport(r.Context(), id, organizationID)
+	if err != nil {
+		h.log.Warn("Failed to get vacancy import", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyImportErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, report)
+}
+
+// CommitVacancyImport starts creating the valid rows of an import in the
+// background. Progress is polled with GetVacancyImport.
+func (h *VacancyImportHandler) CommitVacancyImport(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy import ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	report, err := h.service.CommitVacancyImport(r.Context(), id, organizationID)
+	if err != nil {
+		h.log.Warn("Failed to commit vacancy import", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyImportErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy import committed successfully", slog.Int64("id", id))
+	lib.WriteJSON(w, http.StatusAccepted, report)
+}
+
+func vacancyImportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrVacancyImportNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrInvalidVacancyImport):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrVacancyImportState):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrVacancyForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
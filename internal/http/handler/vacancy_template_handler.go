@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/http/middleware"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type VacancyTemplateHandler struct {
+	log     *slog.Logger
+	service *service.VacancyTemplateService
+}
+
+func NewVacancyTemplateHandler(log *slog.Logger, service *service.VacancyTemplateService) *VacancyTemplateHandler {
+	return &VacancyTemplateHandler{
+		log:     log,
+		service: service,
+	}
+}
+
+func (h *VacancyTemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateVacancyTemplate
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	template, err := h.service.CreateTemplate(r.Context(), organizationID, userID, req)
+	if err != nil {
+		h.log.Warn("Failed to create vacancy template", slog.Any("error", err))
+		lib.WriteError(w, vacancyTemplateErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy template created successfully", slog.Int64("id", template.ID))
+	lib.WriteJSON(w, http.StatusCreated, template)
+}
+
+// SaveVacancyAsTemplate handles saving an existing vacancy as a template
+func (h *VacancyTemplateHandler) SaveVacancyAsTemplate(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req dto.SaveVacancyAsTemplate
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	template, err := h.service.SaveVacancyAsTemplate(r.Context(), id, organizationID, userID, req)
+	if err != nil {
+		h.log.Warn("Failed to save vacancy as template", slog.Int64("vacancy_id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyTemplateErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy saved as template successfully", slog.Int64("vacancy_id", id), slog.Int64("id", template.ID))
+	lib.WriteJSON(w, http.StatusCreated, template)
+}
+
+func (h *VacancyTemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	templates, err := h.service.ListTemplates(r.Context(), organizationID, userID)
+	if err != nil {
+		h.log.Error("Failed to list vacancy templates", slog.Any("error", err))
+		lib.WriteError(w, vacancyTemplateErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, templates)
+}
+
+// GetTemplate handles retrieving a template, in an earlier version with
+// ?version=
+func (h *VacancyTemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy template ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	version, _ := strconv.Atoi(r.URL.Query().Get("version"))
+
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	template, err := h.service.GetTemplate(r.Context(), id, organizationID, userID, version)
+	if err != nil {
+		h.log.Warn("Failed to get vacancy template", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyTemplateErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, template)
+}
+
+func (h *VacancyTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy template ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req dto.UpdateVacancyTemplate
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	template, err := h.service.UpdateTemplate(r.Context(), id, organizationID, userID, req)
+	if err != nil {
+		h.log.Warn("Failed to update vacancy template", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyTemplateErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy template updated successfully", slog.Int64("id", id), slog.Int("version", template.Version))
+	lib.WriteJSON(w, http.StatusOK, template)
+}
+
+func (h *VacancyTemplateHandler) ListTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy template ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	versions, err := h.service.ListTemplateVersions(r.Context(), id, organizationID, userID)
+	if err != nil {
+		h.log.Warn("Failed to list vacancy template versions", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyTemplateErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, versions)
+}
+
+func (h *VacancyTemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy template ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	if err := h.service.DeleteTemplate(r.Context(), id, organizationID, userID); err != nil {
+		h.log.Warn("Failed to delete vacancy template", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyTemplateErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy template deleted successfully", slog.Int64("id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateVacancyFromTemplate handles creating a vacancy from a template with
+// overrides
+func (h *VacancyTemplateHandler) CreateVacancyFromTemplate(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy template ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req dto.CreateVacancyFromTemplate
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r)
+	organizationID, _ := middleware.GetOrganizationID(r)
+	vacancy, err := h.service.CreateVacancyFromTemplate(r.Context(), id, organizationID, userID, req)
+	if err != nil {
+		h.log.Warn("Failed to create vacancy from template", slog.Int64("id", id), slog.Any("error", err))
+		var duplicate *model.DuplicateVacancyError
+		if errors.As(err, &duplicate) {
+			writeVacancyError(w, err)
+			return
+		}
+		lib.WriteError(w, vacancyTemplateErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy created from template successfully", slog.Int64("id", id), slog.Int64("vacancy_id", vacancy.Vacancy.ID))
+	lib.WriteJSON(w, http.StatusCreated, vacancy)
+}
+
+func vacancyTemplateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrVacancyTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrInvalidVacancyTemplate):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrVacancyTemplateForbidden):
+		return http.StatusForbidden
+	default:
+		return vacancyErrorStatus(err)
+	}
+}
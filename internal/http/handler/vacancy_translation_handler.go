@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/http/middleware"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListVacancyTranslations returns the organization's vacancy in every
+// locale, including machine drafts.
+func (h *VacancyHandler) ListVacancyTranslations(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	translations, err := h.service.ListVacancyTranslations(r.Context(), id, organizationID)
+	if err != nil {
+		h.log.Warn("Failed to list vacancy translations", slog.Int64("id", id), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	lib.WriteJSON(w, http.StatusOK, translations)
+}
+
+func (h *VacancyHandler) UpsertVacancyTranslation(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	locale := chi.URLParam(r, "locale")
+
+	var req dto.UpsertVacancyTranslation
+	if err := lib.ParseJSON(r, &req); err != nil {
+		h.log.Warn("Failed to parse request body", slog.Any("error", err))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	translation, err := h.service.UpsertVacancyTranslation(r.Context(), id, organizationID, locale, req)
+	if err != nil {
+		h.log.Warn("Failed to save vacancy translation", slog.Int64("id", id), slog.String("locale", locale), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy translation saved successfully", slog.Int64("id", id), slog.String("locale", locale))
+	lib.WriteJSON(w, http.StatusOK, translation)
+}
+
+// DraftVacancyTranslation fills in a machine translation for the employer
+// to review and save.
+func (h *VacancyHandler) DraftVacancyTranslation(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	locale := chi.URLParam(r, "locale")
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	translation, err := h.service.DraftVacancyTranslation(r.Context(), id, organizationID, locale)
+	if err != nil {
+		h.log.Warn("Failed to draft vacancy translation", slog.Int64("id", id), slog.String("locale", locale), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy translation drafted successfully", slog.Int64("id", id), slog.String("locale", locale))
+	lib.WriteJSON(w, http.StatusCreated, translation)
+}
+
+func (h *VacancyHandler) DeleteVacancyTranslation(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.log.Warn("Invalid vacancy ID", slog.String("id", idStr))
+		lib.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	locale := chi.URLParam(r, "locale")
+
+	organizationID, _ := middleware.GetOrganizationID(r)
+	if err := h.service.DeleteVacancyTranslation(r.Context(), id, organizationID, locale); err != nil {
+		h.log.Warn("Failed to delete vacancy translation", slog.Int64("id", id), slog.String("locale", locale), slog.Any("error", err))
+		lib.WriteError(w, vacancyErrorStatus(err), err)
+		return
+	}
+
+	h.log.Info("Vacancy translation deleted successfully", slog.Int64("id", id), slog.String("locale", locale))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -16,6 +16,7 @@ const (
 	UserIDKey           contextKey = "user_id"
 	OrganizationIDKey   contextKey = "organization_id"
 	OrganizationTypeKey contextKey = "organization_type"
+	RoleKey             contextKey = "role"
 )
 
 func AuthMiddleware(next http.Handler) http.Handler {
@@ -58,14 +59,35 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Tokens issued before roles existed carry no role claim.
+		role, _ := claims["role"].(string)
+
 		ctx := context.WithValue(r.Context(), UserIDKey, userID)
 		ctx = context.WithValue(ctx, OrganizationIDKey, orgID)
 		ctx = context.WithValue(ctx, OrganizationTypeKey, orgType)
+		ctx = context.WithValue(ctx, RoleKey, role)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// RequireRole only lets through requests whose token carries one of roles.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, _ := GetRole(r)
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "Insufficient permissions", http.StatusForbidden)
+		})
+	}
+}
+
 func extractToken(authHeader string) (string, error) {
 	parts := strings.Split(authHeader, " ")
 	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
@@ -107,3 +129,8 @@ func GetOrganizationType(r *http.Request) (string, bool) {
 	orgType, ok := r.Context().Value(OrganizationTypeKey).(string)
 	return orgType, ok
 }
+
+func GetRole(r *http.Request) (string, bool) {
+	role, ok := r.Context().Value(RoleKey).(string)
+	return role, ok
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/aidosgal/alem.core-service/internal/lib"
+)
+
+// Locale stores the languages preferred by the client, taken from the
+// Accept-Language header, in the request context.
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locales := lib.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+		next.ServeHTTP(w, r.WithContext(lib.WithLocales(r.Context(), locales)))
+	})
+}
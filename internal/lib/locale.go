@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const DefaultLocale = "ru"
+
+// SupportedLocales lists the languages content can be translated into.
+var SupportedLocales = []string{"ru", "kk", "uz", "ky", "en", "pl", "de"}
+
+type localeContextKey struct{}
+
+func IsSupportedLocale(locale string) bool {
+	for _, supported := range SupportedLocales {
+		if supported == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAcceptLanguage returns the supported locales from an Accept-Language
+// header ordered by preference, always ending with DefaultLocale.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		if i := strings.IndexAny(tag, "-_"); i > 0 {
+			tag = tag[:i]
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if value, ok := strings.CutPrefix(param, "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 || !IsSupportedLocale(tag) {
+			continue
+		}
+		candidates = append(candidates, weighted{locale: tag, q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	var locales []string
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if !seen[c.locale] {
+			seen[c.locale] = true
+			locales = append(locales, c.locale)
+		}
+	}
+	if !seen[DefaultLocale] {
+		locales = append(locales, DefaultLocale)
+	}
+	return locales
+}
+
+func WithLocales(ctx context.Context, locales []string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locales)
+}
+
+// LocalesFromContext returns the preferred locales stored in ctx, falling
+// back to DefaultLocale when none were set.
+func LocalesFromContext(ctx context.Context) []string {
+	if locales, ok := ctx.Value(localeContextKey{}).([]string); ok && len(locales) > 0 {
+		return locales
+	}
+	return []string{DefaultLocale}
+}
+
+// Localize picks the first translation matching the preferred locales, or
+// fallback when none exists.
+func Localize(ctx context.Context, translations map[string]string, fallback string) string {
+	for _, locale := range LocalesFromContext(ctx) {
+		if value, ok := translations[locale]; ok && value != "" {
+			return value
+		}
+	}
+	return fallback
+}
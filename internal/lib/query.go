@@ -0,0 +1,37 @@
+package lib
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseIntList collects integers from repeated and comma-separated query
+// values, e.g. ?category_id=1&category_id=2,3. Invalid or non-positive
+// entries are skipped.
+func ParseIntList(values []string) []int {
+	var result []int
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n <= 0 {
+				continue
+			}
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// ParseStringList collects non-empty strings from repeated and
+// comma-separated query values.
+func ParseStringList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
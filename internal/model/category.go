@@ -1,5 +1,15 @@
 package model
 
+import "errors"
+
+var (
+	ErrCategoryNotFound   = errors.New("category not found")
+	ErrCategoryCycle      = errors.New("category cannot be moved into its own subtree")
+	ErrCategoryNotSibling = errors.New("categories must share the same parent")
+	ErrUnsupportedLocale  = errors.New("unsupported locale")
+	ErrCategoryInUse      = errors.New("category is still used by vacancies or resumes")
+)
+
 type Category struct {
 	ID       int
 	Name     string
@@ -8,3 +18,30 @@ type Category struct {
 	Right    int
 	Depth    int
 }
+
+// SameCategoryParent reports whether two parent IDs point at the same
+// parent, nil being the top level.
+func SameCategoryParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// CategoryImport is a category an import renames, or creates when ID is
+// zero. A new category goes under Parent when that is created by the same
+// import, otherwise under ParentID. Translations get the category's ID once
+// it is known.
+type CategoryImport struct {
+	ID           int
+	Name         string
+	ParentID     *int
+	Parent       *CategoryImport
+	Translations []CategoryTranslation
+}
+
+type CategoryTranslation struct {
+	CategoryID int
+	Locale     string
+	Name       string
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeEnum    = "enum"
+	AttributeTypeNumber  = "number"
+	AttributeTypeRange   = "range"
+)
+
+var (
+	ErrCategoryAttributeNotFound = errors.New("category attribute not found")
+	ErrInvalidCategoryAttribute  = errors.New("invalid category attribute")
+	ErrInvalidVacancyAttribute   = errors.New("invalid vacancy attribute")
+	ErrInvalidAttributeFilter    = errors.New("invalid attribute filter")
+)
+
+// CategoryAttribute is a typed attribute of the vacancies in a category and
+// its subcategories. Options lists the values of an enum; Min and Max bound
+// numbers and ranges.
+type CategoryAttribute struct {
+	ID         int64
+	CategoryID int
+	Key        string
+	Name       string
+	Type       string
+	Options    []string
+	Unit       string
+	Min        *float64
+	Max        *float64
+	Required   bool
+	CreatedAt  time.Time
+}
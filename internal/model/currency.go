@@ -0,0 +1,35 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// BaseCurrency is the currency normalized salaries are stored in. Its rate is
+// always 1.
+const BaseCurrency = "EUR"
+
+const (
+	SalaryTypeHour  = "hour"
+	SalaryTypeDay   = "day"
+	SalaryTypeWeek  = "week"
+	SalaryTypeMonth = "month"
+	SalaryTypeYear  = "year"
+)
+
+var SalaryTypes = []string{SalaryTypeHour, SalaryTypeDay, SalaryTypeWeek, SalaryTypeMonth, SalaryTypeYear}
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidCurrencyRate = errors.New("invalid currency rate")
+	ErrInvalidSalaryType   = errors.New("invalid salary type")
+)
+
+// Currency holds an exchange rate as the number of currency units worth one
+// unit of BaseCurrency.
+type Currency struct {
+	Code      string
+	Name      string
+	Rate      float64
+	UpdatedAt time.Time
+}
@@ -0,0 +1,49 @@
+package model
+
+import "errors"
+
+var (
+	ErrCountryNotFound    = errors.New("country not found")
+	ErrRegionNotFound     = errors.New("region not found")
+	ErrCityNotFound       = errors.New("city not found")
+	ErrLocationMismatch   = errors.New("region or city does not belong to the country")
+	ErrInvalidCoordinates = errors.New("invalid coordinates")
+)
+
+type Country struct {
+	Code string
+	Name string
+}
+
+type Region struct {
+	ID          int
+	CountryCode string
+	Name        string
+}
+
+type City struct {
+	ID        int
+	RegionID  int
+	Name      string
+	Latitude  *float64
+	Longitude *float64
+}
+
+// CountrySeed is one country of the embedded reference dataset together with
+// its regions and cities.
+type CountrySeed struct {
+	Code    string       `json:"code"`
+	Name    string       `json:"name"`
+	Regions []RegionSeed `json:"regions"`
+}
+
+type RegionSeed struct {
+	Name   string     `json:"name"`
+	Cities []CitySeed `json:"cities"`
+}
+
+type CitySeed struct {
+	Name      string   `json:"name"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	ModerationActionFlagged          = "flagged"
+	ModerationActionSubmitted        = "submitted"
+	ModerationActionApproved         = "approved"
+	ModerationActionRejected         = "rejected"
+	ModerationActionChangesRequested = "changes_requested"
+
+	NotificationVacancyModerated = "vacancy_moderated"
+)
+
+const (
+	RiskRuleUpfrontPayment    = "upfront_payment"
+	RiskRulePassport          = "passport_withholding"
+	RiskRuleUnrealisticSalary = "unrealistic_salary"
+	RiskRuleExternalContacts  = "external_contacts"
+	RiskRuleBlacklist         = "blacklist"
+)
+
+const (
+	BlacklistPhrase = "phrase"
+	BlacklistDomain = "domain"
+	BlacklistPhone  = "phone"
+	BlacklistEmail  = "email"
+)
+
+var (
+	ErrInvalidModerationAction = errors.New("invalid moderation action")
+	ErrModerationReasonMissing = errors.New("a reason is required for this moderation action")
+	ErrBlacklistEntryNotFound  = errors.New("blacklist entry not found")
+	ErrInvalidBlacklistEntry   = errors.New("invalid blacklist entry")
+)
+
+// RiskFlag is one rule that matched a vacancy. Detail quotes what matched.
+type RiskFlag struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+// RiskAssessment is the result of running every risk rule on a vacancy.
+type RiskAssessment struct {
+	Score int
+	Flags []RiskFlag
+}
+
+// ModerationLogEntry records an automatic flag or a moderator decision.
+// ModeratorID is nil for automatic entries.
+type ModerationLogEntry struct {
+	ID          int64
+	VacancyID   int64
+	ModeratorID *int64
+	Action      string
+	Reason      string
+	RiskScore   int
+	Flags       []RiskFlag
+	CreatedAt   time.Time
+}
+
+type BlacklistEntry struct {
+	ID        int64
+	Kind      string
+	Value     string
+	Reason    string
+	CreatedAt time.Time
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	NotificationVacancyExpiring = "vacancy_expiring"
+	NotificationVacancyExpired  = "vacancy_expired"
+)
+
+type Notification struct {
+	ID        int64
+	UserID    int64
+	Type      string
+	Title     string
+	Body      string
+	Payload   json.RawMessage
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
@@ -1,7 +1,12 @@
 package model
 
+import "errors"
+
+var ErrOrganizationNotFound = errors.New("organization not found")
+
 type Organization struct {
 	Id          int
 	Name        string
 	Description string
+	Verified    bool
 }
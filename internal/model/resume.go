@@ -1,6 +1,17 @@
 package model
 
-import "github.com/aidosgal/alem.core-service/internal/dto"
+import (
+	"errors"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+)
+
+var (
+	ErrResumeNotFound           = errors.New("resume not found")
+	ErrResumeForbidden          = errors.New("resume belongs to another user")
+	ErrResumeSkillNotFound      = errors.New("resume skill not found")
+	ErrResumeExperienceNotFound = errors.New("resume experience not found")
+)
 
 type Resume struct {
 	Id           int                   `json:"id"`
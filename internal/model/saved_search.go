@@ -0,0 +1,37 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	SavedSearchInstant = "instant"
+	SavedSearchDaily   = "daily"
+
+	NotificationSavedSearchMatch = "saved_search_match"
+)
+
+var (
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+	ErrSavedSearchLimit    = errors.New("too many saved searches")
+	ErrInvalidSavedSearch  = errors.New("invalid saved search")
+)
+
+// SavedSearch is a vacancy search a user wants to be alerted about. Vacancies
+// published after (LastPublishedAt, LastVacancyID) have not been reported
+// yet.
+type SavedSearch struct {
+	ID               int64
+	UserID           int64
+	Name             string
+	Filters          json.RawMessage
+	Frequency        string
+	Active           bool
+	LastPublishedAt  time.Time
+	LastVacancyID    int64
+	LastCheckedAt    *time.Time
+	UnsubscribeToken string
+	CreatedAt        time.Time
+}
@@ -1,11 +1,18 @@
 package model
 
+const (
+	UserRoleUser      = "user"
+	UserRoleAdmin     = "admin"
+	UserRoleModerator = "moderator"
+)
+
 type User struct {
 	Id             int     `json:"id"`
 	Name           string  `json:"name"`
 	OrganizationId int     `json:"organization_id"`
 	Phone         string  `json:"phone"`
 	Password      string  `json:"password"`
+	Role          string  `json:"role"`
 	AvatarURL     string  `json:"avatar_url"`
 	Balance       float64 `json:"balance"`
 	CreatedAt     string  `json:"created_at"`
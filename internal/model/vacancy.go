@@ -1,18 +1,73 @@
 package model
 
+import (
+	"errors"
+	"time"
+)
+
+const (
+	VacancyStatusDraft             = "draft"
+	VacancyStatusPendingModeration = "pending_moderation"
+	VacancyStatusChangesRequested  = "changes_requested"
+	VacancyStatusRejected          = "rejected"
+	VacancyStatusPublished         = "published"
+	VacancyStatusPaused            = "paused"
+	VacancyStatusClosed            = "closed"
+	VacancyStatusExpired           = "expired"
+)
+
+var (
+	ErrVacancyNotFound          = errors.New("vacancy not found")
+	ErrVacancyDetailNotFound    = errors.New("vacancy detail not found")
+	ErrVacancyForbidden         = errors.New("vacancy belongs to another organization")
+	ErrInvalidVacancyStatus     = errors.New("invalid vacancy status")
+	ErrInvalidVacancyTransition = errors.New("vacancy status transition is not allowed")
+	ErrVacancyRestoreExpired    = errors.New("vacancy can no longer be restored")
+	ErrInvalidVacancySort       = errors.New("invalid vacancy sort")
+	ErrInvalidVacancyCursor     = errors.New("invalid vacancy cursor")
+	ErrInvalidVacancyTotal      = errors.New("invalid vacancy total mode")
+)
+
 type Vacancy struct {
 	ID             int64    `db:"id"`
 	Title          string   `db:"title"`
 	Description    string   `db:"description"`
+	PrimaryLocale  string   `db:"primary_locale"` // of Title and Description
 	SalaryFrom     *float64 `db:"salary_from"`
 	SalaryTo       *float64 `db:"salary_to"`
 	SalaryExact    *float64 `db:"salary_exact"`
 	SalaryType     string   `db:"salary_type"`
 	SalaryCurrency string   `db:"salary_currency"`
-	Country        string   `db:"country"`
-	OrganizationID int64    `db:"organization_id"`
-	CategoryID     int64    `db:"category_id"`
+	// Monthly salaries in BaseCurrency, kept in sync by the repository.
+	SalaryFromBase  *float64 `db:"salary_from_base"`
+	SalaryToBase    *float64 `db:"salary_to_base"`
+	SalaryExactBase *float64 `db:"salary_exact_base"`
+	Country         string   `db:"country"`
+	CountryCode     string   `db:"country_code"`
+	RegionID        *int     `db:"region_id"`
+	CityID          *int     `db:"city_id"`
+	Latitude        *float64 `db:"latitude"`
+	Longitude       *float64 `db:"longitude"`
+	// DistanceKm is only set by radius searches.
+	DistanceKm     *float64
+	OrganizationID int64      `db:"organization_id"`
+	CategoryID     int64      `db:"category_id"`
+	Status         string     `db:"status"`
+	PublishedAt    *time.Time `db:"published_at"`
+	ExpiresAt      *time.Time `db:"expires_at"`
+	DeletedAt      *time.Time `db:"deleted_at"`
+	RiskScore      int        `db:"risk_score"` // of the last automatic risk assessment
 	CreatedAt      string
+	UpdatedAt      *time.Time `db:"updated_at"`
+	// DuplicateClusterID groups near-duplicates posted by other organizations.
+	DuplicateClusterID *int64 `db:"duplicate_cluster_id"`
+	// Duplicates is only set by lists that collapse duplicate clusters.
+	Duplicates int
+	// SortKey is the position of the vacancy in a list, as text, for the
+	// cursor of the next page.
+	SortKey string
+	// Version counts the content changes of the vacancy, starting at 1.
+	Version int `db:"version"`
 }
 
 type VacancyDetail struct {
@@ -22,4 +77,36 @@ type VacancyDetail struct {
 	Value     string  `db:"value"`
 	IconURL   *string `db:"icon_url"`
 	VacancyID int64   `db:"vacancy_id"`
+	// AttributeKey links the detail to a typed category attribute. Its
+	// parsed value is kept in the typed fields, with NumberValue and
+	// NumberTo as the bounds of a range.
+	AttributeKey *string  `db:"attribute_key"`
+	BoolValue    *bool    `db:"bool_value"`
+	NumberValue  *float64 `db:"number_value"`
+	NumberTo     *float64 `db:"number_to"`
+}
+
+// VacancyWrite is what is stored along with a created or updated vacancy,
+// in the same transaction as the vacancy itself.
+type VacancyWrite struct {
+	Details      []VacancyDetail
+	Translations []VacancyTranslation
+	// DeleteTranslation removes the translation into a locale that became
+	// the primary one.
+	DeleteTranslation string
+	// Fingerprint is stored with the vacancy put into ClusterID together
+	// with ClusterMembers. It is nil for vacancies too short to compare.
+	Fingerprint    *VacancyFingerprint
+	ClusterID      *int64
+	ClusterMembers []int64
+	// Moderation is logged for the moderators; without it only RiskScore is
+	// stored. With ModerationTo set the vacancy also moves from
+	// ModerationFrom to it, and the write fails with
+	// ErrInvalidVacancyTransition when its status changed in the meantime.
+	RiskScore      int
+	Moderation     *ModerationLogEntry
+	ModerationFrom string
+	ModerationTo   string
+	// Change records an update in the version history.
+	Change *VacancyChange
 }
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrDuplicateVacancy = errors.New("duplicate vacancy")
+
+// DuplicateVacancyError points to the organization's existing vacancy that a
+// new or edited vacancy duplicates.
+type DuplicateVacancyError struct {
+	VacancyID  int64
+	Similarity float64
+}
+
+func (e *DuplicateVacancyError) Error() string {
+	return fmt.Sprintf("%s: %.0f%% similar to vacancy %d", ErrDuplicateVacancy, e.Similarity*100, e.VacancyID)
+}
+
+func (e *DuplicateVacancyError) Is(target error) bool {
+	return target == ErrDuplicateVacancy
+}
+
+// VacancyFingerprint is the MinHash signature of a vacancy's text with the
+// band hashes used to look up candidate duplicates.
+type VacancyFingerprint struct {
+	VacancyID int64
+	Signature []int64
+	Bands     []int64
+}
+
+// DuplicateCandidate is a vacancy sharing at least one band with a
+// fingerprint.
+type DuplicateCandidate struct {
+	VacancyID          int64
+	OrganizationID     int64
+	DuplicateClusterID *int64
+	Signature          []int64
+}
@@ -0,0 +1,20 @@
+package model
+
+// Facet dimensions of a vacancy list. Each facet counts vacancies under
+// every filter of the list except the one on its own dimension.
+const (
+	VacancyFacetCategory = "category"
+	VacancyFacetCountry  = "country"
+	VacancyFacetSalary   = "salary"
+	VacancyFacetCurrency = "currency"
+	VacancyFacetVerified = "verified"
+)
+
+// VacancyFacetCount is the number of vacancies with Value in Dimension: a
+// top-level category ID, a country code, the index of a salary bucket, a
+// currency code or whether the employer is verified.
+type VacancyFacetCount struct {
+	Dimension string
+	Value     string
+	Count     int
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	VacancyImportFormatCSV  = "csv"
+	VacancyImportFormatXLSX = "xlsx"
+	VacancyImportFormatJSON = "json"
+)
+
+// An import is validated on upload, queued once the employer commits it and
+// then run by the import job.
+const (
+	VacancyImportStatusValidated = "validated"
+	VacancyImportStatusQueued    = "queued"
+	VacancyImportStatusRunning   = "running"
+	VacancyImportStatusCompleted = "completed"
+	VacancyImportStatusFailed    = "failed"
+
+	NotificationVacancyImportFinished = "vacancy_import_finished"
+)
+
+var (
+	ErrVacancyImportNotFound = errors.New("vacancy import not found")
+	ErrInvalidVacancyImport  = errors.New("invalid vacancy import file")
+	ErrVacancyImportState    = errors.New("vacancy import cannot be committed in its current state")
+)
+
+// VacancyImport is a file of vacancies uploaded by an organization. Payload
+// holds the rows that passed validation and Errors the rows that did not or
+// that failed to be created; ProcessedRows counts committed payload rows.
+type VacancyImport struct {
+	ID             int64
+	OrganizationID int64
+	UserID         int64
+	FileName       string
+	Format         string
+	Status         string
+	TotalRows      int
+	ValidRows      int
+	ProcessedRows  int
+	CreatedRows    int
+	Errors         json.RawMessage
+	Payload        json.RawMessage
+	CreatedAt      time.Time
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAlreadyApplied    = errors.New("already applied to this vacancy")
+	ErrApplicationResume = errors.New("resume not found for this user")
+	// ErrVacancyVersionChanged means the vacancy was edited after the
+	// candidate saw it.
+	ErrVacancyVersionChanged = errors.New("vacancy has changed since it was viewed")
+)
+
+type VacancyApplication struct {
+	ID        int64
+	VacancyID int64
+	UserID    int64
+	ResumeID  *int64
+	CreatedAt time.Time
+	// VacancyVersion is the version of the vacancy the user applied to. When
+	// set before creating the application it must still be the current
+	// one.
+	VacancyVersion int
+}
+
+type VacancyDailyStats struct {
+	Day          time.Time
+	Views        int
+	Applications int
+}
+
+// VacancyStats summarizes the audience of a vacancy over a period.
+type VacancyStats struct {
+	Views         int
+	UniqueViewers int
+	Applications  int
+	Daily         []VacancyDailyStats
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrVacancyTemplateNotFound  = errors.New("vacancy template not found")
+	ErrVacancyTemplateForbidden = errors.New("only the author can change a vacancy template")
+	ErrInvalidVacancyTemplate   = errors.New("invalid vacancy template")
+)
+
+// VacancyTemplate is a reusable vacancy of an organization, written by
+// UserID. Content is its latest version.
+type VacancyTemplate struct {
+	ID             int64
+	OrganizationID int64
+	UserID         int64
+	Name           string
+	Shared         bool
+	Version        int
+	Content        json.RawMessage
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// VacancyTemplateVersion is the content of a template as saved by UserID.
+type VacancyTemplateVersion struct {
+	TemplateID int64
+	Version    int
+	UserID     int64
+	Content    json.RawMessage
+	CreatedAt  time.Time
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrVacancyTranslationNotFound = errors.New("vacancy translation not found")
+	ErrPrimaryLocaleTranslation   = errors.New("the primary locale text is edited on the vacancy itself")
+	ErrVacancyTranslationEdited   = errors.New("vacancy translation was edited by the employer")
+	ErrInvalidVacancyTranslation  = errors.New("invalid vacancy translation")
+)
+
+// VacancyTranslation is the title and description of a vacancy in a locale
+// other than its primary one. Machine translations are drafts the employer
+// has not edited yet.
+type VacancyTranslation struct {
+	VacancyID   int64     `db:"vacancy_id"`
+	Locale      string    `db:"locale"`
+	Title       string    `db:"title"`
+	Description string    `db:"description"`
+	Machine     bool      `db:"machine"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
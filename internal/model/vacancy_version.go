@@ -0,0 +1,54 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrVacancyVersionNotFound = errors.New("vacancy version not found")
+
+// VacancyVersion is a snapshot of a vacancy after one of its changes.
+// Content holds a VacancySnapshot as JSON. UserID is nil for the state
+// before the first recorded change.
+type VacancyVersion struct {
+	VacancyID int64
+	Version   int
+	UserID    *int64
+	Content   []byte
+	CreatedAt time.Time
+}
+
+// VacancySnapshot is the content of a vacancy that candidates see.
+type VacancySnapshot struct {
+	Title          string                  `json:"title"`
+	Description    string                  `json:"description"`
+	PrimaryLocale  string                  `json:"primary_locale"`
+	SalaryFrom     *float64                `json:"salary_from"`
+	SalaryTo       *float64                `json:"salary_to"`
+	SalaryExact    *float64                `json:"salary_exact"`
+	SalaryType     string                  `json:"salary_type"`
+	SalaryCurrency string                  `json:"salary_currency"`
+	CategoryID     int64                   `json:"category_id"`
+	Country        string                  `json:"country"`
+	CountryCode    string                  `json:"country_code"`
+	RegionID       *int                    `json:"region_id"`
+	CityID         *int                    `json:"city_id"`
+	Latitude       *float64                `json:"latitude"`
+	Longitude      *float64                `json:"longitude"`
+	Details        []VacancySnapshotDetail `json:"details"`
+}
+
+type VacancySnapshotDetail struct {
+	GroupName string  `json:"group_name"`
+	Name      string  `json:"name"`
+	Value     string  `json:"value"`
+	Key       *string `json:"key,omitempty"`
+}
+
+// VacancyChange records an update of a vacancy: the snapshots before and
+// after it, as JSON, and the user who made it.
+type VacancyChange struct {
+	UserID   int64
+	Previous []byte
+	Current  []byte
+}
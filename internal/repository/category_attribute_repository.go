@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
+)
+
+const categoryAttributeColumns = `a.id, a.category_id, a.key, a.name, a.type, a.options, a.unit, a.min_value, a.max_value, a.required, a.created_at`
+
+type CategoryAttributeRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewCategoryAttributeRepository(log *slog.Logger, db *sql.DB) *CategoryAttributeRepository {
+	return &CategoryAttributeRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+// FindForCategory returns the attributes defined on the category and on its
+// ancestors, from the top-level category down.
+func (r *CategoryAttributeRepository) FindForCategory(ctx context.Context, categoryID int) ([]model.CategoryAttribute, error) {
+	query := `SELECT ` + categoryAttributeColumns + `
+			FROM category_attributes a
+			JOIN categories parent ON parent.id = a.category_id
+			JOIN categories child ON child.lft >= parent.lft AND child.rgt <= parent.rgt
+			WHERE child.id = $1
+			ORDER BY parent.depth, a.id`
+	return scanCategoryAttributes(r.db.QueryContext(ctx, query, categoryID))
+}
+
+func (r *CategoryAttributeRepository) FindByKey(ctx context.Context, categoryID int, key string) (*model.CategoryAttribute, error) {
+	query := `SELECT ` + categoryAttributeColumns + ` FROM category_attributes a WHERE a.category_id = $1 AND a.key = $2`
+	attribute, err := scanCategoryAttribute(r.db.QueryRowContext(ctx, query, categoryID, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return attribute, err
+}
+
+// KeyTypes returns the type of each of the keys that is defined on any
+// category.
+func (r *CategoryAttributeRepository) KeyTypes(ctx context.Context, keys []string) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT key, type FROM category_attributes WHERE key = ANY($1)`, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var key, attributeType string
+		if err := rows.Scan(&key, &attributeType); err != nil {
+			return nil, err
+		}
+		types[key] = attributeType
+	}
+	return types, rows.Err()
+}
+
+// Upsert creates the attribute or updates the one with the same category
+// and key.
+func (r *CategoryAttributeRepository) Upsert(ctx context.Context, attribute *model.CategoryAttribute) error {
+	query := `INSERT INTO category_attributes (category_id, key, name, type, options, unit, min_value, max_value, required)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (category_id, key) DO UPDATE
+			SET name = EXCLUDED.name, options = EXCLUDED.options, unit = EXCLUDED.unit, min_value = EXCLUDED.min_value,
+				max_value = EXCLUDED.max_value, required = EXCLUDED.required, updated_at = NOW()
+			RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query, attribute.CategoryID, attribute.Key, attribute.Name, attribute.Type, pq.Array(attribute.Options),
+		attribute.Unit, attribute.Min, attribute.Max, attribute.Required).Scan(&attribute.ID, &attribute.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return model.ErrCategoryNotFound
+	}
+	return err
+}
+
+// Delete removes the attribute and reports whether it existed.
+func (r *CategoryAttributeRepository) Delete(ctx context.Context, categoryID int, key string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM category_attributes WHERE category_id = $1 AND key = $2`, categoryID, key)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func scanCategoryAttribute(row rowScanner) (*model.CategoryAttribute, error) {
+	var a model.CategoryAttribute
+	err := row.Scan(&a.ID, &a.CategoryID, &a.Key, &a.Name, &a.Type, pq.Array(&a.Options), &a.Unit, &a.Min, &a.Max, &a.Required, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func scanCategoryAttributes(rows *sql.Rows, err error) ([]model.CategoryAttribute, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attributes []model.CategoryAttribute
+	for rows.Next() {
+		a, err := scanCategoryAttribute(rows)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, *a)
+	}
+	return attributes, rows.Err()
+}
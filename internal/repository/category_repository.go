@@ -4,82 +4,256 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
 
 	"github.com/aidosgal/alem.core-service/internal/model"
 )
 
 type CategoryRepository struct {
-    log *slog.Logger
-	db *sql.DB
+	log *slog.Logger
+	db  *sql.DB
 }
 
 func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
+// Insert adds the category as the last child of category.ParentID, or as the
+// last root when ParentID is nil, shifting the rest of the tree to make room.
 func (r *CategoryRepository) Insert(ctx context.Context, category *model.Category) error {
-	query := `
-        INSERT INTO categories 
-        (name, lft, rgt, depth) 
-        VALUES ($1, $2, $3, $4) 
-        RETURNING id
-    `
-	return r.db.QueryRowContext(
-        ctx, 
-        query, 
-        category.Name, 
-        category.Left, 
-        category.Right, 
-        category.Depth,
-    ).Scan(&category.ID)
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCategories(ctx, tx); err != nil {
+			return err
+		}
+		return insertCategory(ctx, tx, category)
+	})
+}
+
+// Import applies the imports in order in a single transaction, so a failing
+// entry leaves the tree and its translations untouched. It returns how many
+// categories were created and renamed.
+func (r *CategoryRepository) Import(ctx context.Context, imports []*model.CategoryImport) (created, updated int, err error) {
+	err = withTx(ctx, r.db, func(tx *sql.Tx) error {
+		created, updated = 0, 0
+		if err := lockCategories(ctx, tx); err != nil {
+			return err
+		}
+
+		var translations []model.CategoryTranslation
+		for _, entry := range imports {
+			if entry.ID == 0 {
+				category := &model.Category{Name: entry.Name, ParentID: entry.ParentID}
+				if entry.Parent != nil {
+					category.ParentID = &entry.Parent.ID
+				}
+				if err := insertCategory(ctx, tx, category); err != nil {
+					return err
+				}
+				entry.ID = category.ID
+				created++
+			} else {
+				category, err := findCategory(ctx, tx, entry.ID)
+				if err != nil {
+					return fmt.Errorf("%w: %d", err, entry.ID)
+				}
+				if entry.Name != "" && entry.Name != category.Name {
+					if _, err := tx.ExecContext(ctx, `UPDATE categories SET name = $1 WHERE id = $2`, entry.Name, entry.ID); err != nil {
+						return err
+					}
+					updated++
+				}
+			}
+
+			for _, t := range entry.Translations {
+				t.CategoryID = entry.ID
+				translations = append(translations, t)
+			}
+		}
+		return upsertCategoryTranslations(ctx, tx, translations)
+	})
+	return created, updated, err
 }
 
 func (r *CategoryRepository) FindByID(ctx context.Context, id int) (*model.Category, error) {
-	query := `
-        SELECT id, name, lft, rgt, depth 
-        FROM categories 
-        WHERE id = $1
-    `
-	var category model.Category
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-        &category.ID, 
-        &category.Name, 
-        &category.Left, 
-        &category.Right, 
-        &category.Depth,
-    )
+	category, err := findCategory(ctx, r.db, id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, model.ErrCategoryNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &category, nil
+	return category, nil
 }
 
 func (r *CategoryRepository) Update(ctx context.Context, category *model.Category) error {
 	query := `
-        UPDATE categories 
-        SET name = $1 
+        UPDATE categories
+        SET name = $1
         WHERE id = $2
     `
 	_, err := r.db.ExecContext(
-        ctx, query, 
-        category.Name, category.ID,
-    )
+		ctx, query,
+		category.Name, category.ID,
+	)
 	return err
 }
 
+// UpdateAndMove renames the category and, when parentID is not its parent,
+// moves its subtree to the end of parentID's children in the same
+// transaction.
+func (r *CategoryRepository) UpdateAndMove(ctx context.Context, category *model.Category, parentID *int) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCategories(ctx, tx); err != nil {
+			return err
+		}
+		stored, err := findCategory(ctx, tx, category.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE categories SET name = $1 WHERE id = $2`, category.Name, category.ID); err != nil {
+			return err
+		}
+		if model.SameCategoryParent(stored.ParentID, parentID) {
+			return nil
+		}
+		return moveCategory(ctx, tx, category.ID, parentID, -1)
+	})
+}
+
+// Move detaches the subtree rooted at id and re-attaches it under parentID
+// (nil for the top level) at the given position among the new siblings. A
+// negative or out of range position appends the subtree as the last child.
+func (r *CategoryRepository) Move(ctx context.Context, id int, parentID *int, position int) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCategories(ctx, tx); err != nil {
+			return err
+		}
+		return moveCategory(ctx, tx, id, parentID, position)
+	})
+}
+
+// Reorder arranges the children of parentID in the order of ids. Children
+// that are not listed keep their relative order after the listed ones.
+func (r *CategoryRepository) Reorder(ctx context.Context, parentID *int, ids []int) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCategories(ctx, tx); err != nil {
+			return err
+		}
+
+		for position, id := range ids {
+			category, err := findCategory(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			if !model.SameCategoryParent(category.ParentID, parentID) {
+				return model.ErrCategoryNotSibling
+			}
+			if err := moveCategory(ctx, tx, id, parentID, position); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the category together with its whole subtree and closes the
+// gap it leaves behind.
 func (r *CategoryRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM categories WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCategories(ctx, tx); err != nil {
+			return err
+		}
+
+		category, err := findCategory(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		inUse, err := categoryInUse(ctx, tx, `lft >= $1 AND rgt <= $2`, category.Left, category.Right)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return model.ErrCategoryInUse
+		}
+
+		query := `DELETE FROM categories WHERE lft >= $1 AND rgt <= $2`
+		if _, err := tx.ExecContext(ctx, query, category.Left, category.Right); err != nil {
+			return err
+		}
+
+		width := category.Right - category.Left + 1
+		return shiftCategories(ctx, tx, category.Right+1, -width)
+	})
+}
+
+// DeleteAndReparent removes a single category and lifts its children one
+// level up, attaching them to the removed category's parent.
+func (r *CategoryRepository) DeleteAndReparent(ctx context.Context, id int) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCategories(ctx, tx); err != nil {
+			return err
+		}
+
+		category, err := findCategory(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		inUse, err := categoryInUse(ctx, tx, `id = $1`, category.ID)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return model.ErrCategoryInUse
+		}
+
+		queries := []struct {
+			query string
+			args  []any
+		}{
+			{`UPDATE categories SET parent_id = $1 WHERE parent_id = $2`, []any{category.ParentID, category.ID}},
+			{`DELETE FROM categories WHERE id = $1`, []any{category.ID}},
+			{`
+        UPDATE categories
+        SET lft = lft - 1, rgt = rgt - 1, depth = depth - 1
+        WHERE lft > $1 AND rgt < $2
+    `, []any{category.Left, category.Right}},
+		}
+		for _, q := range queries {
+			if _, err := tx.ExecContext(ctx, q.query, q.args...); err != nil {
+				return err
+			}
+		}
+
+		return shiftCategories(ctx, tx, category.Right+1, -2)
+	})
+}
+
+// Rebuild overwrites the nested set columns and parents of every given
+// category in a single transaction.
+func (r *CategoryRepository) Rebuild(ctx context.Context, categories []model.Category) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCategories(ctx, tx); err != nil {
+			return err
+		}
+
+		query := `
+        UPDATE categories
+        SET parent_id = $1, lft = $2, rgt = $3, depth = $4
+        WHERE id = $5
+    `
+		for _, c := range categories {
+			if _, err := tx.ExecContext(ctx, query, c.ParentID, c.Left, c.Right, c.Depth, c.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (r *CategoryRepository) FindAll(ctx context.Context) ([]model.Category, error) {
 	query := `
-        SELECT id, name, lft, rgt, depth 
+        SELECT id, name, parent_id, lft, rgt, depth
         FROM categories ORDER BY lft
     `
 	rows, err := r.db.QueryContext(ctx, query)
@@ -92,17 +266,321 @@ func (r *CategoryRepository) FindAll(ctx context.Context) ([]model.Category, err
 	for rows.Next() {
 		var category model.Category
 		err := rows.Scan(
-            &category.ID, 
-            &category.Name, 
-            &category.Left, 
-            &category.Right, 
-            &category.Depth,
-        )
+			&category.ID,
+			&category.Name,
+			&category.ParentID,
+			&category.Left,
+			&category.Right,
+			&category.Depth,
+		)
 		if err != nil {
 			return nil, err
 		}
 		categories = append(categories, category)
 	}
-	return categories, nil
+	return categories, rows.Err()
+}
+
+// FindTranslations returns every category translation keyed by category ID
+// and locale.
+func (r *CategoryRepository) FindTranslations(ctx context.Context) (map[int]map[string]string, error) {
+	query := `SELECT category_id, locale, name FROM category_translations`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[int]map[string]string)
+	for rows.Next() {
+		var t model.CategoryTranslation
+		if err := rows.Scan(&t.CategoryID, &t.Locale, &t.Name); err != nil {
+			return nil, err
+		}
+		if translations[t.CategoryID] == nil {
+			translations[t.CategoryID] = make(map[string]string)
+		}
+		translations[t.CategoryID][t.Locale] = t.Name
+	}
+	return translations, rows.Err()
+}
+
+func (r *CategoryRepository) FindTranslationsByCategory(ctx context.Context, categoryID int) ([]model.CategoryTranslation, error) {
+	query := `
+        SELECT category_id, locale, name
+        FROM category_translations
+        WHERE category_id = $1
+        ORDER BY locale
+    `
+	rows, err := r.db.QueryContext(ctx, query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var translations []model.CategoryTranslation
+	for rows.Next() {
+		var t model.CategoryTranslation
+		if err := rows.Scan(&t.CategoryID, &t.Locale, &t.Name); err != nil {
+			return nil, err
+		}
+		translations = append(translations, t)
+	}
+	return translations, rows.Err()
+}
+
+// UpsertTranslations creates or replaces the given translations in a single
+// transaction.
+func (r *CategoryRepository) UpsertTranslations(ctx context.Context, translations []model.CategoryTranslation) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return upsertCategoryTranslations(ctx, tx, translations)
+	})
+}
+
+func upsertCategoryTranslations(ctx context.Context, tx *sql.Tx, translations []model.CategoryTranslation) error {
+	query := `
+        INSERT INTO category_translations (category_id, locale, name)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (category_id, locale)
+        DO UPDATE SET name = EXCLUDED.name, updated_at = NOW()
+    `
+	for _, t := range translations {
+		if _, err := tx.ExecContext(ctx, query, t.CategoryID, t.Locale, t.Name); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+func (r *CategoryRepository) DeleteTranslation(ctx context.Context, categoryID int, locale string) error {
+	query := `DELETE FROM category_translations WHERE category_id = $1 AND locale = $2`
+	_, err := r.db.ExecContext(ctx, query, categoryID, locale)
+	return err
+}
+
+func findCategory(ctx context.Context, q rowQuerier, id int) (*model.Category, error) {
+	query := `
+        SELECT id, name, parent_id, lft, rgt, depth
+        FROM categories
+        WHERE id = $1
+    `
+	var category model.Category
+	err := q.QueryRowContext(ctx, query, id).Scan(
+		&category.ID,
+		&category.Name,
+		&category.ParentID,
+		&category.Left,
+		&category.Right,
+		&category.Depth,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// categoryInUse reports whether vacancies, resumes or resume experiences
+// reference any category matching the condition. None of them has a foreign
+// key on categories, so deletes check it here.
+func categoryInUse(ctx context.Context, tx *sql.Tx, condition string, args ...any) (bool, error) {
+	categories := `SELECT id FROM categories WHERE ` + condition
+	query := `SELECT EXISTS (SELECT 1 FROM vacancies WHERE category_id IN (` + categories + `))
+			OR EXISTS (SELECT 1 FROM resumes WHERE category_id IN (` + categories + `))
+			OR EXISTS (SELECT 1 FROM resume_experiences WHERE category_id IN (` + categories + `))`
+	var inUse bool
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&inUse)
+	return inUse, err
+}
+
+// insertCategory adds the category as the last child of its parent, or last
+// at the top level. The caller must hold lockCategories.
+func insertCategory(ctx context.Context, tx *sql.Tx, category *model.Category) error {
+	var position, depth int
+	if category.ParentID != nil {
+		parent, err := findCategory(ctx, tx, *category.ParentID)
+		if err != nil {
+			return err
+		}
+		position = parent.Right
+		depth = parent.Depth + 1
+	} else {
+		end, err := categoriesEnd(ctx, tx)
+		if err != nil {
+			return err
+		}
+		position = end
+	}
+
+	if err := shiftCategories(ctx, tx, position, 2); err != nil {
+		return err
+	}
+
+	category.Left = position
+	category.Right = position + 1
+	category.Depth = depth
+
+	query := `
+        INSERT INTO categories
+        (name, parent_id, lft, rgt, depth)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		category.Name,
+		category.ParentID,
+		category.Left,
+		category.Right,
+		category.Depth,
+	).Scan(&category.ID)
+}
+
+// lockCategories serializes tree mutations while still allowing reads.
+func lockCategories(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`)
+	return err
+}
+
+// categoriesEnd returns the boundary right after the last top-level node.
+// Nodes detached by moveCategory carry negative boundaries and are ignored.
+func categoriesEnd(ctx context.Context, tx *sql.Tx) (int, error) {
+	var end int
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(rgt), 0) + 1 FROM categories WHERE lft > 0`).Scan(&end)
+	return end, err
+}
+
+// shiftCategories moves every boundary at or after from by delta.
+func shiftCategories(ctx context.Context, tx *sql.Tx, from, delta int) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE categories SET lft = lft + $2 WHERE lft >= $1`, from, delta); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `UPDATE categories SET rgt = rgt + $2 WHERE rgt >= $1`, from, delta)
+	return err
+}
+
+func moveCategory(ctx context.Context, tx *sql.Tx, id int, parentID *int, position int) error {
+	category, err := findCategory(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		parent, err := findCategory(ctx, tx, *parentID)
+		if err != nil {
+			return err
+		}
+		if parent.Left >= category.Left && parent.Right <= category.Right {
+			return model.ErrCategoryCycle
+		}
+	}
+
+	// Detach the subtree by negating its boundaries, then close the gap.
+	width := category.Right - category.Left + 1
+	query := `UPDATE categories SET lft = -lft, rgt = -rgt WHERE lft >= $1 AND rgt <= $2`
+	if _, err := tx.ExecContext(ctx, query, category.Left, category.Right); err != nil {
+		return err
+	}
+	if err := shiftCategories(ctx, tx, category.Right+1, -width); err != nil {
+		return err
+	}
+
+	var target, depth int
+	if parentID != nil {
+		parent, err := findCategory(ctx, tx, *parentID)
+		if err != nil {
+			return err
+		}
+		target = parent.Right
+		depth = parent.Depth + 1
+	} else {
+		if target, err = categoriesEnd(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	if position >= 0 {
+		siblings, err := childBoundaries(ctx, tx, parentID)
+		if err != nil {
+			return err
+		}
+		if position < len(siblings) {
+			target = siblings[position]
+		}
+	}
+
+	if err := shiftCategories(ctx, tx, target, width); err != nil {
+		return err
+	}
+
+	query = `
+        UPDATE categories
+        SET lft = -lft + $1, rgt = -rgt + $1, depth = depth + $2
+        WHERE lft < 0
+    `
+	if _, err := tx.ExecContext(ctx, query, target-category.Left, depth-category.Depth); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE categories SET parent_id = $1 WHERE id = $2`, parentID, id)
+	return err
+}
+
+// childBoundaries returns the left boundaries of the attached children of
+// parentID in tree order.
+func childBoundaries(ctx context.Context, tx *sql.Tx, parentID *int) ([]int, error) {
+	query := `SELECT lft FROM categories WHERE parent_id IS NULL AND lft > 0 ORDER BY lft`
+	args := []any{}
+	if parentID != nil {
+		query = `SELECT lft FROM categories WHERE parent_id = $1 AND lft > 0 ORDER BY lft`
+		args = append(args, *parentID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var boundaries []int
+	for rows.Next() {
+		var left int
+		if err := rows.Scan(&left); err != nil {
+			return nil, err
+		}
+		boundaries = append(boundaries, left)
+	}
+	return boundaries, rows.Err()
+}
+
+func scanCategoryCounts(rows *sql.Rows, err error) (map[int]int, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var categoryID, count int
+		if err := rows.Scan(&categoryID, &count); err != nil {
+			return nil, err
+		}
+		counts[categoryID] = count
+	}
+	return counts, rows.Err()
+}
+
+// categorySubtreeCondition builds a filter matching rows whose column points
+// at any of the categories passed as an array in $argIndex or at one of their
+// descendants, using the nested set boundaries.
+func categorySubtreeCondition(column string, argIndex int) string {
+	return fmt.Sprintf(`%s IN (
+        SELECT child.id
+        FROM categories child
+        JOIN categories parent ON child.lft >= parent.lft AND child.rgt <= parent.rgt
+        WHERE parent.id = ANY($%d)
+    )`, column, argIndex)
+}
@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/testdb"
+)
+
+// categoryTable answers the nested set statements of CategoryRepository
+// from memory.
+type categoryTable struct {
+	rows  map[int]*model.Category
+	inUse bool
+}
+
+func newCategoryTable(categories ...model.Category) *categoryTable {
+	table := &categoryTable{rows: map[int]*model.Category{}}
+	for i := range categories {
+		table.rows[categories[i].ID] = &categories[i]
+	}
+	return table
+}
+
+func (t *categoryTable) handle(query string, args []driver.Value) (*testdb.Result, error) {
+	arg := func(i int) int { return int(args[i].(int64)) }
+	optional := func(i int) *int {
+		if args[i] == nil {
+			return nil
+		}
+		id := arg(i)
+		return &id
+	}
+	update := func(match func(c *model.Category) bool, apply func(c *model.Category)) (*testdb.Result, error) {
+		var affected int64
+		for _, c := range t.rows {
+			if match(c) {
+				apply(c)
+				affected++
+			}
+		}
+		return &testdb.Result{RowsAffected: affected}, nil
+	}
+
+	switch query {
+	case `LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`:
+		return &testdb.Result{}, nil
+	case `SELECT id, name, parent_id, lft, rgt, depth FROM categories WHERE id = $1`:
+		result := &testdb.Result{Columns: []string{"id", "name", "parent_id", "lft", "rgt", "depth"}}
+		if c, ok := t.rows[arg(0)]; ok {
+			var parentID driver.Value
+			if c.ParentID != nil {
+				parentID = int64(*c.ParentID)
+			}
+			result.Rows = append(result.Rows, []driver.Value{int64(c.ID), c.Name, parentID, int64(c.Left), int64(c.Right), int64(c.Depth)})
+		}
+		return result, nil
+	case `SELECT COALESCE(MAX(rgt), 0) + 1 FROM categories WHERE lft > 0`:
+		end := 0
+		for _, c := range t.rows {
+			if c.Left > 0 && c.Right > end {
+				end = c.Right
+			}
+		}
+		return &testdb.Result{Columns: []string{"end"}, Rows: [][]driver.Value{{int64(end + 1)}}}, nil
+	case `SELECT lft FROM categories WHERE parent_id IS NULL AND lft > 0 ORDER BY lft`,
+		`SELECT lft FROM categories WHERE parent_id = $1 AND lft > 0 ORDER BY lft`:
+		var lefts []int
+		for _, c := range t.rows {
+			if c.Left > 0 && (len(args) == 0 && c.ParentID == nil || len(args) == 1 && c.ParentID != nil && *c.ParentID == arg(0)) {
+				lefts = append(lefts, c.Left)
+			}
+		}
+		sort.Ints(lefts)
+		result := &testdb.Result{Columns: []string{"lft"}}
+		for _, left := range lefts {
+			result.Rows = append(result.Rows, []driver.Value{int64(left)})
+		}
+		return result, nil
+	case `UPDATE categories SET lft = -lft, rgt = -rgt WHERE lft >= $1 AND rgt <= $2`:
+		return update(func(c *model.Category) bool { return c.Left >= arg(0) && c.Right <= arg(1) },
+			func(c *model.Category) { c.Left, c.Right = -c.Left, -c.Right })
+	case `UPDATE categories SET lft = lft + $2 WHERE lft >= $1`:
+		return update(func(c *model.Category) bool { return c.Left >= arg(0) },
+			func(c *model.Category) { c.Left += arg(1) })
+	case `UPDATE categories SET rgt = rgt + $2 WHERE rgt >= $1`:
+		return update(func(c *model.Category) bool { return c.Right >= arg(0) },
+			func(c *model.Category) { c.Right += arg(1) })
+	case `UPDATE categories SET lft = -lft + $1, rgt = -rgt + $1, depth = depth + $2 WHERE lft < 0`:
+		return update(func(c *model.Category) bool { return c.Left < 0 },
+			func(c *model.Category) { c.Left, c.Right, c.Depth = -c.Left+arg(0), -c.Right+arg(0), c.Depth+arg(1) })
+	case `UPDATE categories SET name = $1 WHERE id = $2`:
+		name := args[0].(string)
+		return update(func(c *model.Category) bool { return c.ID == arg(1) },
+			func(c *model.Category) { c.Name = name })
+	case `UPDATE categories SET parent_id = $1 WHERE id = $2`:
+		parentID := optional(0)
+		return update(func(c *model.Category) bool { return c.ID == arg(1) },
+			func(c *model.Category) { c.ParentID = parentID })
+	case `UPDATE categories SET parent_id = $1 WHERE parent_id = $2`:
+		parentID := optional(0)
+		return update(func(c *model.Category) bool { return c.ParentID != nil && *c.ParentID == arg(1) },
+			func(c *model.Category) { c.ParentID = parentID })
+	case `UPDATE categories SET lft = lft - 1, rgt = rgt - 1, depth = depth - 1 WHERE lft > $1 AND rgt < $2`:
+		return update(func(c *model.Category) bool { return c.Left > arg(0) && c.Right < arg(1) },
+			func(c *model.Category) { c.Left, c.Right, c.Depth = c.Left-1, c.Right-1, c.Depth-1 })
+	case `INSERT INTO categories (name, parent_id, lft, rgt, depth) VALUES ($1, $2, $3, $4, $5) RETURNING id`:
+		id := len(t.rows) + 1
+		for t.rows[id] != nil {
+			id++
+		}
+		t.rows[id] = &model.Category{ID: id, Name: args[0].(string), ParentID: optional(1), Left: arg(2), Right: arg(3), Depth: arg(4)}
+		return &testdb.Result{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(id)}}}, nil
+	case `DELETE FROM categories WHERE lft >= $1 AND rgt <= $2`:
+		return t.delete(func(c *model.Category) bool { return c.Left >= arg(0) && c.Right <= arg(1) })
+	case `DELETE FROM categories WHERE id = $1`:
+		return t.delete(func(c *model.Category) bool { return c.ID == arg(0) })
+	}
+	if len(query) > 30 && query[:30] == `SELECT EXISTS (SELECT 1 FROM v` {
+		return &testdb.Result{Columns: []string{"exists"}, Rows: [][]driver.Value{{t.inUse}}}, nil
+	}
+	return nil, nil
+}
+
+func (t *categoryTable) delete(match func(c *model.Category) bool) (*testdb.Result, error) {
+	var affected int64
+	for id, c := range t.rows {
+		if match(c) {
+			delete(t.rows, id)
+			affected++
+		}
+	}
+	return &testdb.Result{RowsAffected: affected}, nil
+}
+
+// category is a row of the expected tree: id, parent (0 for the top level),
+// lft, rgt and depth.
+type category [5]int
+
+func (t *categoryTable) assert(tb testing.TB, want ...category) {
+	tb.Helper()
+	if len(t.rows) != len(want) {
+		tb.Fatalf("got %d categories, want %d", len(t.rows), len(want))
+	}
+	for _, w := range want {
+		c, ok := t.rows[w[0]]
+		if !ok {
+			tb.Fatalf("category %d is missing", w[0])
+		}
+		parent := 0
+		if c.ParentID != nil {
+			parent = *c.ParentID
+		}
+		if got := (category{c.ID, parent, c.Left, c.Right, c.Depth}); got != w {
+			tb.Errorf("category %d is %v, want %v", w[0], got, w)
+		}
+	}
+}
+
+func intPtr(i int) *int { return &i }
+
+// testCategories is the tree
+//
+//	1
+//	├── 2
+//	│   └── 3
+//	└── 4
+//	5
+//	└── 6
+func testCategories() *categoryTable {
+	return newCategoryTable(
+		model.Category{ID: 1, Left: 1, Right: 8},
+		model.Category{ID: 2, ParentID: intPtr(1), Left: 2, Right: 5, Depth: 1},
+		model.Category{ID: 3, ParentID: intPtr(2), Left: 3, Right: 4, Depth: 2},
+		model.Category{ID: 4, ParentID: intPtr(1), Left: 6, Right: 7, Depth: 1},
+		model.Category{ID: 5, Left: 9, Right: 12},
+		model.Category{ID: 6, ParentID: intPtr(5), Left: 10, Right: 11, Depth: 1},
+	)
+}
+
+func TestCategoryRepositoryMove(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int
+		parentID *int
+		position int
+		want     []category
+	}{
+		{
+			name: "subtree to the end of another parent",
+			id:   2, parentID: intPtr(5), position: -1,
+			want: []category{{1, 0, 1, 4, 0}, {4, 1, 2, 3, 1}, {5, 0, 5, 12, 0}, {6, 5, 6, 7, 1}, {2, 5, 8, 11, 1}, {3, 2, 9, 10, 2}},
+		},
+		{
+			name: "subtree before the first child of another parent",
+			id:   2, parentID: intPtr(5), position: 0,
+			want: []category{{1, 0, 1, 4, 0}, {4, 1, 2, 3, 1}, {5, 0, 5, 12, 0}, {2, 5, 6, 9, 1}, {3, 2, 7, 8, 2}, {6, 5, 10, 11, 1}},
+		},
+		{
+			name: "leaf to the top level first",
+			id:   4, position: 0,
+			want: []category{{4, 0, 1, 2, 0}, {1, 0, 3, 8, 0}, {2, 1, 4, 7, 1}, {3, 2, 5, 6, 2}, {5, 0, 9, 12, 0}, {6, 5, 10, 11, 1}},
+		},
+		{
+			name: "top level subtree one level down",
+			id:   5, parentID: intPtr(4), position: -1,
+			want: []category{{1, 0, 1, 12, 0}, {2, 1, 2, 5, 1}, {3, 2, 3, 4, 2}, {4, 1, 6, 11, 1}, {5, 4, 7, 10, 2}, {6, 5, 8, 9, 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := testCategories()
+			repo := NewCategoryRepository(testdb.Open(t, table.handle).DB)
+			if err := repo.Move(context.Background(), tt.id, tt.parentID, tt.position); err != nil {
+				t.Fatal(err)
+			}
+			table.assert(t, tt.want...)
+		})
+	}
+}
+
+func TestCategoryRepositoryMoveIntoOwnSubtree(t *testing.T) {
+	table := testCategories()
+	repo := NewCategoryRepository(testdb.Open(t, table.handle).DB)
+
+	err := repo.Move(context.Background(), 1, intPtr(3), -1)
+	if !errors.Is(err, model.ErrCategoryCycle) {
+		t.Fatalf("got %v, want %v", err, model.ErrCategoryCycle)
+	}
+}
+
+func TestCategoryRepositoryReorder(t *testing.T) {
+	table := testCategories()
+	repo := NewCategoryRepository(testdb.Open(t, table.handle).DB)
+
+	if err := repo.Reorder(context.Background(), intPtr(1), []int{4, 2}); err != nil {
+		t.Fatal(err)
+	}
+	table.assert(t, category{1, 0, 1, 8, 0}, category{4, 1, 2, 3, 1}, category{2, 1, 4, 7, 1}, category{3, 2, 5, 6, 2},
+		category{5, 0, 9, 12, 0}, category{6, 5, 10, 11, 1})
+
+	err := repo.Reorder(context.Background(), intPtr(1), []int{6})
+	if !errors.Is(err, model.ErrCategoryNotSibling) {
+		t.Fatalf("got %v, want %v", err, model.ErrCategoryNotSibling)
+	}
+}
+
+func TestCategoryRepositoryDeleteInUse(t *testing.T) {
+	table := testCategories()
+	table.inUse = true
+	repo := NewCategoryRepository(testdb.Open(t, table.handle).DB)
+
+	if err := repo.Delete(context.Background(), 2); !errors.Is(err, model.ErrCategoryInUse) {
+		t.Fatalf("got %v, want %v", err, model.ErrCategoryInUse)
+	}
+	if err := repo.DeleteAndReparent(context.Background(), 2); !errors.Is(err, model.ErrCategoryInUse) {
+		t.Fatalf("got %v, want %v", err, model.ErrCategoryInUse)
+	}
+	table.assert(t, category{1, 0, 1, 8, 0}, category{2, 1, 2, 5, 1}, category{3, 2, 3, 4, 2}, category{4, 1, 6, 7, 1},
+		category{5, 0, 9, 12, 0}, category{6, 5, 10, 11, 1})
+}
+
+func TestCategoryRepositoryUpdateAndMove(t *testing.T) {
+	table := testCategories()
+	repo := NewCategoryRepository(testdb.Open(t, table.handle).DB)
+
+	if err := repo.UpdateAndMove(context.Background(), &model.Category{ID: 3, Name: "Renamed"}, intPtr(2)); err != nil {
+		t.Fatal(err)
+	}
+	if name := table.rows[3].Name; name != "Renamed" {
+		t.Fatalf("name %q, want %q", name, "Renamed")
+	}
+	table.assert(t, category{1, 0, 1, 8, 0}, category{2, 1, 2, 5, 1}, category{3, 2, 3, 4, 2}, category{4, 1, 6, 7, 1},
+		category{5, 0, 9, 12, 0}, category{6, 5, 10, 11, 1})
+
+	if err := repo.UpdateAndMove(context.Background(), &model.Category{ID: 3, Name: "Renamed"}, intPtr(5)); err != nil {
+		t.Fatal(err)
+	}
+	table.assert(t, category{1, 0, 1, 6, 0}, category{2, 1, 2, 3, 1}, category{4, 1, 4, 5, 1},
+		category{5, 0, 7, 12, 0}, category{6, 5, 8, 9, 1}, category{3, 5, 10, 11, 1})
+}
+
+func TestCategoryRepositoryInsert(t *testing.T) {
+	table := testCategories()
+	repo := NewCategoryRepository(testdb.Open(t, table.handle).DB)
+
+	child := &model.Category{Name: "Child", ParentID: intPtr(2)}
+	if err := repo.Insert(context.Background(), child); err != nil {
+		t.Fatal(err)
+	}
+	root := &model.Category{Name: "Root"}
+	if err := repo.Insert(context.Background(), root); err != nil {
+		t.Fatal(err)
+	}
+	if child.ID != 7 || root.ID != 8 {
+		t.Fatalf("inserted ids %d and %d, want 7 and 8", child.ID, root.ID)
+	}
+	table.assert(t, category{1, 0, 1, 10, 0}, category{2, 1, 2, 7, 1}, category{3, 2, 3, 4, 2}, category{7, 2, 5, 6, 2},
+		category{4, 1, 8, 9, 1}, category{5, 0, 11, 14, 0}, category{6, 5, 12, 13, 1}, category{8, 0, 15, 16, 0})
+}
+
+func TestCategoryRepositoryDelete(t *testing.T) {
+	table := testCategories()
+	repo := NewCategoryRepository(testdb.Open(t, table.handle).DB)
+
+	if err := repo.Delete(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	table.assert(t, category{1, 0, 1, 4, 0}, category{4, 1, 2, 3, 1}, category{5, 0, 5, 8, 0}, category{6, 5, 6, 7, 1})
+}
+
+func TestCategoryRepositoryDeleteAndReparent(t *testing.T) {
+	table := testCategories()
+	repo := NewCategoryRepository(testdb.Open(t, table.handle).DB)
+
+	if err := repo.DeleteAndReparent(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	table.assert(t, category{1, 0, 1, 6, 0}, category{3, 1, 2, 3, 1}, category{4, 1, 4, 5, 1},
+		category{5, 0, 7, 10, 0}, category{6, 5, 8, 9, 1})
+
+	if err := repo.DeleteAndReparent(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	table.assert(t, category{3, 0, 1, 2, 0}, category{4, 0, 3, 4, 0}, category{5, 0, 5, 8, 0}, category{6, 5, 6, 7, 1})
+}
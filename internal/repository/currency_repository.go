@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+type CurrencyRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewCurrencyRepository(log *slog.Logger, db *sql.DB) *CurrencyRepository {
+	return &CurrencyRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+func (r *CurrencyRepository) FindAll(ctx context.Context) ([]model.Currency, error) {
+	query := `SELECT code, name, rate, updated_at FROM currencies ORDER BY code`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []model.Currency
+	for rows.Next() {
+		var c model.Currency
+		if err := rows.Scan(&c.Code, &c.Name, &c.Rate, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, c)
+	}
+	return currencies, rows.Err()
+}
+
+// FindByCode returns nil without an error when the currency is unknown.
+func (r *CurrencyRepository) FindByCode(ctx context.Context, code string) (*model.Currency, error) {
+	query := `SELECT code, name, rate, updated_at FROM currencies WHERE code = $1`
+	var c model.Currency
+	err := r.db.QueryRowContext(ctx, query, code).Scan(&c.Code, &c.Name, &c.Rate, &c.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Upsert stores the currency and recomputes the normalized salaries of every
+// vacancy paid in it, in one transaction.
+func (r *CurrencyRepository) Upsert(ctx context.Context, currency *model.Currency) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		query := `INSERT INTO currencies (code, name, rate) VALUES ($1, $2, $3)
+				ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name, rate = EXCLUDED.rate, updated_at = NOW()
+				RETURNING updated_at`
+		if err := tx.QueryRowContext(ctx, query, currency.Code, currency.Name, currency.Rate).Scan(&currency.UpdatedAt); err != nil {
+			return err
+		}
+
+		query = `UPDATE vacancies SET ` + salaryBaseAssignments + ` WHERE salary_currency = $1`
+		_, err := tx.ExecContext(ctx, query, currency.Code)
+		return err
+	})
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/lib/pq"
+)
+
+type FavoriteRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewFavoriteRepository(log *slog.Logger, db *sql.DB) *FavoriteRepository {
+	return &FavoriteRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+// Add bookmarks the vacancy for the user. Adding it twice is a no-op.
+func (r *FavoriteRepository) Add(ctx context.Context, userID, vacancyID int64) error {
+	query := `INSERT INTO vacancy_favorites (user_id, vacancy_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	_, err := r.db.ExecContext(ctx, query, userID, vacancyID)
+	return err
+}
+
+func (r *FavoriteRepository) Remove(ctx context.Context, userID, vacancyID int64) error {
+	query := `DELETE FROM vacancy_favorites WHERE user_id = $1 AND vacancy_id = $2`
+	_, err := r.db.ExecContext(ctx, query, userID, vacancyID)
+	return err
+}
+
+// FindFavorited returns which of vacancyIDs the user has bookmarked.
+func (r *FavoriteRepository) FindFavorited(ctx context.Context, userID int64, vacancyIDs []int64) (map[int64]bool, error) {
+	favorited := map[int64]bool{}
+	if userID == 0 || len(vacancyIDs) == 0 {
+		return favorited, nil
+	}
+
+	query := `SELECT vacancy_id FROM vacancy_favorites WHERE user_id = $1 AND vacancy_id = ANY($2)`
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(vacancyIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		favorited[id] = true
+	}
+	return favorited, rows.Err()
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+type LocationRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewLocationRepository(log *slog.Logger, db *sql.DB) *LocationRepository {
+	return &LocationRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+// Seed upserts the reference dataset in one transaction and then links
+// vacancies that only have a free-text country to the matching country.
+// Running it again is a no-op apart from refreshed names and coordinates.
+func (r *LocationRepository) Seed(ctx context.Context, countries []model.CountrySeed) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, country := range countries {
+			query := `INSERT INTO countries (code, name) VALUES ($1, $2)
+					ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name`
+			if _, err := tx.ExecContext(ctx, query, country.Code, country.Name); err != nil {
+				return err
+			}
+
+			for _, region := range country.Regions {
+				var regionID int
+				query := `INSERT INTO regions (country_code, name) VALUES ($1, $2)
+						ON CONFLICT (country_code, name) DO UPDATE SET name = EXCLUDED.name
+						RETURNING id`
+				if err := tx.QueryRowContext(ctx, query, country.Code, region.Name).Scan(&regionID); err != nil {
+					return err
+				}
+
+				for _, city := range region.Cities {
+					query := `INSERT INTO cities (region_id, name, latitude, longitude) VALUES ($1, $2, $3, $4)
+							ON CONFLICT (region_id, name) DO UPDATE SET latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude`
+					if _, err := tx.ExecContext(ctx, query, regionID, city.Name, city.Latitude, city.Longitude); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		query := `UPDATE vacancies v SET country_code = c.code
+				FROM countries c
+				WHERE v.country_code IS NULL AND v.country IS NOT NULL
+					AND (LOWER(TRIM(v.country)) = LOWER(c.name) OR UPPER(TRIM(v.country)) = c.code)`
+		_, err := tx.ExecContext(ctx, query)
+		return err
+	})
+}
+
+func (r *LocationRepository) FindCountries(ctx context.Context) ([]model.Country, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT code, name FROM countries ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var countries []model.Country
+	for rows.Next() {
+		var c model.Country
+		if err := rows.Scan(&c.Code, &c.Name); err != nil {
+			return nil, err
+		}
+		countries = append(countries, c)
+	}
+	return countries, rows.Err()
+}
+
+func (r *LocationRepository) FindRegions(ctx context.Context) ([]model.Region, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, country_code, name FROM regions ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var regions []model.Region
+	for rows.Next() {
+		var region model.Region
+		if err := rows.Scan(&region.ID, &region.CountryCode, &region.Name); err != nil {
+			return nil, err
+		}
+		regions = append(regions, region)
+	}
+	return regions, rows.Err()
+}
+
+func (r *LocationRepository) FindCities(ctx context.Context) ([]model.City, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, region_id, name, latitude, longitude FROM cities ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cities []model.City
+	for rows.Next() {
+		var city model.City
+		if err := rows.Scan(&city.ID, &city.RegionID, &city.Name, &city.Latitude, &city.Longitude); err != nil {
+			return nil, err
+		}
+		cities = append(cities, city)
+	}
+	return cities, rows.Err()
+}
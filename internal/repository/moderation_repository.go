@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
+)
+
+type ModerationRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewModerationRepository(log *slog.Logger, db *sql.DB) *ModerationRepository {
+	return &ModerationRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+// SalaryStats returns the normalized monthly salary of the given amount and
+// the median normalized salary of published vacancies in the category with
+// the number of vacancies it was computed from.
+func (r *ModerationRepository) SalaryStats(ctx context.Context, amount float64, salaryType, currency string, categoryID int64) (*float64, float64, int, error) {
+	query := `SELECT monthly_salary_base($1, $2, $3),
+				COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY ` + salarySortExpression + `), 0),
+				COUNT(` + salarySortExpression + `)
+			FROM vacancies
+			WHERE category_id = $4 AND deleted_at IS NULL AND ` + publishedVacancyCondition
+	var base *float64
+	var median float64
+	var samples int
+	err := r.db.QueryRowContext(ctx, query, amount, salaryType, currency, categoryID).Scan(&base, &median, &samples)
+	return base, median, samples, err
+}
+
+// Record stores the risk score of the vacancy and adds the entry to its
+// moderation log. With to set the vacancy is moved from status from to it
+// in the same transaction; false is returned when its status was no longer
+// from. publishedAt and expiresAt are only written together with a status.
+func (r *ModerationRepository) Record(ctx context.Context, entry *model.ModerationLogEntry, from, to string, publishedAt, expiresAt *time.Time) (bool, error) {
+	var changed bool
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		changed, err = recordModeration(ctx, tx, entry, from, to, publishedAt, expiresAt)
+		return err
+	})
+	return changed, err
+}
+
+// recordModeration is Record within tx. When the status was no longer from
+// nothing is written.
+func recordModeration(ctx context.Context, tx *sql.Tx, entry *model.ModerationLogEntry, from, to string, publishedAt, expiresAt *time.Time) (bool, error) {
+	flags, err := json.Marshal(entry.Flags)
+	if err != nil {
+		return false, err
+	}
+	if entry.Flags == nil {
+		flags = []byte("[]")
+	}
+
+	if to != "" {
+		query := `UPDATE vacancies
+				SET status = $3, published_at = COALESCE($4, published_at), expires_at = COALESCE($5, expires_at), expiry_warned_at = NULL, updated_at = NOW()
+				WHERE id = $1 AND status = $2 AND deleted_at IS NULL`
+		result, err := tx.ExecContext(ctx, query, entry.VacancyID, from, to, publishedAt, expiresAt)
+		if err != nil {
+			return false, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return false, err
+		}
+		if affected == 0 {
+			return false, nil
+		}
+	}
+	if entry.ModeratorID == nil {
+		if _, err := tx.ExecContext(ctx, `UPDATE vacancies SET risk_score = $2 WHERE id = $1`, entry.VacancyID, entry.RiskScore); err != nil {
+			return false, err
+		}
+	}
+
+	query := `INSERT INTO vacancy_moderation_log (vacancy_id, moderator_id, action, reason, risk_score, flags)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at`
+	err = tx.QueryRowContext(ctx, query, entry.VacancyID, entry.ModeratorID, entry.Action, entry.Reason, entry.RiskScore, string(flags)).
+		Scan(&entry.ID, &entry.CreatedAt)
+	return err == nil, err
+}
+
+// SetRiskScore stores the score of an assessment that did not flag the
+// vacancy.
+func (r *ModerationRepository) SetRiskScore(ctx context.Context, vacancyID int64, score int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE vacancies SET risk_score = $2 WHERE id = $1`, vacancyID, score)
+	return err
+}
+
+func (r *ModerationRepository) ListLog(ctx context.Context, vacancyID int64) ([]model.ModerationLogEntry, error) {
+	query := `SELECT id, vacancy_id, moderator_id, action, reason, risk_score, flags, created_at
+			FROM vacancy_moderation_log WHERE vacancy_id = $1
+			ORDER BY created_at, id`
+	rows, err := r.db.QueryContext(ctx, query, vacancyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.ModerationLogEntry
+	for rows.Next() {
+		e, err := scanModerationLogEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+// LatestAssessments returns the latest automatic assessment logged for each
+// of the given vacancies, keyed by vacancy ID, in one query. Vacancies never
+// assessed are missing from the map.
+func (r *ModerationRepository) LatestAssessments(ctx context.Context, vacancyIDs []int64) (map[int64]model.ModerationLogEntry, error) {
+	result := make(map[int64]model.ModerationLogEntry, len(vacancyIDs))
+	if len(vacancyIDs) == 0 {
+		return result, nil
+	}
+	query := `SELECT DISTINCT ON (vacancy_id) id, vacancy_id, moderator_id, action, reason, risk_score, flags, created_at
+			FROM vacancy_moderation_log WHERE vacancy_id = ANY($1) AND moderator_id IS NULL
+			ORDER BY vacancy_id, created_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(vacancyIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanModerationLogEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[e.VacancyID] = *e
+	}
+	return result, rows.Err()
+}
+
+func scanModerationLogEntry(row rowScanner) (*model.ModerationLogEntry, error) {
+	var e model.ModerationLogEntry
+	var flags []byte
+	if err := row.Scan(&e.ID, &e.VacancyID, &e.ModeratorID, &e.Action, &e.Reason, &e.RiskScore, &flags, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(flags, &e.Flags); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// ListQueue returns vacancies waiting for a moderator, riskiest first.
+func (r *ModerationRepository) ListQueue(ctx context.Context, limit, offset int) ([]model.Vacancy, int, error) {
+	query := `SELECT ` + vacancyColumns + ` FROM vacancies
+			WHERE status = 'pending_moderation' AND deleted_at IS NULL
+			ORDER BY risk_score DESC, created_at, id
+			LIMIT $1 OFFSET $2`
+	vacancies, err := scanVacancies(r.db.QueryContext(ctx, query, limit, offset))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM vacancies WHERE status = 'pending_moderation' AND deleted_at IS NULL`).Scan(&total)
+	return vacancies, total, err
+}
+
+func (r *ModerationRepository) ListBlacklist(ctx context.Context) ([]model.BlacklistEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, kind, value, reason, created_at FROM moderation_blacklist ORDER BY kind, value`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.BlacklistEntry
+	for rows.Next() {
+		var e model.BlacklistEntry
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Value, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// AddBlacklistEntry stores the entry. Adding an existing kind and value
+// updates its reason.
+func (r *ModerationRepository) AddBlacklistEntry(ctx context.Context, entry *model.BlacklistEntry) error {
+	query := `INSERT INTO moderation_blacklist (kind, value, reason) VALUES ($1, $2, $3)
+			ON CONFLICT (kind, value) DO UPDATE SET reason = EXCLUDED.reason
+			RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query, entry.Kind, entry.Value, entry.Reason).Scan(&entry.ID, &entry.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23514" {
+		return model.ErrInvalidBlacklistEntry
+	}
+	return err
+}
+
+// DeleteBlacklistEntry removes the entry and reports whether it existed.
+func (r *ModerationRepository) DeleteBlacklistEntry(ctx context.Context, id int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM moderation_blacklist WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+type NotificationRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewNotificationRepository(log *slog.Logger, db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *model.Notification) error {
+	query := `INSERT INTO notifications (user_id, type, title, body, payload)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	return r.db.QueryRowContext(ctx, query, notification.UserID, notification.Type, notification.Title, notification.Body, nullableJSON(notification.Payload)).
+		Scan(&notification.ID, &notification.CreatedAt)
+}
+
+// CreateForOrganization stores a copy of the notification for every user of
+// the organization and returns how many were created.
+func (r *NotificationRepository) CreateForOrganization(ctx context.Context, organizationID int64, notification *model.Notification) (int64, error) {
+	query := `INSERT INTO notifications (user_id, type, title, body, payload)
+			SELECT id, $2, $3, $4, $5 FROM users WHERE organization_id = $1`
+	result, err := r.db.ExecContext(ctx, query, organizationID, notification.Type, notification.Title, notification.Body, nullableJSON(notification.Payload))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]model.Notification, error) {
+	query := `SELECT id, user_id, type, title, COALESCE(body, ''), payload, read_at, created_at
+			FROM notifications
+			WHERE user_id = $1 AND ($2 = FALSE OR read_at IS NULL)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $3 OFFSET $4`
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []model.Notification
+	for rows.Next() {
+		var n model.Notification
+		var payload []byte
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &payload, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.Payload = payload
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// MarkRead marks the notification as read and reports whether it belonged to
+// the user.
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, userID int64) (bool, error) {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func nullableJSON(payload []byte) any {
+	if len(payload) == 0 {
+		return nil
+	}
+	return string(payload)
+}
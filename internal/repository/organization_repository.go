@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log/slog"
 
 	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
 )
 
 type OrganizationRepository struct {
@@ -33,9 +35,9 @@ func (r *OrganizationRepository) CreateOrganization(org *model.Organization) err
 }
 
 func (r *OrganizationRepository) GetOrganization(id int) (*model.Organization, error) {
-	query := "SELECT id, name, description FROM organizations WHERE id = $1"
+	query := "SELECT id, name, description, verified FROM organizations WHERE id = $1"
 	org := &model.Organization{}
-	err := r.db.QueryRow(query, id).Scan(&org.Id, &org.Name, &org.Description)
+	err := r.db.QueryRow(query, id).Scan(&org.Id, &org.Name, &org.Description, &org.Verified)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			r.log.Warn("Organization not found", slog.Int("id", id))
@@ -50,7 +52,7 @@ func (r *OrganizationRepository) GetOrganization(id int) (*model.Organization, e
 }
 
 func (r *OrganizationRepository) GetAllOrganizations() ([]*model.Organization, error) {
-	query := "SELECT id, name, description FROM organizations"
+	query := "SELECT id, name, description, verified FROM organizations"
 	rows, err := r.db.Query(query)
 	if err != nil {
 		r.log.Error("Failed to retrieve organizations", slog.Any("error", err))
@@ -61,7 +63,7 @@ func (r *OrganizationRepository) GetAllOrganizations() ([]*model.Organization, e
 	var organizations []*model.Organization
 	for rows.Next() {
 		org := &model.Organization{}
-		if err := rows.Scan(&org.Id, &org.Name, &org.Description); err != nil {
+		if err := rows.Scan(&org.Id, &org.Name, &org.Description, &org.Verified); err != nil {
 			r.log.Error("Failed to scan organization row", slog.Any("error", err))
 			continue
 		}
@@ -72,3 +74,73 @@ func (r *OrganizationRepository) GetAllOrganizations() ([]*model.Organization, e
 	return organizations, nil
 }
 
+// FindByIDs returns the given organizations keyed by ID in one query.
+// Missing organizations are left out.
+func (r *OrganizationRepository) FindByIDs(ctx context.Context, ids []int) (map[int]model.Organization, error) {
+	orgs := make(map[int]model.Organization, len(ids))
+	if len(ids) == 0 {
+		return orgs, nil
+	}
+	query := `SELECT id, name, description, verified FROM organizations WHERE id = ANY($1)`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var org model.Organization
+		if err := rows.Scan(&org.Id, &org.Name, &org.Description, &org.Verified); err != nil {
+			return nil, err
+		}
+		orgs[org.Id] = org
+	}
+	return orgs, rows.Err()
+}
+
+// SetVerified marks the organization as verified or not. It reports whether
+// the organization exists.
+func (r *OrganizationRepository) SetVerified(ctx context.Context, id int, verified bool) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE organizations SET verified = $2 WHERE id = $1`, id, verified)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Hide excludes the organization's vacancies from the user's searches.
+func (r *OrganizationRepository) Hide(ctx context.Context, userID int64, organizationID int) error {
+	query := `INSERT INTO hidden_organizations (user_id, organization_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	_, err := r.db.ExecContext(ctx, query, userID, organizationID)
+	return err
+}
+
+func (r *OrganizationRepository) Unhide(ctx context.Context, userID int64, organizationID int) error {
+	query := `DELETE FROM hidden_organizations WHERE user_id = $1 AND organization_id = $2`
+	_, err := r.db.ExecContext(ctx, query, userID, organizationID)
+	return err
+}
+
+func (r *OrganizationRepository) ListHidden(ctx context.Context, userID int64) ([]model.Organization, error) {
+	query := `SELECT o.id, o.name, o.description, o.verified
+			FROM hidden_organizations h
+			JOIN organizations o ON o.id = h.organization_id
+			WHERE h.user_id = $1
+			ORDER BY h.created_at DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orgs []model.Organization
+	for rows.Next() {
+		var org model.Organization
+		if err := rows.Scan(&org.Id, &org.Name, &org.Description, &org.Verified); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 
 	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
 )
 
 type ResumeExperienceRepository struct {
@@ -212,3 +213,99 @@ func (r *ResumeExperienceRepository) ListResumeExperiencesByResumeID(ctx context
 
 	return experiences, nil
 }
+
+// ListResumeExperiencesByResumeIDs returns the experiences of every given
+// resume, keyed by resume ID, in one query.
+func (r *ResumeExperienceRepository) ListResumeExperiencesByResumeIDs(ctx context.Context, resumeIDs []int) (map[int][]*model.ResumeExperience, error) {
+	experiences := make(map[int][]*model.ResumeExperience, len(resumeIDs))
+	if len(resumeIDs) == 0 {
+		return experiences, nil
+	}
+	query := `
+		SELECT id, resume_id, oraganization_name, category_id, description, 
+		       start_month, start_year, end_month, end_year
+		FROM resume_experiences
+		WHERE resume_id = ANY($1)
+		ORDER BY start_year DESC, start_month DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(resumeIDs))
+	if err != nil {
+		r.log.Error("error querying resume experiences", "error", err)
+		return nil, fmt.Errorf("failed to query resume experiences: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var exp model.ResumeExperience
+		if err := rows.Scan(
+			&exp.Id,
+			&exp.ResumeId,
+			&exp.OrganizationName,
+			&exp.CategoryId,
+			&exp.Description,
+			&exp.StartMonth,
+			&exp.StartYear,
+			&exp.EndMonth,
+			&exp.EndYear,
+		); err != nil {
+			r.log.Error("error scanning resume experience row", "error", err)
+			return nil, fmt.Errorf("failed to scan resume experience: %w", err)
+		}
+		experiences[exp.ResumeId] = append(experiences[exp.ResumeId], &exp)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.log.Error("error iterating resume experience rows", "error", err)
+		return nil, fmt.Errorf("error iterating resume experiences: %w", err)
+	}
+
+	return experiences, nil
+}
+
+func insertResumeExperience(ctx context.Context, tx *sql.Tx, experience *model.ResumeExperience) error {
+	query := `
+		INSERT INTO resume_experiences (
+			resume_id, oraganization_name, category_id, description,
+			start_month, start_year, end_month, end_year
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	return tx.QueryRowContext(ctx, query,
+		experience.ResumeId,
+		experience.OrganizationName,
+		experience.CategoryId,
+		experience.Description,
+		experience.StartMonth,
+		experience.StartYear,
+		experience.EndMonth,
+		experience.EndYear,
+	).Scan(&experience.Id)
+}
+
+// updateResumeExperience overwrites the experience. It does not move it to
+// another resume.
+func updateResumeExperience(ctx context.Context, tx *sql.Tx, experience *model.ResumeExperience) error {
+	query := `
+		UPDATE resume_experiences
+		SET oraganization_name = $1,
+		    category_id = $2,
+		    description = $3,
+		    start_month = $4,
+		    start_year = $5,
+		    end_month = $6,
+		    end_year = $7
+		WHERE id = $8
+	`
+	_, err := tx.ExecContext(ctx, query,
+		experience.OrganizationName,
+		experience.CategoryId,
+		experience.Description,
+		experience.StartMonth,
+		experience.StartYear,
+		experience.EndMonth,
+		experience.EndYear,
+		experience.Id,
+	)
+	return err
+}
@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log/slog"
 	"fmt"
 
 	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
 )
 
 type ResumeRepository struct {
@@ -21,6 +23,8 @@ func NewResumeRepository(log *slog.Logger, db *sql.DB) *ResumeRepository {
 	}
 }
 
+// CreateResume stores the resume together with its skills and experiences
+// in one transaction, so either all of them are stored or none.
 func (r *ResumeRepository) CreateResume(
     ctx context.Context, 
     resume model.Resume,
@@ -39,16 +43,34 @@ func (r *ResumeRepository) CreateResume(
         RETURNING id, created_at
     `
 
-	row := r.db.QueryRowContext(
-        ctx, query, 
-        resume.UserId, 
-        resume.CategoryId, 
-        resume.Description, 
-        resume.SalaryFrom, 
-        resume.SalaryTo, 
-        resume.SalaryPeriod,
-    )
-	err := row.Scan(&resume.Id, &resume.CreatedAt)
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(
+			ctx, query,
+			resume.UserId,
+			resume.CategoryId,
+			resume.Description,
+			resume.SalaryFrom,
+			resume.SalaryTo,
+			resume.SalaryPeriod,
+		)
+		if err := row.Scan(&resume.Id, &resume.CreatedAt); err != nil {
+			return err
+		}
+
+		for _, skill := range resume.Skills {
+			skill.ResumeId = resume.Id
+			if err := insertResumeSkill(ctx, tx, skill); err != nil {
+				return err
+			}
+		}
+		for _, experience := range resume.Experiences {
+			experience.ResumeId = resume.Id
+			if err := insertResumeExperience(ctx, tx, experience); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return model.Resume{}, err
 	}
@@ -76,6 +98,9 @@ func (r *ResumeRepository) GetResumeByID(ctx context.Context, id int) (model.Res
 		&resume.Id, &resume.UserId, &resume.CategoryId, &resume.Description,
 		&resume.SalaryFrom, &resume.SalaryTo, &resume.SalaryPeriod, &resume.CreatedAt,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Resume{}, model.ErrResumeNotFound
+	}
 	if err != nil {
 		return model.Resume{}, err
 	}
@@ -83,17 +108,135 @@ func (r *ResumeRepository) GetResumeByID(ctx context.Context, id int) (model.Res
 	return resume, nil
 }
 
+// UpdateResume updates the resume row and reconciles its skills and
+// experiences in one transaction: entries without an ID are inserted,
+// entries with an ID are updated and stored entries missing from the lists
+// are deleted. An ID that does not belong to the resume fails with
+// model.ErrResumeSkillNotFound or model.ErrResumeExperienceNotFound.
 func (r *ResumeRepository) UpdateResume(ctx context.Context, resume model.Resume) error {
-	query := `
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		query := `
         UPDATE resumes SET category_id = $1, description = $2, salary_from = $3, salary_to = $4, salary_period = $5 WHERE id = $6`
 
-	_, err := r.db.ExecContext(ctx, query, resume.CategoryId, resume.Description, resume.SalaryFrom, resume.SalaryTo, resume.SalaryPeriod, resume.Id)
-	return err
+		result, err := tx.ExecContext(ctx, query, resume.CategoryId, resume.Description, resume.SalaryFrom, resume.SalaryTo, resume.SalaryPeriod, resume.Id)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return model.ErrResumeNotFound
+		}
+
+		skillIDs := make([]int, 0, len(resume.Skills))
+		for _, skill := range resume.Skills {
+			skillIDs = append(skillIDs, skill.Id)
+		}
+		if err := removeResumeRows(ctx, tx, "resume_skills", resume.Id, skillIDs, model.ErrResumeSkillNotFound); err != nil {
+			return err
+		}
+		experienceIDs := make([]int, 0, len(resume.Experiences))
+		for _, experience := range resume.Experiences {
+			experienceIDs = append(experienceIDs, experience.Id)
+		}
+		if err := removeResumeRows(ctx, tx, "resume_experiences", resume.Id, experienceIDs, model.ErrResumeExperienceNotFound); err != nil {
+			return err
+		}
+
+		for _, skill := range resume.Skills {
+			skill.ResumeId = resume.Id
+			if skill.Id == 0 {
+				err = insertResumeSkill(ctx, tx, skill)
+			} else {
+				_, err = tx.ExecContext(ctx, `UPDATE resume_skills SET skill = $1 WHERE id = $2`, skill.Skill, skill.Id)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, experience := range resume.Experiences {
+			experience.ResumeId = resume.Id
+			if experience.Id == 0 {
+				err = insertResumeExperience(ctx, tx, experience)
+			} else {
+				err = updateResumeExperience(ctx, tx, experience)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
+// DeleteResume deletes the resume with its skills and experiences.
 func (r *ResumeRepository) DeleteResume(ctx context.Context, id int) error {
-	query := `DELETE FROM resumes WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM resume_skills WHERE resume_id = $1`, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM resume_experiences WHERE resume_id = $1`, id); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM resumes WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return model.ErrResumeNotFound
+		}
+		return nil
+	})
+}
+
+// removeResumeRows locks the rows of the resume in table, deletes the ones
+// whose IDs are not kept and fails with notFound when a kept ID is not one
+// of them. Zero IDs stand for new rows and are skipped.
+func removeResumeRows(ctx context.Context, tx *sql.Tx, table string, resumeID int, kept []int, notFound error) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM `+table+` WHERE resume_id = $1 FOR UPDATE`, resumeID)
+	if err != nil {
+		return err
+	}
+	stored := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		stored[id] = false
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range kept {
+		if id == 0 {
+			continue
+		}
+		if _, ok := stored[id]; !ok {
+			return fmt.Errorf("%w: %d", notFound, id)
+		}
+		stored[id] = true
+	}
+	var removed []int
+	for id, keep := range stored {
+		if !keep {
+			removed = append(removed, id)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE id = ANY($1)`, pq.Array(removed))
 	return err
 }
 
@@ -108,10 +251,10 @@ func (r *ResumeRepository) ListResumes(ctx context.Context, filters map[string]i
 		idx++
 	}
 
-	categoryId, ok := filters["category_id"]
-	if ok && categoryId != 0 {
-		query += ` AND category_id = $` + fmt.Sprint(idx)
-		args = append(args, categoryId)
+	categoryIds, ok := filters["category_ids"].([]int)
+	if ok && len(categoryIds) > 0 {
+		query += ` AND ` + categorySubtreeCondition("category_id", idx)
+		args = append(args, pq.Array(categoryIds))
 		idx++
 	}
 
@@ -138,3 +281,10 @@ func (r *ResumeRepository) ListResumes(ctx context.Context, filters map[string]i
 
 	return resumes, nil
 }
+
+// CountByCategory returns the number of resumes attached directly to each
+// category.
+func (r *ResumeRepository) CountByCategory(ctx context.Context) (map[int]int, error) {
+	query := `SELECT category_id, COUNT(*) FROM resumes GROUP BY category_id`
+	return scanCategoryCounts(r.db.QueryContext(ctx, query))
+}
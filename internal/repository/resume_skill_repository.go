@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
 )
 
 type ResumeSkillRepository struct {
@@ -91,3 +92,44 @@ func (r *ResumeSkillRepository) ListResumeSkills(
 
 	return resumes, nil
 }
+
+// ListResumeSkillsByResumeIDs returns the skills of every given resume,
+// keyed by resume ID, in one query.
+func (r *ResumeSkillRepository) ListResumeSkillsByResumeIDs(
+	ctx context.Context,
+	resume_ids []int,
+) (map[int][]*model.ResumeSkill, error) {
+	skills := make(map[int][]*model.ResumeSkill, len(resume_ids))
+	if len(resume_ids) == 0 {
+		return skills, nil
+	}
+	query := `
+        SELECT id, resume_id, skill
+        FROM resume_skills
+        WHERE resume_id = ANY($1)
+        ORDER BY id
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(resume_ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		skill := &model.ResumeSkill{}
+		if err := rows.Scan(
+			&skill.Id, &skill.ResumeId, &skill.Skill,
+		); err != nil {
+			return nil, err
+		}
+		skills[skill.ResumeId] = append(skills[skill.ResumeId], skill)
+	}
+
+	return skills, rows.Err()
+}
+
+func insertResumeSkill(ctx context.Context, tx *sql.Tx, skill *model.ResumeSkill) error {
+	query := `INSERT INTO resume_skills (resume_id, skill) VALUES ($1, $2) RETURNING id`
+	return tx.QueryRowContext(ctx, query, skill.ResumeId, skill.Skill).Scan(&skill.Id)
+}
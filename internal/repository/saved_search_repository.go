@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+const savedSearchColumns = `id, user_id, name, filters, frequency, active, last_published_at, last_vacancy_id, last_checked_at, unsubscribe_token, created_at`
+
+type SavedSearchRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewSavedSearchRepository(log *slog.Logger, db *sql.DB) *SavedSearchRepository {
+	return &SavedSearchRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create stores the saved search. Only vacancies published after it was
+// created are reported.
+func (r *SavedSearchRepository) Create(ctx context.Context, search *model.SavedSearch) error {
+	query := `INSERT INTO saved_searches (user_id, name, filters, frequency, unsubscribe_token)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING ` + savedSearchColumns
+	row := r.db.QueryRowContext(ctx, query, search.UserID, search.Name, string(search.Filters), search.Frequency, search.UnsubscribeToken)
+	created, err := scanSavedSearch(row)
+	if err != nil {
+		return err
+	}
+	*search = *created
+	return nil
+}
+
+func (r *SavedSearchRepository) CountByUser(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM saved_searches WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *SavedSearchRepository) ListByUser(ctx context.Context, userID int64) ([]model.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE user_id = $1 ORDER BY created_at DESC`
+	return scanSavedSearches(r.db.QueryContext(ctx, query, userID))
+}
+
+// ListDue returns the active saved searches to check now: instant ones every
+// time, daily ones when they were last checked before dailyBefore.
+func (r *SavedSearchRepository) ListDue(ctx context.Context, dailyBefore time.Time) ([]model.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches
+			WHERE active AND (frequency = 'instant' OR last_checked_at IS NULL OR last_checked_at <= $1)
+			ORDER BY id`
+	return scanSavedSearches(r.db.QueryContext(ctx, query, dailyBefore))
+}
+
+// MarkChecked records the newest reported vacancy and when the search was
+// checked.
+func (r *SavedSearchRepository) MarkChecked(ctx context.Context, id int64, lastPublishedAt time.Time, lastVacancyID int64) error {
+	query := `UPDATE saved_searches SET last_published_at = $2, last_vacancy_id = $3, last_checked_at = NOW(), updated_at = NOW() WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, lastPublishedAt, lastVacancyID)
+	return err
+}
+
+// Delete removes the user's saved search and reports whether it existed.
+func (r *SavedSearchRepository) Delete(ctx context.Context, id, userID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Unsubscribe deactivates the saved search with the token and reports
+// whether one matched.
+func (r *SavedSearchRepository) Unsubscribe(ctx context.Context, token string) (bool, error) {
+	query := `UPDATE saved_searches SET active = FALSE, updated_at = NOW() WHERE unsubscribe_token = $1`
+	result, err := r.db.ExecContext(ctx, query, token)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func scanSavedSearch(row rowScanner) (*model.SavedSearch, error) {
+	var s model.SavedSearch
+	var filters []byte
+	err := row.Scan(&s.ID, &s.UserID, &s.Name, &filters, &s.Frequency, &s.Active, &s.LastPublishedAt, &s.LastVacancyID, &s.LastCheckedAt, &s.UnsubscribeToken, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	s.Filters = filters
+	return &s, nil
+}
+
+func scanSavedSearches(rows *sql.Rows, err error) ([]model.SavedSearch, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var searches []model.SavedSearch
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, *s)
+	}
+	return searches, rows.Err()
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// withTx runs fn inside a transaction and commits it when fn succeeds.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,12 +1,14 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log/slog"
 
 	"github.com/aidosgal/alem.core-service/internal/dto"
 	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
 )
 
 type UserRepository struct {
@@ -35,11 +37,11 @@ func (r *UserRepository) CreateUser(user *model.User) (int64, error) {
 }
 
 func (r *UserRepository) GetUserByPhone(phone string) (*model.User, error) {
-	query := `SELECT id, name, organization_id, phone, password, avatar_url, balance, created_at, updated_at FROM users WHERE phone = $1`
+	query := `SELECT id, name, organization_id, phone, password, avatar_url, balance, role, created_at, updated_at FROM users WHERE phone = $1`
 	row := r.db.QueryRow(query, phone)
 
 	var user model.User
-	err := row.Scan(&user.Id, &user.Name, &user.OrganizationId, &user.Phone, &user.Password, &user.AvatarURL, &user.Balance, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.Id, &user.Name, &user.OrganizationId, &user.Phone, &user.Password, &user.AvatarURL, &user.Balance, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -47,11 +49,11 @@ func (r *UserRepository) GetUserByPhone(phone string) (*model.User, error) {
 }
 
 func (r *UserRepository) GetUserByID(id int) (*model.User, error) {
-	query := `SELECT id, name, organization_id, phone, password, avatar_url, balance, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT id, name, organization_id, phone, password, avatar_url, balance, role, created_at, updated_at FROM users WHERE id = $1`
 	row := r.db.QueryRow(query, id)
 
 	var user model.User
-	err := row.Scan(&user.Id, &user.Name, &user.OrganizationId, &user.Phone, &user.Password, &user.AvatarURL, &user.Balance, &user.CreatedAt, &user.UpdatedAt)
+	err := row.Scan(&user.Id, &user.Name, &user.OrganizationId, &user.Phone, &user.Password, &user.AvatarURL, &user.Balance, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -84,6 +86,31 @@ func (r *UserRepository) ListUsers(organizationID int) ([]model.User, error) {
 	return users, nil
 }
 
+// ListUsersByOrganizations returns the users of every given organization,
+// keyed by organization ID, in one query.
+func (r *UserRepository) ListUsersByOrganizations(ctx context.Context, organizationIDs []int) (map[int][]model.User, error) {
+	result := make(map[int][]model.User, len(organizationIDs))
+	if len(organizationIDs) == 0 {
+		return result, nil
+	}
+	query := `SELECT id, name, organization_id, phone, avatar_url, balance, created_at, updated_at FROM users WHERE organization_id = ANY($1)`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(organizationIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(&user.Id, &user.Name, &user.OrganizationId, &user.Phone, &user.AvatarURL, &user.Balance, &user.CreatedAt, &user.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		result[user.OrganizationId] = append(result[user.OrganizationId], user)
+	}
+	return result, rows.Err()
+}
+
 func (r *UserRepository) GetOrganization(id int) (*dto.Organization, error) {
 	query := "SELECT id, name, description FROM organizations WHERE id = $1"
 	org := &dto.Organization{}
@@ -6,8 +6,11 @@ import (
 	"log/slog"
 
 	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
 )
 
+const vacancyDetailColumns = `id, group_name, name, value, icon_url, vacancy_id, attribute_key, bool_value, number_value, number_to`
+
 type VacancyDetailRepository struct {
 	log *slog.Logger
 	db  *sql.DB
@@ -21,10 +24,15 @@ func NewVacancyDetailRepository(log *slog.Logger, db *sql.DB) *VacancyDetailRepo
 }
 
 func (r *VacancyDetailRepository) Create(ctx context.Context, detail *model.VacancyDetail) (int64, error) {
-	query := `INSERT INTO vacancy_details (group_name, name, value, icon_url, vacancy_id) 
-			VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	return insertVacancyDetail(ctx, r.db, detail)
+}
+
+func insertVacancyDetail(ctx context.Context, q rowQuerier, detail *model.VacancyDetail) (int64, error) {
+	query := `INSERT INTO vacancy_details (group_name, name, value, icon_url, vacancy_id, attribute_key, bool_value, number_value, number_to) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
 	var id int64
-	err := r.db.QueryRowContext(ctx, query, detail.GroupName, detail.Name, detail.Value, detail.IconURL, detail.VacancyID).Scan(&id)
+	err := q.QueryRowContext(ctx, query, detail.GroupName, detail.Name, detail.Value, detail.IconURL, detail.VacancyID,
+		detail.AttributeKey, detail.BoolValue, detail.NumberValue, detail.NumberTo).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -32,28 +40,33 @@ func (r *VacancyDetailRepository) Create(ctx context.Context, detail *model.Vaca
 }
 
 func (r *VacancyDetailRepository) GetByVacancyID(ctx context.Context, vacancyID int64) ([]model.VacancyDetail, error) {
-	query := `SELECT id, group_name, name, value, icon_url, vacancy_id FROM vacancy_details WHERE vacancy_id = $1`
-	rows, err := r.db.QueryContext(ctx, query, vacancyID)
+	query := `SELECT ` + vacancyDetailColumns + ` FROM vacancy_details WHERE vacancy_id = $1 ORDER BY id`
+	return scanVacancyDetails(r.db.QueryContext(ctx, query, vacancyID))
+}
+
+// GetByVacancyIDs returns the details of every given vacancy, keyed by
+// vacancy ID, in one query.
+func (r *VacancyDetailRepository) GetByVacancyIDs(ctx context.Context, vacancyIDs []int64) (map[int64][]model.VacancyDetail, error) {
+	result := make(map[int64][]model.VacancyDetail, len(vacancyIDs))
+	if len(vacancyIDs) == 0 {
+		return result, nil
+	}
+	query := `SELECT ` + vacancyDetailColumns + ` FROM vacancy_details WHERE vacancy_id = ANY($1) ORDER BY id`
+	details, err := scanVacancyDetails(r.db.QueryContext(ctx, query, pq.Array(vacancyIDs)))
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var details []model.VacancyDetail
-	for rows.Next() {
-		var detail model.VacancyDetail
-		err := rows.Scan(&detail.ID, &detail.GroupName, &detail.Name, &detail.Value, &detail.IconURL, &detail.VacancyID)
-		if err != nil {
-			return nil, err
-		}
-		details = append(details, detail)
+	for _, detail := range details {
+		result[detail.VacancyID] = append(result[detail.VacancyID], detail)
 	}
-	return details, nil
+	return result, nil
 }
 
 func (r *VacancyDetailRepository) Update(ctx context.Context, detail *model.VacancyDetail) error {
-	query := `UPDATE vacancy_details SET group_name=$1, name=$2, value=$3, icon_url=$4 WHERE id=$5`
-	_, err := r.db.ExecContext(ctx, query, detail.GroupName, detail.Name, detail.Value, detail.IconURL, detail.ID)
+	query := `UPDATE vacancy_details SET group_name=$1, name=$2, value=$3, icon_url=$4, attribute_key=$5, bool_value=$6, number_value=$7, number_to=$8
+			WHERE id=$9`
+	_, err := r.db.ExecContext(ctx, query, detail.GroupName, detail.Name, detail.Value, detail.IconURL,
+		detail.AttributeKey, detail.BoolValue, detail.NumberValue, detail.NumberTo, detail.ID)
 	return err
 }
 
@@ -62,3 +75,22 @@ func (r *VacancyDetailRepository) Delete(ctx context.Context, id int64) error {
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
+
+func scanVacancyDetails(rows *sql.Rows, err error) ([]model.VacancyDetail, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var details []model.VacancyDetail
+	for rows.Next() {
+		var detail model.VacancyDetail
+		err := rows.Scan(&detail.ID, &detail.GroupName, &detail.Name, &detail.Value, &detail.IconURL, &detail.VacancyID,
+			&detail.AttributeKey, &detail.BoolValue, &detail.NumberValue, &detail.NumberTo)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, detail)
+	}
+	return details, rows.Err()
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
+)
+
+type VacancyFingerprintRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewVacancyFingerprintRepository(log *slog.Logger, db *sql.DB) *VacancyFingerprintRepository {
+	return &VacancyFingerprintRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+// FindCandidates returns the vacancies in one of statuses that share a band
+// with the fingerprint and have the same category and location (country,
+// region and city) as vacancy, which itself is excluded.
+func (r *VacancyFingerprintRepository) FindCandidates(ctx context.Context, fingerprint *model.VacancyFingerprint, vacancy *model.Vacancy, statuses []string) ([]model.DuplicateCandidate, error) {
+	query := `SELECT v.id, v.organization_id, v.duplicate_cluster_id, f.signature
+			FROM vacancy_fingerprints f
+			JOIN vacancies v ON v.id = f.vacancy_id
+			WHERE f.bands && $1 AND v.category_id = $2 AND v.country_code IS NOT DISTINCT FROM $3
+				AND v.region_id IS NOT DISTINCT FROM $4 AND v.city_id IS NOT DISTINCT FROM $5
+				AND v.status = ANY($6) AND v.id <> $7 AND v.deleted_at IS NULL`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(fingerprint.Bands), vacancy.CategoryID, nullString(vacancy.CountryCode),
+		vacancy.RegionID, vacancy.CityID, pq.Array(statuses), vacancy.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []model.DuplicateCandidate
+	for rows.Next() {
+		var c model.DuplicateCandidate
+		if err := rows.Scan(&c.VacancyID, &c.OrganizationID, &c.DuplicateClusterID, pq.Array(&c.Signature)); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// Save stores the fingerprint and puts the vacancy into clusterID, or takes
+// it out of its cluster when clusterID is nil. members are other vacancies
+// that join the cluster with it.
+func (r *VacancyFingerprintRepository) Save(ctx context.Context, fingerprint *model.VacancyFingerprint, clusterID *int64, members ...int64) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return saveFingerprint(ctx, tx, fingerprint, clusterID, members)
+	})
+}
+
+func saveFingerprint(ctx context.Context, tx *sql.Tx, fingerprint *model.VacancyFingerprint, clusterID *int64, members []int64) error {
+	query := `INSERT INTO vacancy_fingerprints (vacancy_id, signature, bands) VALUES ($1, $2, $3)
+			ON CONFLICT (vacancy_id) DO UPDATE SET signature = EXCLUDED.signature, bands = EXCLUDED.bands, updated_at = NOW()`
+	if _, err := tx.ExecContext(ctx, query, fingerprint.VacancyID, pq.Array(fingerprint.Signature), pq.Array(fingerprint.Bands)); err != nil {
+		return err
+	}
+
+	ids := append([]int64{fingerprint.VacancyID}, members...)
+	_, err := tx.ExecContext(ctx, `UPDATE vacancies SET duplicate_cluster_id = $2 WHERE id = ANY($1)`, pq.Array(ids), clusterID)
+	return err
+}
+
+// ListUnfingerprinted returns up to limit vacancies that have no
+// fingerprint yet, oldest first.
+func (r *VacancyFingerprintRepository) ListUnfingerprinted(ctx context.Context, limit int) ([]model.Vacancy, error) {
+	query := `SELECT ` + vacancyColumns + ` FROM vacancies
+			WHERE deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM vacancy_fingerprints f WHERE f.vacancy_id = vacancies.id)
+			ORDER BY id
+			LIMIT $1`
+	return scanVacancies(r.db.QueryContext(ctx, query, limit))
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+const vacancyImportColumns = `id, organization_id, user_id, file_name, format, status, total_rows, valid_rows, processed_rows, created_rows, errors, payload, created_at, started_at, finished_at`
+
+type VacancyImportRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewVacancyImportRepository(log *slog.Logger, db *sql.DB) *VacancyImportRepository {
+	return &VacancyImportRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+func (r *VacancyImportRepository) Create(ctx context.Context, vacancyImport *model.VacancyImport) error {
+	query := `INSERT INTO vacancy_imports (organization_id, user_id, file_name, format, total_rows, valid_rows, errors, payload)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING ` + vacancyImportColumns
+	row := r.db.QueryRowContext(ctx, query, vacancyImport.OrganizationID, vacancyImport.UserID, vacancyImport.FileName, vacancyImport.Format,
+		vacancyImport.TotalRows, vacancyImport.ValidRows, string(vacancyImport.Errors), string(vacancyImport.Payload))
+	created, err := scanVacancyImport(row)
+	if err != nil {
+		return err
+	}
+	*vacancyImport = *created
+	return nil
+}
+
+// GetByID returns the organization's import or nil when there is none.
+func (r *VacancyImportRepository) GetByID(ctx context.Context, id, organizationID int64) (*model.VacancyImport, error) {
+	query := `SELECT ` + vacancyImportColumns + ` FROM vacancy_imports WHERE id = $1 AND organization_id = $2`
+	vacancyImport, err := scanVacancyImport(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return vacancyImport, err
+}
+
+// Queue hands a validated import over to the import job and reports whether
+// it was in the validated state.
+func (r *VacancyImportRepository) Queue(ctx context.Context, id, organizationID int64) (bool, error) {
+	query := `UPDATE vacancy_imports SET status = 'queued', updated_at = NOW()
+			WHERE id = $1 AND organization_id = $2 AND status = 'validated'`
+	result, err := r.db.ExecContext(ctx, query, id, organizationID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// RequeueStale queues running imports again whose progress has not been
+// saved for the lease, because the process running them is gone. Imports
+// other processes are still working on keep running. Requeued imports
+// continue after their last processed row.
+func (r *VacancyImportRepository) RequeueStale(ctx context.Context, lease time.Duration) (int64, error) {
+	query := `UPDATE vacancy_imports SET status = 'queued', updated_at = NOW()
+			WHERE status = 'running' AND updated_at < NOW() - make_interval(secs => $1)`
+	result, err := r.db.ExecContext(ctx, query, lease.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ClaimNext marks the oldest queued import as running and returns it, or
+// nil when the queue is empty.
+func (r *VacancyImportRepository) ClaimNext(ctx context.Context) (*model.VacancyImport, error) {
+	query := `UPDATE vacancy_imports SET status = 'running', started_at = COALESCE(started_at, NOW()), updated_at = NOW()
+			WHERE id = (
+				SELECT id FROM vacancy_imports WHERE status = 'queued'
+				ORDER BY id LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + vacancyImportColumns
+	vacancyImport, err := scanVacancyImport(r.db.QueryRowContext(ctx, query))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return vacancyImport, err
+}
+
+// SaveProgress records how many payload rows have been committed. It also
+// renews the lease of the running import.
+func (r *VacancyImportRepository) SaveProgress(ctx context.Context, vacancyImport *model.VacancyImport) error {
+	query := `UPDATE vacancy_imports SET processed_rows = $2, created_rows = $3, errors = $4, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, vacancyImport.ID, vacancyImport.ProcessedRows, vacancyImport.CreatedRows, string(vacancyImport.Errors))
+	return err
+}
+
+// Finish saves the final progress with the given status. The payload is
+// no longer needed and is cleared.
+func (r *VacancyImportRepository) Finish(ctx context.Context, vacancyImport *model.VacancyImport, status string) error {
+	query := `UPDATE vacancy_imports SET status = $2, processed_rows = $3, created_rows = $4, errors = $5, payload = '[]',
+			finished_at = NOW(), updated_at = NOW()
+			WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, vacancyImport.ID, status, vacancyImport.ProcessedRows, vacancyImport.CreatedRows, string(vacancyImport.Errors))
+	return err
+}
+
+func scanVacancyImport(row rowScanner) (*model.VacancyImport, error) {
+	var v model.VacancyImport
+	var importErrors, payload []byte
+	err := row.Scan(&v.ID, &v.OrganizationID, &v.UserID, &v.FileName, &v.Format, &v.Status, &v.TotalRows, &v.ValidRows,
+		&v.ProcessedRows, &v.CreatedRows, &importErrors, &payload, &v.CreatedAt, &v.StartedAt, &v.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	v.Errors = importErrors
+	v.Payload = payload
+	return &v, nil
+}
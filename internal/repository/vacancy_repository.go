@@ -3,13 +3,43 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/aidosgal/alem.core-service/internal/dto"
 	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
 )
 
+const vacancyColumns = `id, title, description, primary_locale, salary_from, salary_to, salary_exact, salary_type, salary_currency, salary_from_base, salary_to_base, salary_exact_base, organization_id, category_id, country, country_code, region_id, city_id, latitude, longitude, status, published_at, expires_at, deleted_at, risk_score, duplicate_cluster_id, created_at, updated_at, version`
+
+// salaryBaseAssignments recomputes the normalized monthly salaries in the
+// base currency from the stored salary columns.
+const salaryBaseAssignments = `salary_from_base = monthly_salary_base(salary_from, salary_type, salary_currency),
+			salary_to_base = monthly_salary_base(salary_to, salary_type, salary_currency),
+			salary_exact_base = monthly_salary_base(salary_exact, salary_type, salary_currency)`
+
+// salarySortExpression orders vacancies by their normalized salary, preferring
+// the exact amount and then the upper bound of the range.
+const salarySortExpression = `COALESCE(salary_exact_base, salary_to_base, salary_from_base)`
+
+// trendingSortExpression scores a vacancy by its views and applications over
+// the last seven days. An application weighs as much as five views.
+const trendingSortExpression = `(SELECT COALESCE(SUM(s.views + s.applications * 5), 0) FROM vacancy_daily_stats s
+			WHERE s.vacancy_id = vacancies.id AND s.day > CURRENT_DATE - 7)`
+
+// duplicateClusterKey identifies the duplicate cluster of a vacancy. A
+// vacancy outside any cluster forms its own.
+const duplicateClusterKey = `COALESCE(duplicate_cluster_id, -id)`
+
+// publishedVacancyCondition matches vacancies that job seekers may see. A
+// published vacancy past its expiry date is hidden even before the expiry
+// job has flipped its status. Callers exclude soft-deleted rows separately.
+const publishedVacancyCondition = `(status = 'published' AND (expires_at IS NULL OR expires_at > NOW()))`
+
 type VacancyRepository struct {
 	log *slog.Logger
 	db  *sql.DB
@@ -22,105 +52,875 @@ func NewVacancyRepository(log *slog.Logger, db *sql.DB) *VacancyRepository {
 	}
 }
 
-func (r *VacancyRepository) Create(ctx context.Context, vacancy *model.Vacancy) (int64, error) {
-	query := `INSERT INTO vacancies (title, description, salary_from, salary_to, salary_exact, salary_type, salary_currency, organization_id, category_id, country) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+// Create stores the vacancy with everything in write in one transaction and
+// sets the IDs of the vacancy and its details.
+func (r *VacancyRepository) Create(ctx context.Context, vacancy *model.Vacancy, write model.VacancyWrite) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		id, err := insertVacancy(ctx, tx, vacancy)
+		if err != nil {
+			return err
+		}
+		vacancy.ID = id
+
+		for i := range write.Details {
+			write.Details[i].VacancyID = id
+			if write.Details[i].ID, err = insertVacancyDetail(ctx, tx, &write.Details[i]); err != nil {
+				return err
+			}
+		}
+		return applyVacancyWrite(ctx, tx, vacancy, write)
+	})
+}
+
+func insertVacancy(ctx context.Context, tx *sql.Tx, vacancy *model.Vacancy) (int64, error) {
+	query := `INSERT INTO vacancies (title, description, salary_from, salary_to, salary_exact, salary_type, salary_currency, organization_id, category_id, country, status, published_at, expires_at,
+				salary_from_base, salary_to_base, salary_exact_base, country_code, region_id, city_id, latitude, longitude, primary_locale) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+				monthly_salary_base($3, $6, $7), monthly_salary_base($4, $6, $7), monthly_salary_base($5, $6, $7), $14, $15, $16, $17, $18, $19) RETURNING id`
 	var id int64
-	err := r.db.QueryRowContext(ctx, query, vacancy.Title, vacancy.Description, vacancy.SalaryFrom, vacancy.SalaryTo, vacancy.SalaryExact, vacancy.SalaryType, vacancy.SalaryCurrency, vacancy.OrganizationID, vacancy.CategoryID, vacancy.Country).Scan(&id)
+	err := tx.QueryRowContext(ctx, query, vacancy.Title, vacancy.Description, vacancy.SalaryFrom, vacancy.SalaryTo, vacancy.SalaryExact, vacancy.SalaryType, vacancy.SalaryCurrency, vacancy.OrganizationID, vacancy.CategoryID, vacancy.Country, vacancy.Status, vacancy.PublishedAt, vacancy.ExpiresAt,
+		nullString(vacancy.CountryCode), vacancy.RegionID, vacancy.CityID, vacancy.Latitude, vacancy.Longitude, vacancy.PrimaryLocale).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
+// GetByID returns nil without an error when the vacancy does not exist or
+// has been deleted.
 func (r *VacancyRepository) GetByID(ctx context.Context, id int64) (*model.Vacancy, error) {
-	query := `SELECT id, title, description, salary_from, salary_to, salary_exact, salary_type, salary_currency, organization_id, category_id, country, created_at FROM vacancies WHERE id = $1`
-	var vacancy model.Vacancy
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&vacancy.ID, &vacancy.Title, &vacancy.Description, &vacancy.SalaryFrom, &vacancy.SalaryTo, &vacancy.SalaryExact, &vacancy.SalaryType, &vacancy.SalaryCurrency, &vacancy.OrganizationID, &vacancy.CategoryID, &vacancy.Country, &vacancy.CreatedAt,
-	)
+	return r.getByID(ctx, id, `SELECT `+vacancyColumns+` FROM vacancies WHERE id = $1 AND deleted_at IS NULL`)
+}
+
+// GetDeletedByID returns the vacancy only while it is soft-deleted.
+func (r *VacancyRepository) GetDeletedByID(ctx context.Context, id int64) (*model.Vacancy, error) {
+	return r.getByID(ctx, id, `SELECT `+vacancyColumns+` FROM vacancies WHERE id = $1 AND deleted_at IS NOT NULL`)
+}
+
+func (r *VacancyRepository) getByID(ctx context.Context, id int64, query string) (*model.Vacancy, error) {
+	vacancy, err := scanVacancy(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &vacancy, nil
+	return vacancy, nil
 }
 
 func (r *VacancyRepository) Update(ctx context.Context, vacancy *model.Vacancy) error {
-	query := `UPDATE vacancies SET title=$1, description=$2, salary_from=$3, salary_to=$4, salary_exact=$5, salary_type=$6, salary_currency=$7, organization_id=$8, category_id=$9, country=$10 WHERE id=$10`
-	_, err := r.db.ExecContext(ctx, query, vacancy.Title, vacancy.Description, vacancy.SalaryFrom, vacancy.SalaryTo, vacancy.SalaryExact, vacancy.SalaryType, vacancy.SalaryCurrency, vacancy.OrganizationID, vacancy.CategoryID, vacancy.Country, vacancy.ID)
-	return err
+	return updateVacancy(ctx, r.db, vacancy)
+}
+
+// UpdateWithDetails updates the vacancy row and reconciles write.Details in
+// one transaction: details without an ID are inserted, details with an ID
+// are updated and stored details missing from the list are deleted. An ID
+// that does not belong to the vacancy fails with
+// model.ErrVacancyDetailNotFound. The rest of the write, including a move
+// into the moderation queue, is applied in the same transaction.
+//
+// write.Change moves the vacancy to its next version and stores the Current
+// snapshot under it. The Previous snapshot is stored under the old version
+// when that has no snapshot yet, so the history starts at the state before
+// the first recorded change.
+func (r *VacancyRepository) UpdateWithDetails(ctx context.Context, vacancy *model.Vacancy, write model.VacancyWrite) error {
+	change, details := write.Change, write.Details
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var version int
+		var changedAt time.Time
+		err := tx.QueryRowContext(ctx, `SELECT version, COALESCE(updated_at, created_at) FROM vacancies WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, vacancy.ID).
+			Scan(&version, &changedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrVacancyNotFound
+		}
+		if err != nil {
+			return err
+		}
+		query := `INSERT INTO vacancy_versions (vacancy_id, version, user_id, content, created_at)
+				VALUES ($1, $2, NULL, $3, $4)
+				ON CONFLICT (vacancy_id, version) DO NOTHING`
+		if _, err := tx.ExecContext(ctx, query, vacancy.ID, version, change.Previous, changedAt); err != nil {
+			return err
+		}
+
+		if err := updateVacancy(ctx, tx, vacancy); err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM vacancy_details WHERE vacancy_id = $1 FOR UPDATE`, vacancy.ID)
+		if err != nil {
+			return err
+		}
+		stored := map[int64]bool{}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			stored[id] = false
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// Removed details go first and new ones last so that attribute keys
+		// can move between details.
+		for _, detail := range details {
+			if detail.ID == 0 {
+				continue
+			}
+			if _, ok := stored[detail.ID]; !ok {
+				return fmt.Errorf("%w: %d", model.ErrVacancyDetailNotFound, detail.ID)
+			}
+			stored[detail.ID] = true
+		}
+		var removed []int64
+		for id, kept := range stored {
+			if !kept {
+				removed = append(removed, id)
+			}
+		}
+		if len(removed) > 0 {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM vacancy_details WHERE id = ANY($1)`, pq.Array(removed)); err != nil {
+				return err
+			}
+		}
+
+		for _, detail := range details {
+			if detail.ID == 0 {
+				continue
+			}
+			query := `UPDATE vacancy_details
+					SET group_name=$1, name=$2, value=$3, icon_url=$4, attribute_key=$5, bool_value=$6, number_value=$7, number_to=$8, updated_at=NOW()
+					WHERE id=$9`
+			if _, err := tx.ExecContext(ctx, query, detail.GroupName, detail.Name, detail.Value, detail.IconURL,
+				detail.AttributeKey, detail.BoolValue, detail.NumberValue, detail.NumberTo, detail.ID); err != nil {
+				return err
+			}
+		}
+		for _, detail := range details {
+			if detail.ID != 0 {
+				continue
+			}
+			query := `INSERT INTO vacancy_details (group_name, name, value, icon_url, vacancy_id, attribute_key, bool_value, number_value, number_to)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+			if _, err := tx.ExecContext(ctx, query, detail.GroupName, detail.Name, detail.Value, detail.IconURL, vacancy.ID,
+				detail.AttributeKey, detail.BoolValue, detail.NumberValue, detail.NumberTo); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.QueryRowContext(ctx, `UPDATE vacancies SET version = version + 1 WHERE id = $1 RETURNING version`, vacancy.ID).
+			Scan(&vacancy.Version); err != nil {
+			return err
+		}
+		query = `INSERT INTO vacancy_versions (vacancy_id, version, user_id, content) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.ExecContext(ctx, query, vacancy.ID, vacancy.Version, change.UserID, change.Current); err != nil {
+			return err
+		}
+		return applyVacancyWrite(ctx, tx, vacancy, write)
+	})
+}
+
+// ListVersions returns the stored versions of the vacancy, oldest first.
+func (r *VacancyRepository) ListVersions(ctx context.Context, vacancyID int64) ([]model.VacancyVersion, error) {
+	query := `SELECT vacancy_id, version, user_id, content, created_at FROM vacancy_versions
+			WHERE vacancy_id = $1
+			ORDER BY version`
+	rows, err := r.db.QueryContext(ctx, query, vacancyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []model.VacancyVersion
+	for rows.Next() {
+		version, err := scanVacancyVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+	return versions, rows.Err()
+}
+
+func (r *VacancyRepository) GetVersion(ctx context.Context, vacancyID int64, version int) (*model.VacancyVersion, error) {
+	query := `SELECT vacancy_id, version, user_id, content, created_at FROM vacancy_versions
+			WHERE vacancy_id = $1 AND version = $2`
+	v, err := scanVacancyVersion(r.db.QueryRowContext(ctx, query, vacancyID, version))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrVacancyVersionNotFound
+	}
+	return v, err
 }
 
+func scanVacancyVersion(row rowScanner) (*model.VacancyVersion, error) {
+	var v model.VacancyVersion
+	if err := row.Scan(&v.VacancyID, &v.Version, &v.UserID, &v.Content, &v.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// UpdateStatus moves the vacancy from the from status to the to status. It
+// returns false when the vacancy is no longer in the from status, so a
+// concurrent change (for example the expiry job) is never overwritten.
+func (r *VacancyRepository) UpdateStatus(ctx context.Context, id int64, from, to string, publishedAt, expiresAt *time.Time) (bool, error) {
+	query := `UPDATE vacancies
+			SET status = $3, published_at = $4, expires_at = $5, expiry_warned_at = NULL, updated_at = NOW()
+			WHERE id = $1 AND status = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, id, from, to, publishedAt, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ExpireDue marks every published vacancy past its expiry date as expired and
+// returns the vacancies it changed.
+func (r *VacancyRepository) ExpireDue(ctx context.Context) ([]model.Vacancy, error) {
+	query := `UPDATE vacancies SET status = 'expired', updated_at = NOW()
+			WHERE status = 'published' AND expires_at <= NOW() AND deleted_at IS NULL
+			RETURNING ` + vacancyColumns
+	return scanVacancies(r.db.QueryContext(ctx, query))
+}
+
+// MarkExpiring flags published vacancies that expire before the given time
+// and have not been warned about yet, and returns them.
+func (r *VacancyRepository) MarkExpiring(ctx context.Context, before time.Time) ([]model.Vacancy, error) {
+	query := `UPDATE vacancies SET expiry_warned_at = NOW()
+			WHERE status = 'published' AND expires_at > NOW() AND expires_at <= $1 AND expiry_warned_at IS NULL AND deleted_at IS NULL
+			RETURNING ` + vacancyColumns
+	return scanVacancies(r.db.QueryContext(ctx, query, before))
+}
+
+// Delete soft-deletes the vacancy. The row and its details stay in place
+// until Purge removes them.
 func (r *VacancyRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM vacancies WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	query := `UPDATE vacancies SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return model.ErrVacancyNotFound
+	}
+	return nil
+}
+
+// Restore undoes a soft delete made after deletedAfter. It returns false when
+// the vacancy is not deleted or was deleted earlier than that.
+func (r *VacancyRepository) Restore(ctx context.Context, id int64, deletedAfter time.Time) (bool, error) {
+	query := `UPDATE vacancies SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at > $2`
+	result, err := r.db.ExecContext(ctx, query, id, deletedAfter)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Purge permanently removes vacancies soft-deleted before the given time,
+// together with their details, and returns how many were removed.
+// Vacancies that candidates applied to are kept deleted, so that the
+// applications keep the vacancy and the versions they were made against.
+func (r *VacancyRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	query := `DELETE FROM vacancies WHERE deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM vacancy_applications WHERE vacancy_applications.vacancy_id = vacancies.id)`
+	result, err := r.db.ExecContext(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// vacancyFacetDimensions lists the facet dimensions in the order of their
+// grouping sets in Facets.
+var vacancyFacetDimensions = []string{
+	model.VacancyFacetCategory,
+	model.VacancyFacetCountry,
+	model.VacancyFacetSalary,
+	model.VacancyFacetCurrency,
+	model.VacancyFacetVerified,
+}
+
+// vacancyListQuery is the filtering part of a vacancy list query shared by
+// List and the counts.
+type vacancyListQuery struct {
+	columns string
+	// selected names the same columns outside of a collapsing subquery.
+	selected   string
+	conditions []string
+	// byDimension holds the conditions on the facet dimensions, which the
+	// facet counts leave out one at a time.
+	byDimension map[string][]string
+	args        []any
+	// distance and relevance are set for radius searches and text searches.
+	distance  string
+	relevance string
+	collapse  bool
 }
 
-func (r *VacancyRepository) List(ctx context.Context, req dto.ListVacancyRequest) ([]model.Vacancy, int, error) {
-	query := `SELECT id, title, description, salary_from, salary_to, salary_exact, salary_type, salary_currency, organization_id, category_id, country, created_at FROM vacancies`
-	filters := []interface{}{}
-	conditions := []string{}
+// arg adds an argument to the query and returns its placeholder.
+func (q *vacancyListQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
 
-	argIndex := 1
+func newVacancyListQuery(req dto.ListVacancyRequest) *vacancyListQuery {
+	q := &vacancyListQuery{
+		columns:    vacancyColumns,
+		selected:   vacancyColumns,
+		conditions: []string{"deleted_at IS NULL"},
+		collapse:   req.CollapseDuplicates && !req.Mine,
 
-	if req.CategoryID > 0 {
-		conditions = append(conditions, fmt.Sprintf("category_id = $%d", argIndex))
-		filters = append(filters, req.CategoryID)
-		argIndex++
+		byDimension: map[string][]string{},
 	}
+
+	if req.Near != nil {
+		origin := fmt.Sprintf("ll_to_earth(%s, %s)", q.arg(req.Near.Latitude), q.arg(req.Near.Longitude))
+		radius := q.arg(req.RadiusKm * 1000)
+		// earth_box is a cheap, indexed bounding cube; earth_distance trims
+		// its corners to the exact radius.
+		q.conditions = append(q.conditions,
+			"latitude IS NOT NULL",
+			fmt.Sprintf("earth_box(%s, %s) @> ll_to_earth(latitude, longitude)", origin, radius),
+			fmt.Sprintf("earth_distance(%s, ll_to_earth(latitude, longitude)) <= %s", origin, radius))
+		q.distance = fmt.Sprintf("earth_distance(%s, ll_to_earth(latitude, longitude)) / 1000", origin)
+		q.columns += ", " + q.distance + " AS distance_km"
+		q.selected += ", distance_km"
+	}
+
+	if req.Mine {
+		q.conditions = append(q.conditions, "organization_id = "+q.arg(req.ViewerOrganizationID))
+		if len(req.Statuses) > 0 {
+			q.conditions = append(q.conditions, "status = ANY("+q.arg(pq.Array(req.Statuses))+")")
+		}
+	} else {
+		q.conditions = append(q.conditions, publishedVacancyCondition)
+		if req.ViewerUserID > 0 {
+			q.conditions = append(q.conditions, "organization_id NOT IN (SELECT organization_id FROM hidden_organizations WHERE user_id = "+q.arg(req.ViewerUserID)+")")
+		}
+	}
+
+	if req.FavoritesOf > 0 {
+		q.conditions = append(q.conditions, "id IN (SELECT vacancy_id FROM vacancy_favorites WHERE user_id = "+q.arg(req.FavoritesOf)+")")
+	}
+
+	if len(req.CategoryIDs) > 0 {
+		q.filter(model.VacancyFacetCategory, categorySubtreeCondition("category_id", len(q.args)+1))
+		q.args = append(q.args, pq.Array(req.CategoryIDs))
+	}
+	// Salary filters are monthly amounts in the base currency; the service
+	// converts them from the display currency.
 	if req.SalaryFrom > 0 {
-		conditions = append(conditions, fmt.Sprintf("salary_from >= $%d", argIndex))
-		filters = append(filters, req.SalaryFrom)
-		argIndex++
+		q.filter(model.VacancyFacetSalary, "COALESCE(salary_from_base, salary_exact_base) >= "+q.arg(req.SalaryFrom))
 	}
 	if req.SalaryTo > 0 {
-		conditions = append(conditions, fmt.Sprintf("salary_to <= $%d", argIndex))
-		filters = append(filters, req.SalaryTo)
-		argIndex++
+		q.filter(model.VacancyFacetSalary, "COALESCE(salary_to_base, salary_exact_base) <= "+q.arg(req.SalaryTo))
+	}
+	if len(req.CountryCodes) > 0 {
+		q.filter(model.VacancyFacetCountry, "country_code = ANY("+q.arg(pq.Array(req.CountryCodes))+")")
+	}
+	if req.Verified != nil {
+		q.filter(model.VacancyFacetVerified, "organization_id IN (SELECT id FROM organizations WHERE verified = "+q.arg(*req.Verified)+")")
+	}
+	if len(req.RegionIDs) > 0 {
+		q.conditions = append(q.conditions, "region_id = ANY("+q.arg(pq.Array(req.RegionIDs))+")")
+	}
+	if len(req.CityIDs) > 0 {
+		q.conditions = append(q.conditions, "city_id = ANY("+q.arg(pq.Array(req.CityIDs))+")")
+	}
+	if req.PublishedAfter != nil {
+		q.conditions = append(q.conditions, fmt.Sprintf("(published_at, id) > (%s, %s)", q.arg(*req.PublishedAfter), q.arg(req.PublishedAfterID)))
 	}
 	if req.Search != "" {
-		conditions = append(conditions, fmt.Sprintf("(title ILIKE $%d OR description ILIKE $%d)", argIndex, argIndex+1))
-		filters = append(filters, "%"+req.Search+"%", "%"+req.Search+"%")
-		argIndex += 2
+		pattern := q.arg("%" + req.Search + "%")
+		q.conditions = append(q.conditions, fmt.Sprintf(`(title ILIKE %[1]s OR description ILIKE %[1]s OR EXISTS (SELECT 1 FROM vacancy_translations t
+			WHERE t.vacancy_id = vacancies.id AND (t.title ILIKE %[1]s OR t.description ILIKE %[1]s)))`, pattern))
+		// A match in the title outranks one in a translated title, which
+		// outranks one in a description.
+		q.relevance = fmt.Sprintf(`(CASE WHEN title ILIKE %[1]s THEN 3
+				WHEN EXISTS (SELECT 1 FROM vacancy_translations t WHERE t.vacancy_id = vacancies.id AND t.title ILIKE %[1]s) THEN 2
+				ELSE 1 END)::float8`, pattern)
+	}
+	for _, attribute := range req.AttributeFilters {
+		condition, args := attributeFilterCondition(attribute, len(q.args)+1)
+		q.conditions = append(q.conditions, condition)
+		q.args = append(q.args, args...)
 	}
+	return q
+}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + joinConditions(conditions, " AND ")
+// filter adds a condition on a facet dimension.
+func (q *vacancyListQuery) filter(dimension, condition string) {
+	q.byDimension[dimension] = append(q.byDimension[dimension], condition)
+}
+
+func (q *vacancyListQuery) where() string {
+	conditions := append([]string{}, q.conditions...)
+	for _, dimension := range vacancyFacetDimensions {
+		conditions = append(conditions, q.byDimension[dimension]...)
+	}
+	return " WHERE " + joinConditions(conditions, " AND ")
+}
+
+// matches returns whether a vacancy passes the filters on the dimension.
+func (q *vacancyListQuery) matches(dimension string) string {
+	if len(q.byDimension[dimension]) == 0 {
+		return "TRUE"
+	}
+	return "(" + joinConditions(q.byDimension[dimension], " AND ") + ")"
+}
+
+// List returns a page of vacancies matching req in the order of req.Sort,
+// starting after req.After or skipping req.Offset vacancies. Each vacancy
+// carries its sort key for the cursor of the next page.
+func (r *VacancyRepository) List(ctx context.Context, req dto.ListVacancyRequest) ([]model.Vacancy, error) {
+	q := newVacancyListQuery(req)
+	order := newVacancyOrder(req.Sort, q)
+	q.columns += ", (" + order.key + ")::text AS sort_key"
+	q.selected += ", sort_key"
+
+	query := `SELECT ` + q.columns + ` FROM vacancies`
+	if q.collapse {
+		// Each duplicate cluster is listed once, as its oldest matching
+		// vacancy, with the number of other matching vacancies in it.
+		query = `SELECT ` + q.columns + `, COUNT(*) OVER (PARTITION BY ` + duplicateClusterKey + `) - 1 AS duplicates,
+				ROW_NUMBER() OVER (PARTITION BY ` + duplicateClusterKey + ` ORDER BY id) AS cluster_rank
+				FROM vacancies`
+	}
+	query += q.where()
+
+	// The page starts after the cursor in the collapsed list, so that the
+	// clusters stay represented by the same vacancies from page to page.
+	var after string
+	if req.After != nil {
+		after = fmt.Sprintf("(%s, id) %s (%s::%s, %s)", order.key, order.after(), q.arg(req.After.Key), order.keyType, q.arg(req.After.ID))
+	}
+	if q.collapse {
+		query = `SELECT ` + q.selected + `, duplicates FROM (` + query + `) vacancies WHERE cluster_rank = 1`
+		if after != "" {
+			query += " AND " + after
+		}
+	} else if after != "" {
+		query += " AND " + after
 	}
 
-	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
-	filters = append(filters, req.Limit, req.Offset)
+	query += " ORDER BY " + order.String()
+	query += " LIMIT " + q.arg(req.Limit)
+	if req.After == nil && req.Offset > 0 {
+		query += " OFFSET " + q.arg(req.Offset)
+	}
 
 	r.log.Debug("Executing query", slog.String("query", query))
 
-	rows, err := r.db.QueryContext(ctx, query, filters...)
+	rows, err := r.db.QueryContext(ctx, query, q.args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
 	var vacancies []model.Vacancy
 	for rows.Next() {
-		var v model.Vacancy
-		if err := rows.Scan(&v.ID, &v.Title, &v.Description, &v.SalaryFrom, &v.SalaryTo, &v.SalaryExact, &v.SalaryType, &v.SalaryCurrency, &v.OrganizationID, &v.CategoryID, &v.Country, &v.CreatedAt); err != nil {
-			return nil, 0, err
+		var distance float64
+		var sortKey string
+		var duplicates int
+		var extra []any
+		if req.Near != nil {
+			extra = append(extra, &distance)
+		}
+		extra = append(extra, &sortKey)
+		if q.collapse {
+			extra = append(extra, &duplicates)
+		}
+		v, err := scanVacancy(rows, extra...)
+		if err != nil {
+			return nil, err
 		}
-		vacancies = append(vacancies, v)
+		v.Duplicates = duplicates
+		v.SortKey = sortKey
+		if req.Near != nil {
+			v.DistanceKm = &distance
+		}
+		vacancies = append(vacancies, *v)
+	}
+	return vacancies, rows.Err()
+}
+
+// Count returns the number of vacancies matching req, counting each
+// duplicate cluster once when they are collapsed.
+func (r *VacancyRepository) Count(ctx context.Context, req dto.ListVacancyRequest) (int, error) {
+	q := newVacancyListQuery(req)
+	query := "SELECT COUNT(*) FROM vacancies"
+	if q.collapse {
+		query = "SELECT COUNT(DISTINCT " + duplicateClusterKey + ") FROM vacancies"
 	}
 
 	var total int
+	err := r.db.QueryRowContext(ctx, query+q.where(), q.args...).Scan(&total)
+	return total, err
+}
+
+// CountUpTo is Count stopping at limit, so that its cost stays bounded on
+// large lists. A result of limit means there are at least that many.
+func (r *VacancyRepository) CountUpTo(ctx context.Context, req dto.ListVacancyRequest, limit int) (int, error) {
+	q := newVacancyListQuery(req)
+	matches := "SELECT 1 FROM vacancies"
+	if q.collapse {
+		matches = "SELECT DISTINCT " + duplicateClusterKey + " FROM vacancies"
+	}
+	query := "SELECT COUNT(*) FROM (" + matches + q.where() + " LIMIT " + q.arg(limit) + ") matches"
+
+	var total int
+	err := r.db.QueryRowContext(ctx, query, q.args...).Scan(&total)
+	return total, err
+}
+
+// EstimateCount returns the planner's estimate of the number of vacancies
+// matching req. It costs no more than planning the query, but may be far
+// off for selective filters and does not account for duplicate clusters.
+func (r *VacancyRepository) EstimateCount(ctx context.Context, req dto.ListVacancyRequest) (int, error) {
+	q := newVacancyListQuery(req)
+	var plan []byte
+	if err := r.db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) SELECT id FROM vacancies"+q.where(), q.args...).Scan(&plan); err != nil {
+		return 0, err
+	}
+
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explained); err != nil {
+		return 0, err
+	}
+	if len(explained) == 0 {
+		return 0, errors.New("empty query plan")
+	}
+	return int(explained[0].Plan.Rows), nil
+}
+
+// Facets counts the vacancies matching req by top-level category, country,
+// salary bucket, salary currency and verified employer in one pass over the
+// matches. Each dimension is counted without the filters on it. Salaries
+// fall into the buckets split by salaryBounds, monthly amounts in the base
+// currency, numbered from 0 below the first bound.
+func (r *VacancyRepository) Facets(ctx context.Context, req dto.ListVacancyRequest, salaryBounds []float64) ([]model.VacancyFacetCount, error) {
+	q := newVacancyListQuery(req)
+	count := "COUNT(*)"
+	if q.collapse {
+		count = "COUNT(DISTINCT cluster)"
+	}
+	// counted returns the count of the dimension's grouping set, with the
+	// filters of every other dimension applied.
+	counted := func(dimension string) string {
+		var conditions []string
+		for _, other := range vacancyFacetDimensions {
+			if other != dimension {
+				conditions = append(conditions, "in_"+other)
+			}
+		}
+		return count + " FILTER (WHERE " + joinConditions(conditions, " AND ") + ")"
+	}
+
+	query := `WITH matches AS (
+				SELECT (SELECT root.id FROM categories c
+						JOIN categories root ON root.lft <= c.lft AND root.rgt >= c.rgt AND root.parent_id IS NULL
+						WHERE c.id = vacancies.category_id) AS category,
+					country_code AS country,
+					width_bucket((` + salarySortExpression + `)::float8, ` + q.arg(pq.Array(salaryBounds)) + `::float8[]) AS salary,
+					CASE WHEN ` + salarySortExpression + ` IS NOT NULL THEN salary_currency END AS currency,
+					(SELECT o.verified FROM organizations o WHERE o.id = vacancies.organization_id) AS verified,
+					` + duplicateClusterKey + ` AS cluster,
+					` + q.matches(model.VacancyFacetCategory) + ` AS in_category,
+					` + q.matches(model.VacancyFacetCountry) + ` AS in_country,
+					` + q.matches(model.VacancyFacetSalary) + ` AS in_salary,
+					` + q.matches(model.VacancyFacetCurrency) + ` AS in_currency,
+					` + q.matches(model.VacancyFacetVerified) + ` AS in_verified
+				FROM vacancies WHERE ` + joinConditions(q.conditions, " AND ") + `
+			)
+			SELECT CASE WHEN GROUPING(category) = 0 THEN 'category'
+					WHEN GROUPING(country) = 0 THEN 'country'
+					WHEN GROUPING(salary) = 0 THEN 'salary'
+					WHEN GROUPING(currency) = 0 THEN 'currency'
+					ELSE 'verified' END,
+				COALESCE(category::text, country, salary::text, currency, verified::text),
+				CASE WHEN GROUPING(category) = 0 THEN ` + counted(model.VacancyFacetCategory) + `
+					WHEN GROUPING(country) = 0 THEN ` + counted(model.VacancyFacetCountry) + `
+					WHEN GROUPING(salary) = 0 THEN ` + counted(model.VacancyFacetSalary) + `
+					WHEN GROUPING(currency) = 0 THEN ` + counted(model.VacancyFacetCurrency) + `
+					ELSE ` + counted(model.VacancyFacetVerified) + ` END
+			FROM matches
+			GROUP BY GROUPING SETS ((category), (country), (salary), (currency), (verified))`
 
-	countQuery := "SELECT COUNT(*) FROM vacancies"
-	if len(conditions) > 0 {
-		countQuery += " WHERE " + joinConditions(conditions, " AND ")
+	rows, err := r.db.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []model.VacancyFacetCount
+	for rows.Next() {
+		var c model.VacancyFacetCount
+		var value sql.NullString
+		if err := rows.Scan(&c.Dimension, &value, &c.Count); err != nil {
+			return nil, err
+		}
+		// Vacancies without a value in the dimension are not an option.
+		if !value.Valid || c.Count == 0 {
+			continue
+		}
+		c.Value = value.String
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// CountByCategory returns the number of published vacancies attached directly
+// to each category.
+func (r *VacancyRepository) CountByCategory(ctx context.Context) (map[int]int, error) {
+	query := `SELECT category_id, COUNT(*) FROM vacancies WHERE deleted_at IS NULL AND ` + publishedVacancyCondition + ` GROUP BY category_id`
+	return scanCategoryCounts(r.db.QueryContext(ctx, query))
+}
+
+// CountOpenByCountry returns the number of published vacancies in each
+// country.
+func (r *VacancyRepository) CountOpenByCountry(ctx context.Context) (map[string]int, error) {
+	query := `SELECT country_code, COUNT(*) FROM vacancies
+			WHERE deleted_at IS NULL AND country_code IS NOT NULL AND ` + publishedVacancyCondition + `
+			GROUP BY country_code`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var code string
+		var count int
+		if err := rows.Scan(&code, &count); err != nil {
+			return nil, err
+		}
+		counts[code] = count
+	}
+	return counts, rows.Err()
+}
+
+// ListFeed returns published vacancies for the public job feed. Without
+// since they are the most recently changed first. With since only vacancies
+// changed after it are returned, oldest change first, so that a feed cut at
+// limit can be continued from its last vacancy: after is that vacancy's id
+// and since its change time.
+func (r *VacancyRepository) ListFeed(ctx context.Context, since *time.Time, after *int64, limit int) ([]model.Vacancy, error) {
+	order := `COALESCE(updated_at, created_at) DESC, id DESC`
+	if since != nil {
+		order = `COALESCE(updated_at, created_at), id`
 	}
+	query := `SELECT ` + vacancyColumns + ` FROM vacancies
+			WHERE deleted_at IS NULL AND ` + publishedVacancyCondition + `
+				AND ($1::timestamp IS NULL OR COALESCE(updated_at, created_at) > $1
+					OR COALESCE(updated_at, created_at) = $1 AND id > $2)
+			ORDER BY ` + order + `
+			LIMIT $3`
+	return scanVacancies(r.db.QueryContext(ctx, query, since, after, limit))
+}
 
-	if err := r.db.QueryRowContext(ctx, countQuery, filters[:len(filters)-2]...).Scan(&total); err != nil {
-		return nil, 0, err
+// attributeFilterCondition matches vacancies with a typed detail satisfying
+// the filter. A range matches when it overlaps the filter's bounds.
+func attributeFilterCondition(filter dto.AttributeFilter, argIndex int) (string, []any) {
+	args := []any{filter.Key}
+	var match string
+	switch filter.Type {
+	case model.AttributeTypeBoolean:
+		match = fmt.Sprintf("d.bool_value = $%d", argIndex+1)
+		args = append(args, filter.Bool)
+	case model.AttributeTypeEnum:
+		match = fmt.Sprintf("LOWER(d.value) = ANY($%d)", argIndex+1)
+		args = append(args, pq.Array(filter.Values))
+	default:
+		upper := "d.number_value"
+		if filter.Type == model.AttributeTypeRange {
+			upper = "d.number_to"
+		}
+		match = fmt.Sprintf("($%[1]d::float8 IS NULL OR %[3]s >= $%[1]d) AND ($%[2]d::float8 IS NULL OR d.number_value <= $%[2]d)", argIndex+1, argIndex+2, upper)
+		args = append(args, filter.From, filter.To)
 	}
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM vacancy_details d WHERE d.vacancy_id = vacancies.id AND d.attribute_key = $%d AND %s)", argIndex, match), args
+}
+
+// vacancyOrder orders a vacancy list by a key that is never NULL and then
+// by id in the same direction, so that (key, id) identifies the position
+// of every vacancy for keyset pagination.
+type vacancyOrder struct {
+	key string
+	// keyType is the SQL type of the key, which a cursor stores as text.
+	keyType string
+	desc    bool
+}
 
-	return vacancies, total, nil
+func newVacancyOrder(sort string, q *vacancyListQuery) vacancyOrder {
+	switch {
+	case sort == dto.VacancySortDistance && q.distance != "":
+		return vacancyOrder{key: q.distance, keyType: "float8"}
+	case sort == dto.VacancySortRelevance && q.relevance != "":
+		return vacancyOrder{key: q.relevance, keyType: "float8", desc: true}
+	case sort == dto.VacancySortPublished:
+		return vacancyOrder{key: "COALESCE(published_at, '-infinity'::timestamp)", keyType: "timestamp", desc: true}
+	case sort == dto.VacancySortSalaryAsc:
+		return vacancyOrder{key: "COALESCE((" + salarySortExpression + ")::float8, 'Infinity'::float8)", keyType: "float8"}
+	case sort == dto.VacancySortSalaryDesc:
+		return vacancyOrder{key: "COALESCE((" + salarySortExpression + ")::float8, '-Infinity'::float8)", keyType: "float8", desc: true}
+	case sort == dto.VacancySortTrending:
+		return vacancyOrder{key: "(" + trendingSortExpression + ")::float8", keyType: "float8", desc: true}
+	default:
+		return vacancyOrder{key: "COALESCE(created_at, '-infinity'::timestamp)", keyType: "timestamp", desc: true}
+	}
+}
+
+// after returns the comparison of (key, id) with a cursor that matches the
+// vacancies following it.
+func (o vacancyOrder) after() string {
+	if o.desc {
+		return "<"
+	}
+	return ">"
+}
+
+func (o vacancyOrder) String() string {
+	direction := " ASC"
+	if o.desc {
+		direction = " DESC"
+	}
+	return o.key + direction + ", id" + direction
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Write applies write to a stored vacancy in one transaction.
+func (r *VacancyRepository) Write(ctx context.Context, vacancy *model.Vacancy, write model.VacancyWrite) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return applyVacancyWrite(ctx, tx, vacancy, write)
+	})
+}
+
+// applyVacancyWrite stores the translations, fingerprint and risk
+// assessment of a created or updated vacancy within its transaction.
+func applyVacancyWrite(ctx context.Context, tx *sql.Tx, vacancy *model.Vacancy, write model.VacancyWrite) error {
+	if write.DeleteTranslation != "" {
+		query := `DELETE FROM vacancy_translations WHERE vacancy_id = $1 AND locale = $2`
+		if _, err := tx.ExecContext(ctx, query, vacancy.ID, write.DeleteTranslation); err != nil {
+			return err
+		}
+	}
+	for i := range write.Translations {
+		write.Translations[i].VacancyID = vacancy.ID
+	}
+	if err := upsertVacancyTranslations(ctx, tx, write.Translations); err != nil {
+		return err
+	}
+
+	if write.Fingerprint != nil {
+		write.Fingerprint.VacancyID = vacancy.ID
+		if err := saveFingerprint(ctx, tx, write.Fingerprint, write.ClusterID, write.ClusterMembers); err != nil {
+			return err
+		}
+	}
+
+	if write.Moderation == nil {
+		_, err := tx.ExecContext(ctx, `UPDATE vacancies SET risk_score = $2 WHERE id = $1`, vacancy.ID, write.RiskScore)
+		return err
+	}
+	write.Moderation.VacancyID = vacancy.ID
+	changed, err := recordModeration(ctx, tx, write.Moderation, write.ModerationFrom, write.ModerationTo, nil, nil)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return fmt.Errorf("%w: vacancy changed concurrently", model.ErrInvalidVacancyTransition)
+	}
+	if write.ModerationTo != "" {
+		vacancy.Status = write.ModerationTo
+	}
+	return nil
+}
+
+// rowQuerier is a *sql.DB or a *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// updateVacancy overwrites the editable fields of the vacancy. The owning
+// organization and the status are changed elsewhere.
+func updateVacancy(ctx context.Context, db execer, vacancy *model.Vacancy) error {
+	query := `UPDATE vacancies SET title=$1, description=$2, salary_from=$3, salary_to=$4, salary_exact=$5, salary_type=$6, salary_currency=$7, category_id=$8, country=$9,
+			salary_from_base=monthly_salary_base($3, $6, $7), salary_to_base=monthly_salary_base($4, $6, $7), salary_exact_base=monthly_salary_base($5, $6, $7),
+			country_code=$11, region_id=$12, city_id=$13, latitude=$14, longitude=$15, primary_locale=$16,
+			updated_at=NOW() WHERE id=$10 AND deleted_at IS NULL`
+	result, err := db.ExecContext(ctx, query, vacancy.Title, vacancy.Description, vacancy.SalaryFrom, vacancy.SalaryTo, vacancy.SalaryExact, vacancy.SalaryType, vacancy.SalaryCurrency, vacancy.CategoryID, vacancy.Country, vacancy.ID,
+		nullString(vacancy.CountryCode), vacancy.RegionID, vacancy.CityID, vacancy.Latitude, vacancy.Longitude, vacancy.PrimaryLocale)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return model.ErrVacancyNotFound
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanVacancy scans vacancyColumns followed by any extra selected columns.
+func scanVacancy(row rowScanner, extra ...any) (*model.Vacancy, error) {
+	var v model.Vacancy
+	var country, countryCode sql.NullString
+	dest := []any{&v.ID, &v.Title, &v.Description, &v.PrimaryLocale, &v.SalaryFrom, &v.SalaryTo, &v.SalaryExact, &v.SalaryType, &v.SalaryCurrency, &v.SalaryFromBase, &v.SalaryToBase, &v.SalaryExactBase, &v.OrganizationID, &v.CategoryID, &country, &countryCode, &v.RegionID, &v.CityID, &v.Latitude, &v.Longitude, &v.Status, &v.PublishedAt, &v.ExpiresAt, &v.DeletedAt, &v.RiskScore, &v.DuplicateClusterID, &v.CreatedAt, &v.UpdatedAt, &v.Version}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	v.Country = country.String
+	v.CountryCode = countryCode.String
+	return &v, nil
+}
+
+func scanVacancies(rows *sql.Rows, err error) ([]model.Vacancy, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vacancies []model.Vacancy
+	for rows.Next() {
+		v, err := scanVacancy(rows)
+		if err != nil {
+			return nil, err
+		}
+		vacancies = append(vacancies, *v)
+	}
+	return vacancies, rows.Err()
+}
+
+func nullString(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
 }
 
 func joinConditions(conditions []string, sep string) string {
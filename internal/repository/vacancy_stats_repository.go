@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
+)
+
+type VacancyStatsRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewVacancyStatsRepository(log *slog.Logger, db *sql.DB) *VacancyStatsRepository {
+	return &VacancyStatsRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+// RecordView stores a view of the vacancy by the user. Only the first view
+// per user per day is counted in the daily stats.
+func (r *VacancyStatsRepository) RecordView(ctx context.Context, vacancyID, userID int64) error {
+	query := `WITH inserted AS (
+				INSERT INTO vacancy_views (vacancy_id, user_id) VALUES ($1, $2)
+				ON CONFLICT DO NOTHING
+				RETURNING vacancy_id, viewed_on
+			)
+			INSERT INTO vacancy_daily_stats (vacancy_id, day, views)
+			SELECT vacancy_id, viewed_on, 1 FROM inserted
+			ON CONFLICT (vacancy_id, day) DO UPDATE SET views = vacancy_daily_stats.views + 1`
+	_, err := r.db.ExecContext(ctx, query, vacancyID, userID)
+	return err
+}
+
+// CreateApplication stores the application and counts it in the daily
+// stats, along with the version of the vacancy the user applied to. The
+// resume, when given, must belong to the applicant. A second
+// application by the same user fails with model.ErrAlreadyApplied.
+func (r *VacancyStatsRepository) CreateApplication(ctx context.Context, application *model.VacancyApplication) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// The share lock keeps the vacancy from being edited until the
+		// application is stored with the version checked here.
+		var version int
+		err := tx.QueryRowContext(ctx, `SELECT version FROM vacancies WHERE id = $1 FOR SHARE`, application.VacancyID).Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrVacancyNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if application.VacancyVersion != 0 && application.VacancyVersion != version {
+			return model.ErrVacancyVersionChanged
+		}
+		application.VacancyVersion = version
+
+		query := `INSERT INTO vacancy_applications (vacancy_id, user_id, resume_id, vacancy_version)
+				SELECT $1, $2, $3::int, $4
+				WHERE $3::int IS NULL OR EXISTS (SELECT 1 FROM resumes WHERE id = $3::int AND user_id = $2)
+				RETURNING id, created_at`
+		err = tx.QueryRowContext(ctx, query, application.VacancyID, application.UserID, application.ResumeID, version).
+			Scan(&application.ID, &application.CreatedAt)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return model.ErrAlreadyApplied
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrApplicationResume
+		}
+		if err != nil {
+			return err
+		}
+
+		query = `INSERT INTO vacancy_daily_stats (vacancy_id, day, applications) VALUES ($1, CURRENT_DATE, 1)
+				ON CONFLICT (vacancy_id, day) DO UPDATE SET applications = vacancy_daily_stats.applications + 1`
+		_, err = tx.ExecContext(ctx, query, application.VacancyID)
+		return err
+	})
+}
+
+// Stats sums the views and applications of the vacancy since the given day.
+func (r *VacancyStatsRepository) Stats(ctx context.Context, vacancyID int64, since time.Time) (*model.VacancyStats, error) {
+	query := `SELECT day, views, applications FROM vacancy_daily_stats
+			WHERE vacancy_id = $1 AND day >= $2
+			ORDER BY day`
+	rows, err := r.db.QueryContext(ctx, query, vacancyID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := &model.VacancyStats{}
+	for rows.Next() {
+		var day model.VacancyDailyStats
+		if err := rows.Scan(&day.Day, &day.Views, &day.Applications); err != nil {
+			return nil, err
+		}
+		stats.Views += day.Views
+		stats.Applications += day.Applications
+		stats.Daily = append(stats.Daily, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query = `SELECT COUNT(DISTINCT user_id) FROM vacancy_views WHERE vacancy_id = $1 AND viewed_on >= $2`
+	if err := r.db.QueryRowContext(ctx, query, vacancyID, since).Scan(&stats.UniqueViewers); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
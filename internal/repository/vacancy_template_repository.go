@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+const vacancyTemplateColumns = `t.id, t.organization_id, t.user_id, t.name, t.shared, t.version, v.content, t.created_at, t.updated_at`
+
+// vacancyTemplateFrom joins every template with its latest version.
+const vacancyTemplateFrom = ` FROM vacancy_templates t
+		JOIN vacancy_template_versions v ON v.template_id = t.id AND v.version = t.version`
+
+type VacancyTemplateRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewVacancyTemplateRepository(log *slog.Logger, db *sql.DB) *VacancyTemplateRepository {
+	return &VacancyTemplateRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create stores the template with its content as the first version.
+func (r *VacancyTemplateRepository) Create(ctx context.Context, template *model.VacancyTemplate) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		query := `INSERT INTO vacancy_templates (organization_id, user_id, name, shared)
+				VALUES ($1, $2, $3, $4)
+				RETURNING id, version, created_at, updated_at`
+		err := tx.QueryRowContext(ctx, query, template.OrganizationID, template.UserID, template.Name, template.Shared).
+			Scan(&template.ID, &template.Version, &template.CreatedAt, &template.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return insertVacancyTemplateVersion(ctx, tx, template.ID, template.Version, template.UserID, template.Content)
+	})
+}
+
+// GetByID returns the organization's template or nil when there is none.
+func (r *VacancyTemplateRepository) GetByID(ctx context.Context, id, organizationID int64) (*model.VacancyTemplate, error) {
+	query := `SELECT ` + vacancyTemplateColumns + vacancyTemplateFrom + ` WHERE t.id = $1 AND t.organization_id = $2`
+	template, err := scanVacancyTemplate(r.db.QueryRowContext(ctx, query, id, organizationID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return template, err
+}
+
+// List returns the organization's shared templates and the user's own
+// ones, most recently changed first.
+func (r *VacancyTemplateRepository) List(ctx context.Context, organizationID, userID int64) ([]model.VacancyTemplate, error) {
+	query := `SELECT ` + vacancyTemplateColumns + vacancyTemplateFrom + `
+			WHERE t.organization_id = $1 AND (t.shared OR t.user_id = $2)
+			ORDER BY t.updated_at DESC, t.id DESC`
+	rows, err := r.db.QueryContext(ctx, query, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []model.VacancyTemplate
+	for rows.Next() {
+		template, err := scanVacancyTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	return templates, rows.Err()
+}
+
+// Update saves the name and sharing of the template. With content it also
+// adds the content as a new version saved by userID.
+func (r *VacancyTemplateRepository) Update(ctx context.Context, template *model.VacancyTemplate, userID int64, content []byte) error {
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		query := `UPDATE vacancy_templates
+				SET name = $2, shared = $3, version = version + CASE WHEN $4::boolean THEN 1 ELSE 0 END, updated_at = NOW()
+				WHERE id = $1
+				RETURNING version, updated_at`
+		err := tx.QueryRowContext(ctx, query, template.ID, template.Name, template.Shared, content != nil).
+			Scan(&template.Version, &template.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrVacancyTemplateNotFound
+		}
+		if err != nil || content == nil {
+			return err
+		}
+		template.Content = content
+		return insertVacancyTemplateVersion(ctx, tx, template.ID, template.Version, userID, content)
+	})
+}
+
+// ListVersions returns every version of the template, newest first,
+// without their content.
+func (r *VacancyTemplateRepository) ListVersions(ctx context.Context, templateID int64) ([]model.VacancyTemplateVersion, error) {
+	query := `SELECT template_id, version, user_id, created_at FROM vacancy_template_versions
+			WHERE template_id = $1
+			ORDER BY version DESC`
+	rows, err := r.db.QueryContext(ctx, query, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []model.VacancyTemplateVersion
+	for rows.Next() {
+		var v model.VacancyTemplateVersion
+		if err := rows.Scan(&v.TemplateID, &v.Version, &v.UserID, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// GetVersion returns a version of the template or nil when there is none.
+func (r *VacancyTemplateRepository) GetVersion(ctx context.Context, templateID int64, version int) (*model.VacancyTemplateVersion, error) {
+	query := `SELECT template_id, version, user_id, content, created_at FROM vacancy_template_versions
+			WHERE template_id = $1 AND version = $2`
+	var v model.VacancyTemplateVersion
+	var content []byte
+	err := r.db.QueryRowContext(ctx, query, templateID, version).Scan(&v.TemplateID, &v.Version, &v.UserID, &content, &v.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	v.Content = content
+	return &v, nil
+}
+
+// Delete removes the template with all of its versions.
+func (r *VacancyTemplateRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM vacancy_templates WHERE id = $1`, id)
+	return err
+}
+
+func insertVacancyTemplateVersion(ctx context.Context, tx *sql.Tx, templateID int64, version int, userID int64, content []byte) error {
+	query := `INSERT INTO vacancy_template_versions (template_id, version, user_id, content) VALUES ($1, $2, $3, $4)`
+	_, err := tx.ExecContext(ctx, query, templateID, version, userID, string(content))
+	return err
+}
+
+func scanVacancyTemplate(row rowScanner) (*model.VacancyTemplate, error) {
+	var t model.VacancyTemplate
+	var content []byte
+	err := row.Scan(&t.ID, &t.OrganizationID, &t.UserID, &t.Name, &t.Shared, &t.Version, &content, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.Content = content
+	return &t, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/lib/pq"
+)
+
+const vacancyTranslationColumns = `vacancy_id, locale, title, description, machine, updated_at`
+
+type VacancyTranslationRepository struct {
+	log *slog.Logger
+	db  *sql.DB
+}
+
+func NewVacancyTranslationRepository(log *slog.Logger, db *sql.DB) *VacancyTranslationRepository {
+	return &VacancyTranslationRepository{
+		log: log,
+		db:  db,
+	}
+}
+
+// Upsert stores the translations. Machine drafts never overwrite a
+// translation the employer has edited.
+func (r *VacancyTranslationRepository) Upsert(ctx context.Context, translations []model.VacancyTranslation) error {
+	if len(translations) == 0 {
+		return nil
+	}
+	return withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return upsertVacancyTranslations(ctx, tx, translations)
+	})
+}
+
+func upsertVacancyTranslations(ctx context.Context, tx *sql.Tx, translations []model.VacancyTranslation) error {
+	query := `INSERT INTO vacancy_translations (vacancy_id, locale, title, description, machine)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (vacancy_id, locale) DO UPDATE
+			SET title = EXCLUDED.title, description = EXCLUDED.description, machine = EXCLUDED.machine, updated_at = NOW()
+			WHERE NOT EXCLUDED.machine OR vacancy_translations.machine`
+	for _, t := range translations {
+		if _, err := tx.ExecContext(ctx, query, t.VacancyID, t.Locale, t.Title, t.Description, t.Machine); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes the translation and reports whether it existed.
+func (r *VacancyTranslationRepository) Delete(ctx context.Context, vacancyID int64, locale string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM vacancy_translations WHERE vacancy_id = $1 AND locale = $2`, vacancyID, locale)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *VacancyTranslationRepository) ListByVacancy(ctx context.Context, vacancyID int64) ([]model.VacancyTranslation, error) {
+	query := `SELECT ` + vacancyTranslationColumns + ` FROM vacancy_translations WHERE vacancy_id = $1 ORDER BY locale`
+	return scanVacancyTranslations(r.db.QueryContext(ctx, query, vacancyID))
+}
+
+// FindByVacancies returns the translations of the vacancies into any of
+// locales, keyed by vacancy ID and locale.
+func (r *VacancyTranslationRepository) FindByVacancies(ctx context.Context, vacancyIDs []int64, locales []string) (map[int64]map[string]model.VacancyTranslation, error) {
+	translations := make(map[int64]map[string]model.VacancyTranslation)
+	if len(vacancyIDs) == 0 || len(locales) == 0 {
+		return translations, nil
+	}
+
+	query := `SELECT ` + vacancyTranslationColumns + ` FROM vacancy_translations WHERE vacancy_id = ANY($1) AND locale = ANY($2)`
+	list, err := scanVacancyTranslations(r.db.QueryContext(ctx, query, pq.Array(vacancyIDs), pq.Array(locales)))
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range list {
+		if translations[t.VacancyID] == nil {
+			translations[t.VacancyID] = make(map[string]model.VacancyTranslation)
+		}
+		translations[t.VacancyID][t.Locale] = t
+	}
+	return translations, nil
+}
+
+func scanVacancyTranslations(rows *sql.Rows, err error) ([]model.VacancyTranslation, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var translations []model.VacancyTranslation
+	for rows.Next() {
+		var t model.VacancyTranslation
+		if err := rows.Scan(&t.VacancyID, &t.Locale, &t.Title, &t.Description, &t.Machine, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		translations = append(translations, t)
+	}
+	return translations, rows.Err()
+}
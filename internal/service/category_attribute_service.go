@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// ListCategoryAttributes returns the attribute schema of the category: its
+// own attributes and the ones it inherits from its ancestors.
+func (s *CategoryService) ListCategoryAttributes(ctx context.Context, categoryID int) ([]dto.CategoryAttribute, error) {
+	category, err := s.repo.FindByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, model.ErrCategoryNotFound
+	}
+
+	attributes, err := s.effectiveAttributes(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	result := []dto.CategoryAttribute{}
+	for _, a := range attributes {
+		result = append(result, toCategoryAttributeResponse(a, categoryID))
+	}
+	return result, nil
+}
+
+// UpsertCategoryAttribute defines the attribute key on the category, or
+// replaces its definition there. A key has the same type in every category.
+func (s *CategoryService) UpsertCategoryAttribute(ctx context.Context, categoryID int, key string, req dto.UpsertCategoryAttribute) (*dto.CategoryAttribute, error) {
+	attribute, err := newCategoryAttribute(categoryID, key, req)
+	if err != nil {
+		return nil, err
+	}
+
+	types, err := s.attribute.KeyTypes(ctx, []string{attribute.Key})
+	if err != nil {
+		return nil, err
+	}
+	if existing, ok := types[attribute.Key]; ok && existing != attribute.Type {
+		return nil, fmt.Errorf("%w: %s is already defined as %s", model.ErrInvalidCategoryAttribute, attribute.Key, existing)
+	}
+
+	if err := s.attribute.Upsert(ctx, attribute); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Category attribute saved", slog.Int("category_id", categoryID), slog.String("key", attribute.Key))
+	response := toCategoryAttributeResponse(*attribute, categoryID)
+	return &response, nil
+}
+
+// DeleteCategoryAttribute removes the attribute from the category. Details
+// already stored for it are kept.
+func (s *CategoryService) DeleteCategoryAttribute(ctx context.Context, categoryID int, key string) error {
+	deleted, err := s.attribute.Delete(ctx, categoryID, key)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return model.ErrCategoryAttributeNotFound
+	}
+	return nil
+}
+
+// AttributeSchema returns the attributes of vacancies in the category by
+// key. An attribute redefined on a subcategory replaces the inherited one.
+func (s *CategoryService) AttributeSchema(ctx context.Context, categoryID int) (map[string]model.CategoryAttribute, error) {
+	attributes, err := s.effectiveAttributes(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	schema := make(map[string]model.CategoryAttribute, len(attributes))
+	for _, a := range attributes {
+		schema[a.Key] = a
+	}
+	return schema, nil
+}
+
+// AttributeTypes returns the type of each of the keys that is defined on
+// any category.
+func (s *CategoryService) AttributeTypes(ctx context.Context, keys []string) (map[string]string, error) {
+	return s.attribute.KeyTypes(ctx, keys)
+}
+
+// effectiveAttributes returns the schema of the category ordered from the
+// top-level category down, keeping the deepest definition of every key.
+func (s *CategoryService) effectiveAttributes(ctx context.Context, categoryID int) ([]model.CategoryAttribute, error) {
+	attributes, err := s.attribute.FindForCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	position := make(map[string]int, len(attributes))
+	var result []model.CategoryAttribute
+	for _, a := range attributes {
+		if i, ok := position[a.Key]; ok {
+			result[i] = a
+			continue
+		}
+		position[a.Key] = len(result)
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+// newCategoryAttribute validates an attribute definition. Only enums have
+// options and only numbers and ranges have bounds.
+func newCategoryAttribute(categoryID int, key string, req dto.UpsertCategoryAttribute) (*model.CategoryAttribute, error) {
+	if !attributeKeyPattern.MatchString(key) {
+		return nil, fmt.Errorf("%w: key must be lowercase letters, digits and underscores", model.ErrInvalidCategoryAttribute)
+	}
+	attribute := &model.CategoryAttribute{
+		CategoryID: categoryID,
+		Key:        key,
+		Name:       strings.TrimSpace(req.Name),
+		Type:       req.Type,
+		Options:    []string{},
+		Unit:       strings.TrimSpace(req.Unit),
+		Required:   req.Required,
+	}
+	if attribute.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", model.ErrInvalidCategoryAttribute)
+	}
+
+	switch req.Type {
+	case model.AttributeTypeBoolean:
+	case model.AttributeTypeEnum:
+		for _, option := range req.Options {
+			option = strings.TrimSpace(option)
+			if option == "" {
+				return nil, fmt.Errorf("%w: options cannot be empty", model.ErrInvalidCategoryAttribute)
+			}
+			if slices.Contains(attribute.Options, option) {
+				return nil, fmt.Errorf("%w: option %q is listed twice", model.ErrInvalidCategoryAttribute, option)
+			}
+			attribute.Options = append(attribute.Options, option)
+		}
+		if len(attribute.Options) == 0 {
+			return nil, fmt.Errorf("%w: an enum needs options", model.ErrInvalidCategoryAttribute)
+		}
+	case model.AttributeTypeNumber, model.AttributeTypeRange:
+		if req.Min != nil && req.Max != nil && *req.Min > *req.Max {
+			return nil, fmt.Errorf("%w: min is greater than max", model.ErrInvalidCategoryAttribute)
+		}
+		attribute.Min, attribute.Max = req.Min, req.Max
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", model.ErrInvalidCategoryAttribute, req.Type)
+	}
+	return attribute, nil
+}
+
+func toCategoryAttributeResponse(a model.CategoryAttribute, categoryID int) dto.CategoryAttribute {
+	return dto.CategoryAttribute{
+		Key:        a.Key,
+		Name:       a.Name,
+		Type:       a.Type,
+		Options:    a.Options,
+		Unit:       a.Unit,
+		Min:        a.Min,
+		Max:        a.Max,
+		Required:   a.Required,
+		CategoryID: a.CategoryID,
+		Inherited:  a.CategoryID != categoryID,
+	}
+}
@@ -3,115 +3,508 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/lib"
 	"github.com/aidosgal/alem.core-service/internal/model"
 	"github.com/aidosgal/alem.core-service/internal/repository"
 )
 
+// categoryCountsTTL bounds how stale the per-category vacancy and resume
+// counts may get, since they change without touching the category tree.
+const categoryCountsTTL = 5 * time.Minute
+
+// categoryTreeTTL bounds how stale the cached tree may get after a change
+// made elsewhere: by another instance or by cmd/categories. Changes made
+// through this service clear the cache right away.
+const categoryTreeTTL = time.Minute
+
 type CategoryService struct {
-    log  *slog.Logger
-	repo *repository.CategoryRepository
+	log       *slog.Logger
+	repo      *repository.CategoryRepository
+	attribute *repository.CategoryAttributeRepository
+	vacancy   *repository.VacancyRepository
+	resume    *repository.ResumeRepository
+
+	cacheMu        sync.RWMutex
+	cachedTree     *categorySnapshot
+	treeCachedAt   time.Time
+	cachedVacancy  map[int]int
+	cachedResume   map[int]int
+	countsCachedAt time.Time
 }
 
 func NewCategoryService(
 	repo *repository.CategoryRepository,
+	attribute *repository.CategoryAttributeRepository,
+	vacancy *repository.VacancyRepository,
+	resume *repository.ResumeRepository,
 	log *slog.Logger,
 ) *CategoryService {
-    return &CategoryService{repo: repo, log: log}
+	return &CategoryService{repo: repo, attribute: attribute, vacancy: vacancy, resume: resume, log: log}
 }
 
 func (s *CategoryService) CreateCategory(ctx context.Context, req dto.CreateCategory) (*dto.CategoryResponse, error) {
-	var left, right, depth int
-	if req.ParentID != nil {
-		parent, err := s.repo.FindByID(ctx, *req.ParentID)
-		if err != nil || parent == nil {
-			return nil, errors.New("invalid parent ID")
-		}
-		left = parent.Right
-		right = parent.Right + 1
-		depth = parent.Depth + 1
-	} else {
-		left = 1
-		right = 2
-		depth = 1
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("category name cannot be empty")
 	}
 
 	category := &model.Category{
-		Name:     req.Name,
+		Name:     name,
 		ParentID: req.ParentID,
-		Left:     left,
-		Right:    right,
-		Depth:    depth,
 	}
 
-	err := s.repo.Insert(ctx, category)
-	if err != nil {
+	if err := s.repo.Insert(ctx, category); err != nil {
 		return nil, err
 	}
+	s.invalidateCache()
 
-	return &dto.CategoryResponse{
-		ID:       category.ID,
-		Name:     category.Name,
-		ParentID: category.ParentID,
-		Left:     category.Left,
-		Right:    category.Right,
-		Depth:    category.Depth,
-	}, nil
+	response := toCategoryResponse(*category)
+	return &response, nil
 }
 
 func (s *CategoryService) GetCategoryByID(ctx context.Context, id int) (*dto.CategoryResponse, error) {
-	category, err := s.repo.FindByID(ctx, id)
-	if err != nil || category == nil {
-		return nil, errors.New("category not found")
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	category, ok := snapshot.find(id)
+	if !ok {
+		return nil, model.ErrCategoryNotFound
+	}
+	response := toCategoryResponse(snapshot.localize(ctx, category))
+	return &response, nil
+}
+
+// GetCategoriesByIDs returns the given categories keyed by ID from a
+// single snapshot of the tree. Missing categories are left out.
+func (s *CategoryService) GetCategoriesByIDs(ctx context.Context, ids []int) (map[int]dto.CategoryResponse, error) {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int]dto.CategoryResponse, len(ids))
+	for _, id := range ids {
+		if category, ok := snapshot.find(id); ok {
+			result[id] = toCategoryResponse(snapshot.localize(ctx, category))
+		}
 	}
-	return &dto.CategoryResponse{
-		ID:       category.ID,
-		Name:     category.Name,
-		ParentID: category.ParentID,
-		Left:     category.Left,
-		Right:    category.Right,
-		Depth:    category.Depth,
-	}, nil
+	return result, nil
 }
 
+// UpdateCategory renames the category and, when the request has a parent
+// other than the current one, moves its subtree to the end of the new
+// parent's children. Without parent_id the category stays where it is.
 func (s *CategoryService) UpdateCategory(ctx context.Context, req dto.UpdateCategory) error {
+	defer s.invalidateCache()
+
 	category, err := s.repo.FindByID(ctx, req.ID)
-	if err != nil || category == nil {
-		return errors.New("category not found")
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return model.ErrCategoryNotFound
 	}
 
-	category.Name = req.Name
-
+	if name := strings.TrimSpace(req.Name); name != "" {
+		category.Name = name
+	}
+	if req.Move {
+		return s.repo.UpdateAndMove(ctx, category, req.ParentID)
+	}
 	return s.repo.Update(ctx, category)
 }
 
-func (s *CategoryService) DeleteCategory(ctx context.Context, id int) error {
-	category, err := s.repo.FindByID(ctx, id)
-	if err != nil || category == nil {
-		return errors.New("category not found")
+func (s *CategoryService) MoveCategory(ctx context.Context, id int, req dto.MoveCategory) error {
+	defer s.invalidateCache()
+
+	position := -1
+	if req.Position != nil {
+		position = *req.Position
+	}
+	return s.repo.Move(ctx, id, req.ParentID, position)
+}
+
+func (s *CategoryService) ReorderCategories(ctx context.Context, req dto.ReorderCategories) error {
+	if len(req.IDs) == 0 {
+		return errors.New("ids cannot be empty")
 	}
+	defer s.invalidateCache()
+	return s.repo.Reorder(ctx, req.ParentID, req.IDs)
+}
+
+// DeleteCategory removes the category. With cascade the whole subtree goes
+// with it, otherwise its children are re-attached to its parent. Categories
+// that vacancies or resumes still use are kept and fail with
+// model.ErrCategoryInUse.
+func (s *CategoryService) DeleteCategory(ctx context.Context, id int, cascade bool) error {
+	defer s.invalidateCache()
 
-	return s.repo.Delete(ctx, id)
+	if cascade {
+		return s.repo.Delete(ctx, id)
+	}
+	return s.repo.DeleteAndReparent(ctx, id)
 }
 
 func (s *CategoryService) GetCategoryTree(ctx context.Context) ([]dto.CategoryResponse, error) {
-	categories, err := s.repo.FindAll(ctx)
+	snapshot, err := s.snapshot(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	var result []dto.CategoryResponse
-	for _, c := range categories {
-		result = append(result, dto.CategoryResponse{
+	for _, c := range snapshot.categories {
+		result = append(result, toCategoryResponse(snapshot.localize(ctx, c)))
+	}
+	return result, nil
+}
+
+// GetCategoryNestedTree returns the top-level categories with their
+// descendants nested inside. With withCounts every node also carries the
+// number of vacancies and resumes in its whole subtree.
+func (s *CategoryService) GetCategoryNestedTree(ctx context.Context, withCounts bool) ([]dto.CategoryNode, error) {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var vacancyCounts, resumeCounts map[int]int
+	if withCounts {
+		if vacancyCounts, resumeCounts, err = s.counts(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	children := make(map[int][]model.Category)
+	var roots []model.Category
+	for _, c := range snapshot.categories {
+		c = snapshot.localize(ctx, c)
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var build func(c model.Category) dto.CategoryNode
+	build = func(c model.Category) dto.CategoryNode {
+		node := dto.CategoryNode{
 			ID:       c.ID,
 			Name:     c.Name,
 			ParentID: c.ParentID,
-			Left:     c.Left,
-			Right:    c.Right,
 			Depth:    c.Depth,
-		})
+			Children: []dto.CategoryNode{},
+		}
+		vacancies, resumes := vacancyCounts[c.ID], resumeCounts[c.ID]
+		for _, child := range children[c.ID] {
+			childNode := build(child)
+			if withCounts {
+				vacancies += *childNode.VacancyCount
+				resumes += *childNode.ResumeCount
+			}
+			node.Children = append(node.Children, childNode)
+		}
+		if withCounts {
+			node.VacancyCount = &vacancies
+			node.ResumeCount = &resumes
+		}
+		return node
+	}
+
+	result := make([]dto.CategoryNode, 0, len(roots))
+	for _, root := range roots {
+		result = append(result, build(root))
 	}
 	return result, nil
 }
+
+// GetCategoryAncestors returns the path from the top-level category down to
+// the requested one, inclusive, for use as a breadcrumb.
+func (s *CategoryService) GetCategoryAncestors(ctx context.Context, id int) ([]dto.CategoryResponse, error) {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target, ok := snapshot.find(id)
+	if !ok {
+		return nil, model.ErrCategoryNotFound
+	}
+
+	var result []dto.CategoryResponse
+	for _, c := range snapshot.categories {
+		if c.Left <= target.Left && c.Right >= target.Right {
+			result = append(result, toCategoryResponse(snapshot.localize(ctx, c)))
+		}
+	}
+	return result, nil
+}
+
+// categorySnapshot is the cached state of the category tree.
+type categorySnapshot struct {
+	categories   []model.Category // ordered by lft
+	translations map[int]map[string]string
+}
+
+func (c *categorySnapshot) find(id int) (model.Category, bool) {
+	for _, category := range c.categories {
+		if category.ID == id {
+			return category, true
+		}
+	}
+	return model.Category{}, false
+}
+
+// localize returns the category with its name in the best locale preferred
+// by the request, falling back to the default name.
+func (c *categorySnapshot) localize(ctx context.Context, category model.Category) model.Category {
+	category.Name = lib.Localize(ctx, c.translations[category.ID], category.Name)
+	return category
+}
+
+// snapshot returns every category with its translations, served from the
+// cache until the tree or a translation is modified, or for at most
+// categoryTreeTTL.
+func (s *CategoryService) snapshot(ctx context.Context) (*categorySnapshot, error) {
+	s.cacheMu.RLock()
+	cached := s.cachedTree
+	fresh := time.Since(s.treeCachedAt) < categoryTreeTTL
+	s.cacheMu.RUnlock()
+	if cached != nil && fresh {
+		return cached, nil
+	}
+
+	categories, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.repo.FindTranslations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &categorySnapshot{categories: categories, translations: translations}
+
+	s.cacheMu.Lock()
+	s.cachedTree = snapshot
+	s.treeCachedAt = time.Now()
+	s.cacheMu.Unlock()
+	return snapshot, nil
+}
+
+func (s *CategoryService) counts(ctx context.Context) (map[int]int, map[int]int, error) {
+	s.cacheMu.RLock()
+	vacancyCounts, resumeCounts := s.cachedVacancy, s.cachedResume
+	fresh := time.Since(s.countsCachedAt) < categoryCountsTTL
+	s.cacheMu.RUnlock()
+	if vacancyCounts != nil && fresh {
+		return vacancyCounts, resumeCounts, nil
+	}
+
+	vacancyCounts, err := s.vacancy.CountByCategory(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	resumeCounts, err = s.resume.CountByCategory(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.cacheMu.Lock()
+	s.cachedVacancy = vacancyCounts
+	s.cachedResume = resumeCounts
+	s.countsCachedAt = time.Now()
+	s.cacheMu.Unlock()
+	return vacancyCounts, resumeCounts, nil
+}
+
+func (s *CategoryService) invalidateCache() {
+	s.cacheMu.Lock()
+	s.cachedTree = nil
+	s.cachedVacancy = nil
+	s.cachedResume = nil
+	s.cacheMu.Unlock()
+}
+
+// CheckCategoryTree validates the stored nested set and returns a description
+// of every inconsistency found. An empty result means the tree is healthy.
+func (s *CategoryService) CheckCategoryTree(ctx context.Context) ([]string, error) {
+	categories, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return checkNestedSet(categories), nil
+}
+
+// RebuildCategoryTree recomputes lft, rgt and depth from parent_id, keeping
+// the current sibling order. Categories whose parent is missing or forms a
+// cycle are promoted to the top level.
+func (s *CategoryService) RebuildCategoryTree(ctx context.Context) error {
+	categories, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	rebuilt := rebuildNestedSet(categories)
+	if err := s.repo.Rebuild(ctx, rebuilt); err != nil {
+		return err
+	}
+	s.invalidateCache()
+
+	s.log.Info("Category tree rebuilt", slog.Int("count", len(rebuilt)))
+	return nil
+}
+
+func toCategoryResponse(c model.Category) dto.CategoryResponse {
+	return dto.CategoryResponse{
+		ID:       c.ID,
+		Name:     c.Name,
+		ParentID: c.ParentID,
+		Left:     c.Left,
+		Right:    c.Right,
+		Depth:    c.Depth,
+	}
+}
+
+// checkNestedSet expects categories ordered by lft.
+func checkNestedSet(categories []model.Category) []string {
+	var issues []string
+
+	seen := make(map[int]int, len(categories)*2)
+	for _, c := range categories {
+		if c.Left >= c.Right {
+			issues = append(issues, fmt.Sprintf("category %d: lft %d is not less than rgt %d", c.ID, c.Left, c.Right))
+		}
+		for _, boundary := range []int{c.Left, c.Right} {
+			if other, ok := seen[boundary]; ok {
+				issues = append(issues, fmt.Sprintf("category %d: boundary %d is already used by category %d", c.ID, boundary, other))
+			}
+			seen[boundary] = c.ID
+		}
+	}
+	for boundary := 1; boundary <= len(categories)*2; boundary++ {
+		if _, ok := seen[boundary]; !ok {
+			issues = append(issues, fmt.Sprintf("boundary %d is missing", boundary))
+		}
+	}
+
+	var stack []model.Category
+	for _, c := range categories {
+		for len(stack) > 0 && stack[len(stack)-1].Right < c.Left {
+			stack = stack[:len(stack)-1]
+		}
+
+		var expectedParent *int
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			if c.Right > parent.Right {
+				issues = append(issues, fmt.Sprintf("category %d overlaps category %d", c.ID, parent.ID))
+			}
+			expectedParent = &parent.ID
+		}
+
+		if !model.SameCategoryParent(c.ParentID, expectedParent) {
+			issues = append(issues, fmt.Sprintf("category %d: parent_id %s does not match its position under %s", c.ID, formatCategoryID(c.ParentID), formatCategoryID(expectedParent)))
+		}
+		if c.Depth != len(stack) {
+			issues = append(issues, fmt.Sprintf("category %d: depth %d, expected %d", c.ID, c.Depth, len(stack)))
+		}
+
+		stack = append(stack, c)
+	}
+
+	return issues
+}
+
+// rebuildNestedSet expects categories ordered by lft and returns them with
+// fresh boundaries assigned by a depth-first walk over parent_id.
+func rebuildNestedSet(categories []model.Category) []model.Category {
+	byID := make(map[int]*model.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	// A category is promoted to the top level when its parent is missing or
+	// when it sits on a parent_id cycle.
+	detached := func(c *model.Category) bool {
+		visited := map[int]bool{c.ID: true}
+		for current := c; current.ParentID != nil; {
+			parent, ok := byID[*current.ParentID]
+			if !ok {
+				return current == c
+			}
+			if parent.ID == c.ID {
+				return true
+			}
+			if visited[parent.ID] {
+				return false
+			}
+			visited[parent.ID] = true
+			current = parent
+		}
+		return false
+	}
+
+	promoted := make(map[int]bool)
+	for i := range categories {
+		if detached(&categories[i]) {
+			promoted[categories[i].ID] = true
+		}
+	}
+
+	children := make(map[int][]*model.Category)
+	var roots []*model.Category
+	for i := range categories {
+		c := &categories[i]
+		if c.ParentID == nil || promoted[c.ID] {
+			c.ParentID = nil
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	// Keep siblings in their current order; corrupted boundaries fall back to id.
+	order := func(list []*model.Category) {
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Left != list[j].Left {
+				return list[i].Left < list[j].Left
+			}
+			return list[i].ID < list[j].ID
+		})
+	}
+
+	counter := 0
+	var walk func(c *model.Category, depth int)
+	walk = func(c *model.Category, depth int) {
+		counter++
+		c.Left = counter
+		c.Depth = depth
+		order(children[c.ID])
+		for _, child := range children[c.ID] {
+			walk(child, depth+1)
+		}
+		counter++
+		c.Right = counter
+	}
+
+	order(roots)
+	for _, root := range roots {
+		walk(root, 0)
+	}
+
+	return categories
+}
+
+func formatCategoryID(id *int) string {
+	if id == nil {
+		return "root"
+	}
+	return fmt.Sprint(*id)
+}
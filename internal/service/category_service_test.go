@@ -0,0 +1,95 @@
+package service
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+func intPtr(i int) *int { return &i }
+
+// nestedSetCategories is the tree
+//
+//	1
+//	├── 2
+//	│   └── 3
+//	└── 4
+//	5
+//	└── 6
+func nestedSetCategories() []model.Category {
+	return []model.Category{
+		{ID: 1, Left: 1, Right: 8},
+		{ID: 2, ParentID: intPtr(1), Left: 2, Right: 5, Depth: 1},
+		{ID: 3, ParentID: intPtr(2), Left: 3, Right: 4, Depth: 2},
+		{ID: 4, ParentID: intPtr(1), Left: 6, Right: 7, Depth: 1},
+		{ID: 5, Left: 9, Right: 12},
+		{ID: 6, ParentID: intPtr(5), Left: 10, Right: 11, Depth: 1},
+	}
+}
+
+func TestCheckNestedSet(t *testing.T) {
+	if issues := checkNestedSet(nestedSetCategories()); len(issues) != 0 {
+		t.Fatalf("healthy tree has issues: %v", issues)
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func(categories []model.Category)
+	}{
+		{"lft not less than rgt", func(c []model.Category) { c[3].Left, c[3].Right = 7, 6 }},
+		{"duplicate boundary", func(c []model.Category) { c[5].Right = 12 }},
+		{"wrong parent", func(c []model.Category) { c[2].ParentID = intPtr(1) }},
+		{"wrong depth", func(c []model.Category) { c[5].Depth = 0 }},
+		{"overlap", func(c []model.Category) { c[1].Right = 9; c[4].Left = 5 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			categories := nestedSetCategories()
+			tt.corrupt(categories)
+			if issues := checkNestedSet(categories); len(issues) == 0 {
+				t.Fatal("corrupted tree has no issues")
+			}
+		})
+	}
+}
+
+func TestRebuildNestedSet(t *testing.T) {
+	// Boundaries and depths are garbage, 3 and 4 sit on a parent_id cycle
+	// and 6 points at a missing parent, so all three become top level.
+	categories := []model.Category{
+		{ID: 1, Left: 1, Right: 3},
+		{ID: 2, ParentID: intPtr(1), Left: 2, Right: 2, Depth: 4},
+		{ID: 3, ParentID: intPtr(4), Left: 5, Right: 20, Depth: 1},
+		{ID: 4, ParentID: intPtr(3), Left: 6, Right: 7},
+		{ID: 5, ParentID: intPtr(2), Left: 8, Right: 9, Depth: 1},
+		{ID: 6, ParentID: intPtr(99), Left: 10, Right: 11, Depth: 1},
+	}
+	if issues := checkNestedSet(categories); len(issues) == 0 {
+		t.Fatal("corrupted tree has no issues")
+	}
+
+	rebuilt := rebuildNestedSet(categories)
+	sort.Slice(rebuilt, func(i, j int) bool { return rebuilt[i].Left < rebuilt[j].Left })
+	if issues := checkNestedSet(rebuilt); len(issues) != 0 {
+		t.Fatalf("rebuilt tree has issues: %v", issues)
+	}
+
+	want := map[int][4]int{
+		1: {0, 1, 6, 0},
+		2: {1, 2, 5, 1},
+		5: {2, 3, 4, 2},
+		3: {0, 7, 8, 0},
+		4: {0, 9, 10, 0},
+		6: {0, 11, 12, 0},
+	}
+	for _, c := range rebuilt {
+		parent := 0
+		if c.ParentID != nil {
+			parent = *c.ParentID
+		}
+		if got := [4]int{parent, c.Left, c.Right, c.Depth}; got != want[c.ID] {
+			t.Errorf("category %d is %v, want %v", c.ID, got, want[c.ID])
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+// ListCategoryTranslations returns the category name in every locale it is
+// available in, starting with the default one.
+func (s *CategoryService) ListCategoryTranslations(ctx context.Context, id int) ([]dto.CategoryTranslation, error) {
+	category, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, model.ErrCategoryNotFound
+	}
+
+	translations, err := s.repo.FindTranslationsByCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.CategoryTranslation{{Locale: lib.DefaultLocale, Name: category.Name}}
+	for _, t := range translations {
+		if t.Locale == lib.DefaultLocale {
+			continue
+		}
+		result = append(result, dto.CategoryTranslation{Locale: t.Locale, Name: t.Name})
+	}
+	return result, nil
+}
+
+// UpsertCategoryTranslation sets the category name for a locale. The default
+// locale is stored on the category itself, so setting it renames the category.
+func (s *CategoryService) UpsertCategoryTranslation(ctx context.Context, id int, locale string, req dto.UpsertCategoryTranslation) error {
+	if !lib.IsSupportedLocale(locale) {
+		return model.ErrUnsupportedLocale
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("translation name cannot be empty")
+	}
+
+	category, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return model.ErrCategoryNotFound
+	}
+	defer s.invalidateCache()
+
+	if locale == lib.DefaultLocale {
+		category.Name = name
+		return s.repo.Update(ctx, category)
+	}
+	return s.repo.UpsertTranslations(ctx, []model.CategoryTranslation{{CategoryID: id, Locale: locale, Name: name}})
+}
+
+func (s *CategoryService) DeleteCategoryTranslation(ctx context.Context, id int, locale string) error {
+	if !lib.IsSupportedLocale(locale) {
+		return model.ErrUnsupportedLocale
+	}
+	if locale == lib.DefaultLocale {
+		return errors.New("the default locale name cannot be deleted")
+	}
+	defer s.invalidateCache()
+	return s.repo.DeleteTranslation(ctx, id, locale)
+}
+
+// ExportCategories returns the whole tree with the names in every locale.
+func (s *CategoryService) ExportCategories(ctx context.Context) ([]dto.CategoryExportNode, error) {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int][]model.Category)
+	var roots []model.Category
+	for _, c := range snapshot.categories {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var build func(c model.Category) dto.CategoryExportNode
+	build = func(c model.Category) dto.CategoryExportNode {
+		node := dto.CategoryExportNode{
+			ID:           c.ID,
+			Name:         c.Name,
+			Translations: snapshot.allNames(c),
+		}
+		for _, child := range children[c.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	result := make([]dto.CategoryExportNode, 0, len(roots))
+	for _, root := range roots {
+		result = append(result, build(root))
+	}
+	return result, nil
+}
+
+// ExportCategoriesCSV writes the tree in lft order as id, parent_id, depth and
+// one name column per supported locale.
+func (s *CategoryService) ExportCategoriesCSV(ctx context.Context, w io.Writer) error {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(categoryCSVHeader()); err != nil {
+		return err
+	}
+
+	for _, c := range snapshot.categories {
+		parentID := ""
+		if c.ParentID != nil {
+			parentID = strconv.Itoa(*c.ParentID)
+		}
+		record := []string{strconv.Itoa(c.ID), parentID, strconv.Itoa(c.Depth)}
+		names := snapshot.allNames(c)
+		for _, locale := range lib.SupportedLocales {
+			record = append(record, names[locale])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportCategories applies an exported tree: existing nodes are renamed and
+// get their translations replaced, nodes without an ID are created under
+// their parent in the given order. Every node is checked before anything is
+// written and the import is applied in one transaction.
+func (s *CategoryService) ImportCategories(ctx context.Context, nodes []dto.CategoryExportNode) (*dto.CategoryImportResult, error) {
+	var imports []*model.CategoryImport
+
+	var add func(node dto.CategoryExportNode, parent *model.CategoryImport) error
+	add = func(node dto.CategoryExportNode, parent *model.CategoryImport) error {
+		entry, err := newCategoryImport(node.ID, nil, node.Name, node.Translations)
+		if err != nil {
+			return err
+		}
+		if parent != nil && parent.ID == 0 {
+			entry.Parent = parent
+		} else if parent != nil {
+			entry.ParentID = &parent.ID
+		}
+		imports = append(imports, entry)
+
+		for _, child := range node.Children {
+			if err := add(child, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, node := range nodes {
+		if err := add(node, nil); err != nil {
+			return nil, err
+		}
+	}
+	return s.importCategories(ctx, imports)
+}
+
+// ImportCategoriesCSV applies a file in the ExportCategoriesCSV format. Rows
+// without an id are created under parent_id, which must already exist. The
+// whole file is read and checked before anything is written.
+func (s *CategoryService) ImportCategoriesCSV(ctx context.Context, r io.Reader) (*dto.CategoryImportResult, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, errors.New("csv header must contain an id column")
+	}
+
+	var imports []*model.CategoryImport
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		id, _ := strconv.Atoi(field("id"))
+		var parentID *int
+		if value := field("parent_id"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid parent_id %q", line, value)
+			}
+			parentID = &parsed
+		}
+
+		names := make(map[string]string)
+		for _, locale := range lib.SupportedLocales {
+			if name := field(locale); name != "" {
+				names[locale] = name
+			}
+		}
+
+		entry, err := newCategoryImport(id, parentID, "", names)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		imports = append(imports, entry)
+	}
+	return s.importCategories(ctx, imports)
+}
+
+func (s *CategoryService) importCategories(ctx context.Context, imports []*model.CategoryImport) (*dto.CategoryImportResult, error) {
+	defer s.invalidateCache()
+
+	created, updated, err := s.repo.Import(ctx, imports)
+	if err != nil {
+		return nil, err
+	}
+	result := &dto.CategoryImportResult{Created: created, Updated: updated}
+	for _, entry := range imports {
+		result.Translations += len(entry.Translations)
+	}
+	return result, nil
+}
+
+// newCategoryImport checks an imported category. The default locale name in
+// names wins over name, and new categories need one.
+func newCategoryImport(id int, parentID *int, name string, names map[string]string) (*model.CategoryImport, error) {
+	if defaultName := names[lib.DefaultLocale]; defaultName != "" {
+		name = defaultName
+	}
+	name = strings.TrimSpace(name)
+	for locale := range names {
+		if !lib.IsSupportedLocale(locale) {
+			return nil, fmt.Errorf("%w: %s", model.ErrUnsupportedLocale, locale)
+		}
+	}
+	if id == 0 && name == "" {
+		return nil, errors.New("new categories need a name")
+	}
+
+	return &model.CategoryImport{
+		ID:           id,
+		Name:         name,
+		ParentID:     parentID,
+		Translations: importedTranslations(id, names),
+	}, nil
+}
+
+func importedTranslations(categoryID int, names map[string]string) []model.CategoryTranslation {
+	var translations []model.CategoryTranslation
+	for locale, name := range names {
+		name = strings.TrimSpace(name)
+		if locale == lib.DefaultLocale || name == "" {
+			continue
+		}
+		translations = append(translations, model.CategoryTranslation{CategoryID: categoryID, Locale: locale, Name: name})
+	}
+	return translations
+}
+
+// allNames returns the names of a category in every locale, including the
+// default one.
+func (c *categorySnapshot) allNames(category model.Category) map[string]string {
+	names := map[string]string{lib.DefaultLocale: category.Name}
+	for locale, name := range c.translations[category.ID] {
+		if locale != lib.DefaultLocale {
+			names[locale] = name
+		}
+	}
+	return names
+}
+
+func categoryCSVHeader() []string {
+	return append([]string{"id", "parent_id", "depth"}, lib.SupportedLocales...)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/repository"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// CurrencyService maintains the exchange rates used to normalize vacancy
+// salaries into model.BaseCurrency.
+type CurrencyService struct {
+	log  *slog.Logger
+	repo *repository.CurrencyRepository
+}
+
+func NewCurrencyService(log *slog.Logger, repo *repository.CurrencyRepository) *CurrencyService {
+	return &CurrencyService{
+		log:  log,
+		repo: repo,
+	}
+}
+
+func (s *CurrencyService) ListCurrencies(ctx context.Context) (*dto.ListCurrenciesResponse, error) {
+	currencies, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ListCurrenciesResponse{Base: model.BaseCurrency, Currencies: []dto.Currency{}}
+	for _, c := range currencies {
+		response.Currencies = append(response.Currencies, toCurrencyResponse(c))
+	}
+	return response, nil
+}
+
+// UpsertCurrency creates or updates a currency and renormalizes the salaries
+// of vacancies paid in it. The base currency's rate is fixed at 1.
+func (s *CurrencyService) UpsertCurrency(ctx context.Context, code string, req dto.UpsertCurrency) (*dto.Currency, error) {
+	code = NormalizeCurrencyCode(code)
+	if !currencyCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("%w: %q", model.ErrUnsupportedCurrency, code)
+	}
+	if req.Rate <= 0 || (code == model.BaseCurrency && req.Rate != 1) {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidCurrencyRate, req.Rate)
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = code
+	}
+
+	currency := &model.Currency{Code: code, Name: name, Rate: req.Rate}
+	if err := s.repo.Upsert(ctx, currency); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Currency rate updated", slog.String("code", code), slog.Float64("rate", req.Rate))
+	response := toCurrencyResponse(*currency)
+	return &response, nil
+}
+
+// Find returns the currency or model.ErrUnsupportedCurrency when it is not
+// known.
+func (s *CurrencyService) Find(ctx context.Context, code string) (*model.Currency, error) {
+	code = NormalizeCurrencyCode(code)
+	currency, err := s.repo.FindByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if currency == nil {
+		return nil, fmt.Errorf("%w: %q", model.ErrUnsupportedCurrency, code)
+	}
+	return currency, nil
+}
+
+func NormalizeCurrencyCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func toCurrencyResponse(c model.Currency) dto.Currency {
+	return dto.Currency{
+		Code:      c.Code,
+		Name:      c.Name,
+		Rate:      c.Rate,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aidosgal/alem.core-service/internal/config"
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/repository"
+)
+
+const (
+	// feedCacheSize bounds how many distinct feeds (one per since bucket and
+	// locale) are cached at once.
+	feedCacheSize = 64
+	// feedSinceBucket is how coarsely since is rounded down, so that
+	// aggregators polling with their own timestamps share cached feeds.
+	feedSinceBucket = time.Hour
+)
+
+// FeedService publishes live vacancies for job aggregators and search
+// engines.
+type FeedService struct {
+	log          *slog.Logger
+	cfg          config.FeedConfig
+	vacancy      *repository.VacancyRepository
+	organization *OrganizationService
+	category     *CategoryService
+	location     *LocationService
+
+	mu       sync.Mutex
+	cache    map[string]cachedFeed
+	building map[string]*feedBuild
+}
+
+// cachedFeed is a rendered XML feed and when it was built.
+type cachedFeed struct {
+	body    []byte
+	builtAt time.Time
+}
+
+// feedBuild is a feed being built. Requests for the same feed wait for done
+// instead of building it again.
+type feedBuild struct {
+	done chan struct{}
+	feed cachedFeed
+	err  error
+}
+
+func NewFeedService(log *slog.Logger, cfg config.FeedConfig, vacancy *repository.VacancyRepository, organization *OrganizationService, category *CategoryService, location *LocationService) *FeedService {
+	return &FeedService{
+		log:          log,
+		cfg:          cfg,
+		vacancy:      vacancy,
+		organization: organization,
+		category:     category,
+		location:     location,
+		cache:        make(map[string]cachedFeed),
+		building:     make(map[string]*feedBuild),
+	}
+}
+
+// JobFeed returns the XML feed of published vacancies and when it was
+// built. With since set it only lists vacancies changed after it, rounded
+// down to the hour, oldest change first. A feed cut at the configured
+// maximum carries the since and after values that continue it from its last
+// vacancy; since is not rounded when after is set. Feeds are cached for the
+// configured TTL; category names follow the preferred supported locale of
+// the request.
+func (s *FeedService) JobFeed(ctx context.Context, since *time.Time, after *int64) ([]byte, time.Time, error) {
+	locale := lib.LocalesFromContext(ctx)[0]
+	if !lib.IsSupportedLocale(locale) {
+		locale = lib.DefaultLocale
+	}
+	key := locale
+	switch {
+	case since != nil && after != nil:
+		exact := since.UTC()
+		since = &exact
+		key += "|" + exact.Format(time.RFC3339Nano) + "|" + strconv.FormatInt(*after, 10)
+	case since != nil:
+		bucket := since.UTC().Truncate(feedSinceBucket)
+		since = &bucket
+		key += "|" + bucket.Format(time.RFC3339)
+	default:
+		after = nil
+	}
+
+	s.mu.Lock()
+	if cached, ok := s.cache[key]; ok && time.Since(cached.builtAt) < s.cfg.CacheTTL {
+		s.mu.Unlock()
+		return cached.body, cached.builtAt, nil
+	}
+	if build, ok := s.building[key]; ok {
+		s.mu.Unlock()
+		select {
+		case <-build.done:
+			return build.feed.body, build.feed.builtAt, build.err
+		case <-ctx.Done():
+			return nil, time.Time{}, ctx.Err()
+		}
+	}
+	build := &feedBuild{done: make(chan struct{})}
+	s.building[key] = build
+	s.mu.Unlock()
+
+	// The feed is shared with the requests waiting for it, so it is built
+	// to the end even when this request goes away.
+	buildCtx := lib.WithLocales(context.WithoutCancel(ctx), []string{locale, lib.DefaultLocale})
+	build.feed.builtAt = time.Now().UTC()
+	build.feed.body, build.err = s.buildJobFeed(buildCtx, since, after, build.feed.builtAt)
+
+	s.mu.Lock()
+	delete(s.building, key)
+	if build.err == nil {
+		s.cacheFeed(key, build.feed)
+	}
+	s.mu.Unlock()
+	close(build.done)
+
+	if build.err != nil {
+		return nil, time.Time{}, build.err
+	}
+	return build.feed.body, build.feed.builtAt, nil
+}
+
+// cacheFeed stores the feed under key. When the cache is full, expired
+// feeds are dropped first and otherwise the oldest one. s.mu must be held.
+func (s *FeedService) cacheFeed(key string, feed cachedFeed) {
+	if _, ok := s.cache[key]; !ok && len(s.cache) >= feedCacheSize {
+		oldest := ""
+		for k, v := range s.cache {
+			if time.Since(v.builtAt) >= s.cfg.CacheTTL {
+				delete(s.cache, k)
+				continue
+			}
+			if oldest == "" || v.builtAt.Before(s.cache[oldest].builtAt) {
+				oldest = k
+			}
+		}
+		if len(s.cache) >= feedCacheSize {
+			delete(s.cache, oldest)
+		}
+	}
+	s.cache[key] = feed
+}
+
+func (s *FeedService) buildJobFeed(ctx context.Context, since *time.Time, after *int64, builtAt time.Time) ([]byte, error) {
+	limit := s.cfg.MaxItems
+	if limit <= 0 {
+		limit = 5000
+	}
+	vacancies, err := s.vacancy.ListFeed(ctx, since, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	truncated := len(vacancies) > limit
+	if truncated {
+		vacancies = vacancies[:limit]
+	}
+
+	feed := dto.JobFeed{
+		Publisher:     s.cfg.Publisher,
+		PublisherURL:  s.cfg.SiteURL,
+		LastBuildDate: builtAt.Format(time.RFC1123),
+		Truncated:     truncated,
+		Jobs:          make([]dto.FeedJob, 0, len(vacancies)),
+	}
+	if truncated && since != nil {
+		last := vacancies[len(vacancies)-1]
+		feed.NextSince = changedAt(last).Format(time.RFC3339Nano)
+		feed.NextAfter = strconv.FormatInt(last.ID, 10)
+	}
+	employers := make(map[int64]string)
+	for _, v := range vacancies {
+		employer, err := s.employerName(v.OrganizationID, employers)
+		if err != nil {
+			return nil, err
+		}
+		location, err := s.location.DescribeLocation(ctx, v.CountryCode, v.RegionID, v.CityID)
+		if err != nil {
+			return nil, err
+		}
+
+		job := dto.FeedJob{
+			Title:           dto.CDATA{Value: v.Title},
+			Date:            postedAt(v).Format(time.RFC1123),
+			ReferenceNumber: strconv.FormatInt(v.ID, 10),
+			URL:             s.vacancyURL(v.ID),
+			Company:         dto.CDATA{Value: employer},
+			Country:         v.CountryCode,
+			Description:     dto.CDATA{Value: v.Description},
+			Salary:          feedSalary(v),
+			Category:        s.categoryName(ctx, v.CategoryID),
+		}
+		if location != nil {
+			job.City = location.City
+			job.State = location.Region
+		} else {
+			job.Country = v.Country
+		}
+		if v.ExpiresAt != nil {
+			job.ExpirationDate = v.ExpiresAt.UTC().Format(time.RFC1123)
+		}
+		feed.Jobs = append(feed.Jobs, job)
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info("Job feed built", slog.Int("jobs", len(feed.Jobs)))
+	return append([]byte(xml.Header), body...), nil
+}
+
+// JobPosting returns the schema.org JobPosting of a published vacancy.
+func (s *FeedService) JobPosting(ctx context.Context, id int64) (*dto.JobPosting, error) {
+	v, err := s.vacancy.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil || !isVacancyLive(v) {
+		return nil, model.ErrVacancyNotFound
+	}
+	employer, err := s.employerName(v.OrganizationID, nil)
+	if err != nil {
+		return nil, err
+	}
+	location, err := s.location.DescribeLocation(ctx, v.CountryCode, v.RegionID, v.CityID)
+	if err != nil {
+		return nil, err
+	}
+
+	posting := &dto.JobPosting{
+		Context:     "https://schema.org/",
+		Type:        "JobPosting",
+		Title:       v.Title,
+		Description: v.Description,
+		Identifier: dto.PropertyValue{
+			Type:  "PropertyValue",
+			Name:  employer,
+			Value: strconv.FormatInt(v.ID, 10),
+		},
+		DatePosted: postedAt(*v).Format("2006-01-02"),
+		URL:        s.vacancyURL(v.ID),
+		HiringOrganization: dto.JobPostingEmployer{
+			Type: "Organization",
+			Name: employer,
+		},
+		OccupationalCategory: s.categoryName(ctx, v.CategoryID),
+		BaseSalary:           jobPostingSalary(*v),
+	}
+	if v.ExpiresAt != nil {
+		posting.ValidThrough = v.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if location != nil {
+		posting.JobLocation = &dto.JobPostingPlace{
+			Type: "Place",
+			Address: dto.JobPostingAddress{
+				Type:            "PostalAddress",
+				AddressLocality: location.City,
+				AddressRegion:   location.Region,
+				AddressCountry:  location.CountryCode,
+			},
+		}
+		if v.Latitude != nil && v.Longitude != nil {
+			posting.JobLocation.Geo = &dto.JobPostingGeo{
+				Type:      "GeoCoordinates",
+				Latitude:  *v.Latitude,
+				Longitude: *v.Longitude,
+			}
+		}
+	}
+	return posting, nil
+}
+
+// employerName returns the organization's name, remembering it in names
+// when given.
+func (s *FeedService) employerName(organizationID int64, names map[int64]string) (string, error) {
+	if name, ok := names[organizationID]; ok {
+		return name, nil
+	}
+	organization, err := s.organization.GetOrganization(int(organizationID))
+	if err != nil {
+		return "", err
+	}
+	name := ""
+	if organization != nil {
+		name = organization.Name
+	}
+	if names != nil {
+		names[organizationID] = name
+	}
+	return name, nil
+}
+
+func (s *FeedService) categoryName(ctx context.Context, id int64) string {
+	category, err := s.category.GetCategoryByID(ctx, int(id))
+	if err != nil {
+		return ""
+	}
+	return category.Name
+}
+
+// vacancyURL links to the vacancy in the web app, or is empty when no site
+// URL is configured.
+func (s *FeedService) vacancyURL(id int64) string {
+	if s.cfg.SiteURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/vacancy/%d", strings.TrimRight(s.cfg.SiteURL, "/"), id)
+}
+
+// postedAt is when the vacancy was published, falling back to its last
+// change for vacancies published before publication dates were tracked.
+func postedAt(v model.Vacancy) time.Time {
+	if v.PublishedAt != nil {
+		return v.PublishedAt.UTC()
+	}
+	if v.UpdatedAt != nil {
+		return v.UpdatedAt.UTC()
+	}
+	return time.Now().UTC()
+}
+
+// changedAt is when the vacancy last changed, which orders the feed.
+func changedAt(v model.Vacancy) time.Time {
+	if v.UpdatedAt != nil {
+		return v.UpdatedAt.UTC()
+	}
+	createdAt, _ := time.Parse(time.RFC3339, v.CreatedAt)
+	return createdAt.UTC()
+}
+
+// feedSalary formats the salary as "1500-2000 EUR per month".
+func feedSalary(v model.Vacancy) string {
+	var amount string
+	switch {
+	case v.SalaryExact != nil:
+		amount = formatAmount(*v.SalaryExact)
+	case v.SalaryFrom != nil && v.SalaryTo != nil:
+		amount = formatAmount(*v.SalaryFrom) + "-" + formatAmount(*v.SalaryTo)
+	case v.SalaryFrom != nil:
+		amount = "from " + formatAmount(*v.SalaryFrom)
+	case v.SalaryTo != nil:
+		amount = "up to " + formatAmount(*v.SalaryTo)
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%s %s per %s", amount, v.SalaryCurrency, v.SalaryType)
+}
+
+// jobPostingSalary maps the salary to a schema.org MonetaryAmount. Salary
+// types match the schema.org unit names.
+func jobPostingSalary(v model.Vacancy) *dto.JobPostingSalary {
+	value := dto.JobPostingSalaryValue{
+		Type:     "QuantitativeValue",
+		UnitText: strings.ToUpper(v.SalaryType),
+	}
+	switch {
+	case v.SalaryExact != nil:
+		value.Value = v.SalaryExact
+	case v.SalaryFrom != nil || v.SalaryTo != nil:
+		value.MinValue, value.MaxValue = v.SalaryFrom, v.SalaryTo
+	default:
+		return nil
+	}
+	return &dto.JobPostingSalary{
+		Type:     "MonetaryAmount",
+		Currency: v.SalaryCurrency,
+		Value:    value,
+	}
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/repository"
+)
+
+//go:embed data/locations.json
+var locationsDataset []byte
+
+// LocationService serves the country, region and city reference data. The
+// data is small and only changes when the dataset is reseeded, so it is kept
+// in memory after the first read.
+type LocationService struct {
+	log     *slog.Logger
+	repo    *repository.LocationRepository
+	vacancy *repository.VacancyRepository
+
+	mu     sync.Mutex
+	cached *locationSnapshot
+}
+
+type locationSnapshot struct {
+	countries []model.Country
+	regions   []model.Region
+	cities    []model.City
+
+	countryByCode map[string]model.Country
+	regionByID    map[int]model.Region
+	cityByID      map[int]model.City
+}
+
+func NewLocationService(log *slog.Logger, repo *repository.LocationRepository, vacancy *repository.VacancyRepository) *LocationService {
+	return &LocationService{
+		log:     log,
+		repo:    repo,
+		vacancy: vacancy,
+	}
+}
+
+// Seed loads the embedded dataset into the reference tables.
+func (s *LocationService) Seed(ctx context.Context) error {
+	var countries []model.CountrySeed
+	if err := json.Unmarshal(locationsDataset, &countries); err != nil {
+		return fmt.Errorf("decode locations dataset: %w", err)
+	}
+	if err := s.repo.Seed(ctx, countries); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+
+	s.log.Info("Location reference data seeded", slog.Int("countries", len(countries)))
+	return nil
+}
+
+func (s *LocationService) ListCountries(ctx context.Context) ([]dto.Country, error) {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	countries := []dto.Country{}
+	for _, c := range snapshot.countries {
+		countries = append(countries, dto.Country{Code: c.Code, Name: c.Name})
+	}
+	return countries, nil
+}
+
+// ListOpenCountries returns the countries that have published vacancies,
+// with the most vacancies first.
+func (s *LocationService) ListOpenCountries(ctx context.Context) ([]dto.Country, error) {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.vacancy.CountOpenByCountry(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	countries := []dto.Country{}
+	for _, c := range snapshot.countries {
+		count, ok := counts[c.Code]
+		if !ok {
+			continue
+		}
+		countries = append(countries, dto.Country{Code: c.Code, Name: c.Name, VacancyCount: &count})
+	}
+	sort.SliceStable(countries, func(i, j int) bool {
+		return *countries[i].VacancyCount > *countries[j].VacancyCount
+	})
+	return countries, nil
+}
+
+func (s *LocationService) ListRegions(ctx context.Context, countryCode string) ([]dto.Region, error) {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	countryCode = strings.ToUpper(countryCode)
+	if _, ok := snapshot.countryByCode[countryCode]; !ok {
+		return nil, model.ErrCountryNotFound
+	}
+
+	regions := []dto.Region{}
+	for _, r := range snapshot.regions {
+		if r.CountryCode == countryCode {
+			regions = append(regions, dto.Region{ID: r.ID, CountryCode: r.CountryCode, Name: r.Name})
+		}
+	}
+	return regions, nil
+}
+
+func (s *LocationService) ListCities(ctx context.Context, regionID int) ([]dto.City, error) {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := snapshot.regionByID[regionID]; !ok {
+		return nil, model.ErrRegionNotFound
+	}
+
+	cities := []dto.City{}
+	for _, c := range snapshot.cities {
+		if c.RegionID == regionID {
+			cities = append(cities, toCityResponse(c))
+		}
+	}
+	return cities, nil
+}
+
+// ResolveVacancyLocation validates the location of the vacancy and fills in
+// what can be derived: the region and missing coordinates from the city, the
+// country from the region, and the legacy free-text country from the country
+// code. A vacancy
+// with only a free-text country that names a known country gets its code.
+func (s *LocationService) ResolveVacancyLocation(ctx context.Context, vacancy *dto.Vacancy) error {
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateCoordinates(vacancy.Latitude, vacancy.Longitude); err != nil {
+		return err
+	}
+
+	if vacancy.CityID != nil {
+		city, ok := snapshot.cityByID[*vacancy.CityID]
+		if !ok {
+			return fmt.Errorf("%w: %d", model.ErrCityNotFound, *vacancy.CityID)
+		}
+		if vacancy.RegionID != nil && *vacancy.RegionID != city.RegionID {
+			return model.ErrLocationMismatch
+		}
+		regionID := city.RegionID
+		vacancy.RegionID = &regionID
+		if vacancy.Latitude == nil && city.Latitude != nil && city.Longitude != nil {
+			vacancy.Latitude, vacancy.Longitude = city.Latitude, city.Longitude
+		}
+	}
+	if vacancy.RegionID != nil {
+		region, ok := snapshot.regionByID[*vacancy.RegionID]
+		if !ok {
+			return fmt.Errorf("%w: %d", model.ErrRegionNotFound, *vacancy.RegionID)
+		}
+		if vacancy.CountryCode != "" && !strings.EqualFold(vacancy.CountryCode, region.CountryCode) {
+			return model.ErrLocationMismatch
+		}
+		vacancy.CountryCode = region.CountryCode
+	}
+
+	if vacancy.CountryCode == "" {
+		if country, ok := snapshot.findCountry(vacancy.Country); ok {
+			vacancy.CountryCode = country.Code
+		}
+		return nil
+	}
+	country, ok := snapshot.countryByCode[strings.ToUpper(vacancy.CountryCode)]
+	if !ok {
+		return fmt.Errorf("%w: %q", model.ErrCountryNotFound, vacancy.CountryCode)
+	}
+	vacancy.CountryCode = country.Code
+	vacancy.Country = country.Name
+	return nil
+}
+
+// DescribeLocation resolves the names of a vacancy location. It returns nil
+// when the vacancy has no country code.
+func (s *LocationService) DescribeLocation(ctx context.Context, countryCode string, regionID, cityID *int) (*dto.Location, error) {
+	if countryCode == "" {
+		return nil, nil
+	}
+	snapshot, err := s.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	location := &dto.Location{
+		CountryCode: countryCode,
+		Country:     snapshot.countryByCode[countryCode].Name,
+		RegionID:    regionID,
+		CityID:      cityID,
+	}
+	if regionID != nil {
+		location.Region = snapshot.regionByID[*regionID].Name
+	}
+	if cityID != nil {
+		location.City = snapshot.cityByID[*cityID].Name
+	}
+	return location, nil
+}
+
+func (s *LocationService) snapshot(ctx context.Context) (*locationSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil {
+		return s.cached, nil
+	}
+
+	countries, err := s.repo.FindCountries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	regions, err := s.repo.FindRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cities, err := s.repo.FindCities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &locationSnapshot{
+		countries:     countries,
+		regions:       regions,
+		cities:        cities,
+		countryByCode: make(map[string]model.Country, len(countries)),
+		regionByID:    make(map[int]model.Region, len(regions)),
+		cityByID:      make(map[int]model.City, len(cities)),
+	}
+	for _, c := range countries {
+		snapshot.countryByCode[c.Code] = c
+	}
+	for _, r := range regions {
+		snapshot.regionByID[r.ID] = r
+	}
+	for _, c := range cities {
+		snapshot.cityByID[c.ID] = c
+	}
+
+	s.cached = snapshot
+	return snapshot, nil
+}
+
+// ValidateCoordinates accepts either no coordinates or a valid
+// latitude/longitude pair.
+func ValidateCoordinates(latitude, longitude *float64) error {
+	if latitude == nil && longitude == nil {
+		return nil
+	}
+	if latitude == nil || longitude == nil {
+		return fmt.Errorf("%w: latitude and longitude go together", model.ErrInvalidCoordinates)
+	}
+	if *latitude < -90 || *latitude > 90 || *longitude < -180 || *longitude > 180 {
+		return fmt.Errorf("%w: %v,%v", model.ErrInvalidCoordinates, *latitude, *longitude)
+	}
+	return nil
+}
+
+// findCountry matches free text against country codes and names.
+func (s *locationSnapshot) findCountry(text string) (model.Country, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return model.Country{}, false
+	}
+	if country, ok := s.countryByCode[strings.ToUpper(text)]; ok {
+		return country, true
+	}
+	for _, country := range s.countries {
+		if strings.EqualFold(country.Name, text) {
+			return country, true
+		}
+	}
+	return model.Country{}, false
+}
+
+func toCityResponse(c model.City) dto.City {
+	return dto.City{
+		ID:        c.ID,
+		RegionID:  c.RegionID,
+		Name:      c.Name,
+		Latitude:  c.Latitude,
+		Longitude: c.Longitude,
+	}
+}
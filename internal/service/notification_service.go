@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/repository"
+)
+
+// NotificationService stores in-app notifications that users fetch from
+// /notifications.
+type NotificationService struct {
+	log  *slog.Logger
+	repo *repository.NotificationRepository
+}
+
+func NewNotificationService(log *slog.Logger, repo *repository.NotificationRepository) *NotificationService {
+	return &NotificationService{
+		log:  log,
+		repo: repo,
+	}
+}
+
+func (s *NotificationService) NotifyUser(ctx context.Context, userID int64, kind, title, body string, payload any) error {
+	raw, err := marshalPayload(payload)
+	if err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, &model.Notification{
+		UserID:  userID,
+		Type:    kind,
+		Title:   title,
+		Body:    body,
+		Payload: raw,
+	})
+}
+
+func (s *NotificationService) NotifyOrganization(ctx context.Context, organizationID int64, kind, title, body string, payload any) error {
+	raw, err := marshalPayload(payload)
+	if err != nil {
+		return err
+	}
+	count, err := s.repo.CreateForOrganization(ctx, organizationID, &model.Notification{
+		Type:    kind,
+		Title:   title,
+		Body:    body,
+		Payload: raw,
+	})
+	if err != nil {
+		return err
+	}
+	s.log.Debug("Organization notified", slog.Int64("organization_id", organizationID), slog.String("type", kind), slog.Int64("users", count))
+	return nil
+}
+
+func (s *NotificationService) ListNotifications(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) (*dto.ListNotificationsResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	notifications, err := s.repo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ListNotificationsResponse{Notifications: []dto.Notification{}}
+	for _, n := range notifications {
+		response.Notifications = append(response.Notifications, dto.Notification{
+			ID:        n.ID,
+			Type:      n.Type,
+			Title:     n.Title,
+			Body:      n.Body,
+			Payload:   n.Payload,
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, id, userID int64) (bool, error) {
+	return s.repo.MarkRead(ctx, id, userID)
+}
+
+func marshalPayload(payload any) (json.RawMessage, error) {
+	if payload == nil {
+		return nil, nil
+	}
+	return json.Marshal(payload)
+}
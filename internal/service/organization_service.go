@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 
@@ -66,9 +67,36 @@ func (s *OrganizationService) GetOrganization(id int) (*dto.Organization, error)
 		Name:        org.Name,
 		Description: org.Description,
 		Users:       users,
+		Verified:    org.Verified,
 	}, nil
 }
 
+// GetOrganizations returns the given organizations with their users, keyed
+// by ID, in two queries however many there are. Missing organizations are
+// left out.
+func (s *OrganizationService) GetOrganizations(ctx context.Context, ids []int) (map[int]dto.Organization, error) {
+	orgs, err := s.repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.user.ListUsersByOrganizations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]dto.Organization, len(orgs))
+	for id, org := range orgs {
+		result[id] = dto.Organization{
+			Id:          org.Id,
+			Name:        org.Name,
+			Description: org.Description,
+			Users:       users[id],
+			Verified:    org.Verified,
+		}
+	}
+	return result, nil
+}
+
 func (s *OrganizationService) GetAllOrganizations() ([]*dto.Organization, error) {
 	orgs, err := s.repo.GetAllOrganizations()
 	if err != nil {
@@ -82,9 +110,60 @@ func (s *OrganizationService) GetAllOrganizations() ([]*dto.Organization, error)
 			Id:          org.Id,
 			Name:        org.Name,
 			Description: org.Description,
+			Verified:    org.Verified,
 		})
 	}
 
 	s.log.Info("Organizations retrieved successfully", slog.Int("count", len(dtoOrgs)))
 	return dtoOrgs, nil
 }
+
+// HideOrganization excludes the organization's vacancies from the user's
+// vacancy lists and saved search alerts.
+func (s *OrganizationService) HideOrganization(ctx context.Context, userID int64, organizationID int) error {
+	org, err := s.repo.GetOrganization(organizationID)
+	if err != nil {
+		return err
+	}
+	if org == nil {
+		return model.ErrOrganizationNotFound
+	}
+	return s.repo.Hide(ctx, userID, organizationID)
+}
+
+func (s *OrganizationService) UnhideOrganization(ctx context.Context, userID int64, organizationID int) error {
+	return s.repo.Unhide(ctx, userID, organizationID)
+}
+
+func (s *OrganizationService) ListHiddenOrganizations(ctx context.Context, userID int64) ([]dto.Organization, error) {
+	orgs, err := s.repo.ListHidden(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.Organization{}
+	for _, org := range orgs {
+		response = append(response, dto.Organization{
+			Id:          org.Id,
+			Name:        org.Name,
+			Description: org.Description,
+			Verified:    org.Verified,
+		})
+	}
+	return response, nil
+}
+
+// VerifyOrganization sets whether an administrator has checked the
+// organization.
+func (s *OrganizationService) VerifyOrganization(ctx context.Context, id int, req dto.VerifyOrganization) error {
+	found, err := s.repo.SetVerified(ctx, id, req.Verified)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return model.ErrOrganizationNotFound
+	}
+
+	s.log.Info("Organization verification changed", slog.Int("id", id), slog.Bool("verified", req.Verified))
+	return nil
+}
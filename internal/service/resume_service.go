@@ -32,27 +32,51 @@ func NewResumeService(
 	}
 }
 
+// CreateResume stores the resume with its skills and experiences, all or
+// nothing.
 func (s *ResumeService) CreateResume(ctx context.Context, req *model.Resume) (*model.Resume, error) {
 	resume, err := s.resume.CreateResume(ctx, *req)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, skill := range req.Skills {
-		_, err := s.skill.CreateResumeSkill(ctx, *skill)
-		if err != nil {
-			return nil, err
-		}
+	return s.GetResume(ctx, resume.Id)
+}
+
+// UpdateResume saves one of the user's resumes. Its skills and experiences
+// are reconciled against what is stored: new ones are inserted, known ones
+// updated and missing ones deleted, all in one transaction.
+func (s *ResumeService) UpdateResume(ctx context.Context, userID int, req *model.Resume) (*model.Resume, error) {
+	if _, err := s.ownedResume(ctx, req.Id, userID); err != nil {
+		return nil, err
 	}
 
-	for _, experience := range req.Experiences {
-		_, err := s.experience.CreateResumeExperience(ctx, experience)
-		if err != nil {
-			return nil, err
-		}
+	req.UserId = userID
+	if err := s.resume.UpdateResume(ctx, *req); err != nil {
+		return nil, err
 	}
 
-	return s.GetResume(ctx, resume.Id)
+	return s.GetResume(ctx, req.Id)
+}
+
+// DeleteResume deletes one of the user's resumes with its skills and
+// experiences.
+func (s *ResumeService) DeleteResume(ctx context.Context, id, userID int) error {
+	if _, err := s.ownedResume(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.resume.DeleteResume(ctx, id)
+}
+
+func (s *ResumeService) ownedResume(ctx context.Context, id, userID int) (*model.Resume, error) {
+	resume, err := s.resume.GetResumeByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if resume.UserId != userID {
+		return nil, model.ErrResumeForbidden
+	}
+	return &resume, nil
 }
 
 func (s *ResumeService) GetResume(ctx context.Context, id int) (*model.Resume, error) {
@@ -85,20 +109,42 @@ func (s *ResumeService) GetResume(ctx context.Context, id int) (*model.Resume, e
 	return &resume, nil
 }
 
+// ListResumes lists resumes with their skills, experiences and category.
+// The page is loaded in three queries however many resumes it has.
 func (s *ResumeService) ListResumes(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*model.Resume, error) {
 	resumes, err := s.resume.ListResumes(ctx, filters, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
+	ids := make([]int, 0, len(resumes))
+	categoryIDs := make([]int, 0, len(resumes))
+	for _, resume := range resumes {
+		ids = append(ids, resume.Id)
+		categoryIDs = append(categoryIDs, resume.CategoryId)
+	}
+	skills, err := s.skill.ListResumeSkillsByResumeIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	experiences, err := s.experience.ListResumeExperiencesByResumeIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.category.GetCategoriesByIDs(ctx, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	var detailed_resumes []*model.Resume
 	for _, resume := range resumes {
-		detailed_resume, err := s.GetResume(ctx, resume.Id)
-		if err != nil {
-			return nil, err
+		resume.Skills = skills[resume.Id]
+		resume.Experiences = experiences[resume.Id]
+		if category, ok := categories[resume.CategoryId]; ok {
+			resume.Category = &category
 		}
 
-		detailed_resumes = append(detailed_resumes, detailed_resume)
+		detailed_resumes = append(detailed_resumes, &resume)
 	}
 
 	return detailed_resumes, nil
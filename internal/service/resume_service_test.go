@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aidosgal/alem.core-service/internal/repository"
+	"github.com/aidosgal/alem.core-service/internal/testdb"
+)
+
+// resumeRows answers the resume list with a page of size resumes and
+// everything loaded for them with no rows.
+func resumeRows(size *int) testdb.Handler {
+	return func(query string, _ []driver.Value) (*testdb.Result, error) {
+		switch {
+		case strings.HasPrefix(query, "SELECT id, user_id, category_id, description, salary_from, salary_to, salary_period, created_at FROM resumes"):
+			result := &testdb.Result{Columns: []string{"id", "user_id", "category_id", "description", "salary_from", "salary_to", "salary_period", "created_at"}}
+			for i := 1; i <= *size; i++ {
+				result.Rows = append(result.Rows, []driver.Value{int64(i), int64(1), int64(1), "Description", int64(100), int64(200), "monthly", "2024-01-01T00:00:00Z"})
+			}
+			return result, nil
+		case strings.HasPrefix(query, "SELECT"):
+			return &testdb.Result{}, nil
+		}
+		return nil, nil
+	}
+}
+
+func newTestResumeService(db *testdb.DB) *ResumeService {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	vacancy := repository.NewVacancyRepository(log, db.DB)
+	resume := repository.NewResumeRepository(log, db.DB)
+	category := NewCategoryService(repository.NewCategoryRepository(db.DB), repository.NewCategoryAttributeRepository(log, db.DB), vacancy, resume, log)
+	return NewResumeService(log, resume,
+		repository.NewResumeSkillRepository(log, db.DB),
+		repository.NewResumeExperienceRepository(log, db.DB),
+		category,
+	)
+}
+
+// TestListResumesQueryCount checks that a page of resumes is loaded with
+// the same number of queries whatever its size.
+func TestListResumesQueryCount(t *testing.T) {
+	var size int
+	db := testdb.Open(t, resumeRows(&size))
+	service := newTestResumeService(db)
+	ctx := context.Background()
+	// The first list also loads the category tree, which is cached.
+	if _, err := service.ListResumes(ctx, map[string]interface{}{}, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	want := -1
+	for _, size = range listPageSizes {
+		db.Reset()
+		resumes, err := service.ListResumes(ctx, map[string]interface{}{}, size, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resumes) != size {
+			t.Fatalf("listed %d resumes, want %d", len(resumes), size)
+		}
+		queries := len(db.Queries())
+		if want < 0 {
+			want = queries
+		}
+		if queries != want {
+			t.Fatalf("page of %d sent %d queries, want %d:\n%s", size, queries, want, strings.Join(db.Queries(), "\n"))
+		}
+	}
+}
+
+func BenchmarkListResumes(b *testing.B) {
+	for _, pageSize := range listPageSizes {
+		b.Run(strconv.Itoa(pageSize), func(b *testing.B) {
+			size := pageSize
+			db := testdb.Open(b, resumeRows(&size))
+			service := newTestResumeService(db)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				db.Reset()
+				if _, err := service.ListResumes(context.Background(), map[string]interface{}{}, size, 0); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(db.Queries())), "queries/op")
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aidosgal/alem.core-service/internal/config"
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/repository"
+)
+
+// savedSearchAlertSize is how many vacancies an alert lists; the total count
+// is always reported.
+const savedSearchAlertSize = 5
+
+type SavedSearchService struct {
+	log          *slog.Logger
+	cfg          config.SavedSearchConfig
+	repo         *repository.SavedSearchRepository
+	vacancy      *VacancyService
+	notification *NotificationService
+}
+
+func NewSavedSearchService(log *slog.Logger, cfg config.SavedSearchConfig, repo *repository.SavedSearchRepository, vacancy *VacancyService, notification *NotificationService) *SavedSearchService {
+	return &SavedSearchService{
+		log:          log,
+		cfg:          cfg,
+		repo:         repo,
+		vacancy:      vacancy,
+		notification: notification,
+	}
+}
+
+func (s *SavedSearchService) CreateSavedSearch(ctx context.Context, userID int64, req dto.CreateSavedSearch) (*dto.SavedSearch, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", model.ErrInvalidSavedSearch)
+	}
+	frequency := req.Frequency
+	if frequency == "" {
+		frequency = model.SavedSearchDaily
+	}
+	if frequency != model.SavedSearchInstant && frequency != model.SavedSearchDaily {
+		return nil, fmt.Errorf("%w: unknown frequency %q", model.ErrInvalidSavedSearch, frequency)
+	}
+
+	count, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.MaxPerUser > 0 && count >= s.cfg.MaxPerUser {
+		return nil, model.ErrSavedSearchLimit
+	}
+
+	filters, err := json.Marshal(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	token, err := newUnsubscribeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	search := &model.SavedSearch{
+		UserID:           userID,
+		Name:             name,
+		Filters:          filters,
+		Frequency:        frequency,
+		UnsubscribeToken: token,
+	}
+	if err := s.repo.Create(ctx, search); err != nil {
+		return nil, err
+	}
+	return toSavedSearchResponse(*search), nil
+}
+
+func (s *SavedSearchService) ListSavedSearches(ctx context.Context, userID int64) ([]dto.SavedSearch, error) {
+	searches, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.SavedSearch{}
+	for _, search := range searches {
+		response = append(response, *toSavedSearchResponse(search))
+	}
+	return response, nil
+}
+
+func (s *SavedSearchService) DeleteSavedSearch(ctx context.Context, id, userID int64) error {
+	deleted, err := s.repo.Delete(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return model.ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// Unsubscribe turns off alerts for the saved search the token belongs to.
+// It needs no authentication so it can be used straight from a notification.
+func (s *SavedSearchService) Unsubscribe(ctx context.Context, token string) error {
+	found, err := s.repo.Unsubscribe(ctx, token)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return model.ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// RunMatcher checks due saved searches for newly published vacancies once
+// per interval until ctx is cancelled.
+func (s *SavedSearchService) RunMatcher(ctx context.Context) {
+	interval := s.cfg.MatchInterval
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.MatchSavedSearches(ctx); err != nil {
+			s.log.Error("Saved search matcher failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *SavedSearchService) MatchSavedSearches(ctx context.Context) error {
+	searches, err := s.repo.ListDue(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	notified := 0
+	for _, search := range searches {
+		matched, err := s.match(ctx, search)
+		if err != nil {
+			s.log.Error("Failed to match saved search", slog.Int64("id", search.ID), slog.Any("error", err))
+			continue
+		}
+		if matched {
+			notified++
+		}
+	}
+
+	if notified > 0 {
+		s.log.Info("Saved search alerts sent", slog.Int("checked", len(searches)), slog.Int("notified", notified))
+	}
+	return nil
+}
+
+// match looks for vacancies published since the search was last reported,
+// notifies its owner and moves the search past them.
+func (s *SavedSearchService) match(ctx context.Context, search model.SavedSearch) (bool, error) {
+	var filters dto.SavedSearchFilters
+	if err := json.Unmarshal(search.Filters, &filters); err != nil {
+		return false, err
+	}
+
+	lastPublishedAt := search.LastPublishedAt
+	vacancies, err := s.vacancy.ListVacancies(ctx, dto.ListVacancyRequest{
+		CategoryIDs:      filters.CategoryIDs,
+		SalaryFrom:       filters.SalaryFrom,
+		SalaryTo:         filters.SalaryTo,
+		Currency:         filters.Currency,
+		Search:           filters.Search,
+		CountryCodes:     filters.CountryCodes,
+		RegionIDs:        filters.RegionIDs,
+		CityIDs:          filters.CityIDs,
+		Near:             filters.Near,
+		RadiusKm:         filters.RadiusKm,
+		Sort:             dto.VacancySortPublished,
+		PublishedAfter:   &lastPublishedAt,
+		PublishedAfterID: search.LastVacancyID,
+		ViewerUserID:     search.UserID,
+		Limit:            savedSearchAlertSize,
+		Total:            dto.VacancyTotalExact,
+
+		CollapseDuplicates: true,
+		Attributes:         filters.Attributes,
+		Verified:           filters.Verified,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	lastVacancyID := search.LastVacancyID
+	if len(vacancies.Vacancie) > 0 {
+		total := *vacancies.Total
+		newest := vacancies.Vacancie[0]
+		if newest.PublishedAt != nil {
+			lastPublishedAt, lastVacancyID = *newest.PublishedAt, newest.ID
+		}
+
+		ids := make([]int64, 0, len(vacancies.Vacancie))
+		titles := make([]string, 0, len(vacancies.Vacancie))
+		for _, v := range vacancies.Vacancie {
+			ids = append(ids, v.ID)
+			titles = append(titles, v.Title)
+		}
+		payload := map[string]any{
+			"saved_search_id":   search.ID,
+			"vacancy_ids":       ids,
+			"total":             total,
+			"unsubscribe_token": search.UnsubscribeToken,
+		}
+		title := fmt.Sprintf("%d new vacancies for %q", total, search.Name)
+		if err := s.notification.NotifyUser(ctx, search.UserID, model.NotificationSavedSearchMatch, title, strings.Join(titles, "\n"), payload); err != nil {
+			return false, err
+		}
+	}
+
+	if err := s.repo.MarkChecked(ctx, search.ID, lastPublishedAt, lastVacancyID); err != nil {
+		return false, err
+	}
+	return len(vacancies.Vacancie) > 0, nil
+}
+
+func newUnsubscribeToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func toSavedSearchResponse(search model.SavedSearch) *dto.SavedSearch {
+	var filters dto.SavedSearchFilters
+	_ = json.Unmarshal(search.Filters, &filters)
+	return &dto.SavedSearch{
+		ID:            search.ID,
+		Name:          search.Name,
+		Filters:       filters,
+		Frequency:     search.Frequency,
+		Active:        search.Active,
+		LastCheckedAt: search.LastCheckedAt,
+		CreatedAt:     search.CreatedAt,
+	}
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aidosgal/alem.core-service/internal/config"
+)
+
+// Translator machine-translates texts from one locale into another,
+// returning the translations in the order of texts.
+type Translator interface {
+	Translate(ctx context.Context, from, to string, texts []string) ([]string, error)
+}
+
+// NewTranslator returns the translator of the configured provider.
+func NewTranslator(cfg config.TranslationConfig) (Translator, error) {
+	switch cfg.Provider {
+	case "", "noop":
+		return NoopTranslator{}, nil
+	default:
+		return nil, fmt.Errorf("unknown translation provider %q", cfg.Provider)
+	}
+}
+
+// NoopTranslator returns the texts unchanged, so drafts start from the
+// original for the employer to translate.
+type NoopTranslator struct{}
+
+func (NoopTranslator) Translate(_ context.Context, _, _ string, texts []string) ([]string, error) {
+	return append([]string(nil), texts...), nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 
@@ -45,11 +46,12 @@ func (s *UserService) Register(req dto.RegisterRequest) (*dto.RegisterResponse,
 	}
 
 	token, err := lib.NewToken(
-		int64(id), int64(req.User.OrganizationId), "organization_type")
+		int64(id), int64(req.User.OrganizationId), "organization_type", model.UserRoleUser)
 	if err != nil {
 		return nil, err
 	}
 
+	req.User.Role = model.UserRoleUser
 	return &dto.RegisterResponse{User: req.User, Token: token}, nil
 }
 
@@ -64,7 +66,7 @@ func (s *UserService) Login(req dto.LoginRequest) (*dto.LoginResponse, error) {
 		return nil, errors.New("invalid phone or password")
 	}
 
-	token, err := lib.NewToken(int64(user.Id), int64(user.OrganizationId), "organization_type")
+	token, err := lib.NewToken(int64(user.Id), int64(user.OrganizationId), "organization_type", user.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +79,7 @@ func (s *UserService) Login(req dto.LoginRequest) (*dto.LoginResponse, error) {
 			Phone:          user.Phone,
 			AvatarURL:      user.AvatarURL,
 			Balance:        user.Balance,
+			Role:           user.Role,
 		},
 		IsCompleted: true,
 		Token:       token,
@@ -99,6 +102,7 @@ func (s *UserService) GetProfile(userID int) (*dto.User, error) {
 		Phone:          user.Phone,
 		AvatarURL:      user.AvatarURL,
 		Balance:        user.Balance,
+		Role:           user.Role,
 		Organization:   *org,
 	}, nil
 }
@@ -108,7 +112,25 @@ func (s *UserService) ListUsers(organizationID int) ([]dto.User, error) {
 	if err != nil {
 		return nil, err
 	}
+	return toOrganizationUsers(users), nil
+}
+
+// ListUsersByOrganizations returns the users of every given organization,
+// keyed by organization ID.
+func (s *UserService) ListUsersByOrganizations(ctx context.Context, organizationIDs []int) (map[int][]dto.User, error) {
+	users, err := s.userRepo.ListUsersByOrganizations(ctx, organizationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int][]dto.User, len(users))
+	for organizationID, organizationUsers := range users {
+		result[organizationID] = toOrganizationUsers(organizationUsers)
+	}
+	return result, nil
+}
 
+func toOrganizationUsers(users []model.User) []dto.User {
 	var userDTOs []dto.User
 	for _, user := range users {
 		userDTOs = append(userDTOs, dto.User{
@@ -120,5 +142,5 @@ func (s *UserService) ListUsers(organizationID int) ([]dto.User, error) {
 			Balance:        user.Balance,
 		})
 	}
-	return userDTOs, nil
+	return userDTOs
 }
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+// rangeSeparator separates the bounds of range values and of number and
+// range filters, e.g. "1000..2000".
+const rangeSeparator = ".."
+
+// newVacancyDetails converts the details of a request. Details with a key
+// are checked by validateAttributes.
+func newVacancyDetails(vacancyID int64, details []dto.VacancyDetailResponse) []model.VacancyDetail {
+	result := make([]model.VacancyDetail, 0, len(details))
+	for _, detail := range details {
+		d := model.VacancyDetail{
+			ID:        detail.ID,
+			GroupName: detail.GroupName,
+			Name:      detail.Name,
+			Value:     detail.Value,
+			IconURL:   iconURL(detail.IconURL),
+			VacancyID: vacancyID,
+		}
+		if key := strings.TrimSpace(detail.Key); key != "" {
+			d.AttributeKey = &key
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// validateAttributes checks the typed details against the attribute schema
+// of the category and fills in their parsed values. Every required
+// attribute must be given, and each at most once. Values are stored in a
+// canonical form, and a detail without a name takes the attribute's.
+func (s *VacancyService) validateAttributes(ctx context.Context, categoryID int64, details []model.VacancyDetail) error {
+	schema, err := s.category.AttributeSchema(ctx, int(categoryID))
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for i := range details {
+		detail := &details[i]
+		if detail.AttributeKey == nil {
+			continue
+		}
+		key := *detail.AttributeKey
+		attribute, ok := schema[key]
+		if !ok {
+			return fmt.Errorf("%w: %s is not an attribute of the category", model.ErrInvalidVacancyAttribute, key)
+		}
+		if seen[key] {
+			return fmt.Errorf("%w: %s is given twice", model.ErrInvalidVacancyAttribute, key)
+		}
+		seen[key] = true
+
+		if err := parseAttributeValue(attribute, detail); err != nil {
+			return err
+		}
+		if strings.TrimSpace(detail.Name) == "" {
+			detail.Name = attribute.Name
+		}
+	}
+
+	var missing []string
+	for key, attribute := range schema {
+		if attribute.Required && !seen[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: %s is required", model.ErrInvalidVacancyAttribute, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// parseAttributeValue parses the value of the detail by the attribute type
+// into its typed fields.
+func parseAttributeValue(attribute model.CategoryAttribute, detail *model.VacancyDetail) error {
+	value := strings.TrimSpace(detail.Value)
+	detail.BoolValue, detail.NumberValue, detail.NumberTo = nil, nil, nil
+
+	switch attribute.Type {
+	case model.AttributeTypeBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be true or false", model.ErrInvalidVacancyAttribute, attribute.Key)
+		}
+		detail.BoolValue = &b
+		detail.Value = strconv.FormatBool(b)
+	case model.AttributeTypeEnum:
+		i := slices.IndexFunc(attribute.Options, func(option string) bool {
+			return strings.EqualFold(option, value)
+		})
+		if i < 0 {
+			return fmt.Errorf("%w: %s must be one of %s", model.ErrInvalidVacancyAttribute, attribute.Key, strings.Join(attribute.Options, ", "))
+		}
+		detail.Value = attribute.Options[i]
+	case model.AttributeTypeNumber:
+		n, err := parseAttributeNumber(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be a number", model.ErrInvalidVacancyAttribute, attribute.Key)
+		}
+		if err := checkAttributeBounds(attribute, n); err != nil {
+			return err
+		}
+		detail.NumberValue = &n
+		detail.Value = formatAttributeNumber(n)
+	case model.AttributeTypeRange:
+		from, to, err := parseAttributeRange(value)
+		if err != nil || from == nil || to == nil || *from > *to {
+			return fmt.Errorf("%w: %s must be a range like 1..5", model.ErrInvalidVacancyAttribute, attribute.Key)
+		}
+		if err := checkAttributeBounds(attribute, *from); err != nil {
+			return err
+		}
+		if err := checkAttributeBounds(attribute, *to); err != nil {
+			return err
+		}
+		detail.NumberValue, detail.NumberTo = from, to
+		detail.Value = formatAttributeNumber(*from) + rangeSeparator + formatAttributeNumber(*to)
+	}
+	return nil
+}
+
+func checkAttributeBounds(attribute model.CategoryAttribute, n float64) error {
+	if attribute.Min != nil && n < *attribute.Min {
+		return fmt.Errorf("%w: %s cannot be less than %s", model.ErrInvalidVacancyAttribute, attribute.Key, formatAttributeNumber(*attribute.Min))
+	}
+	if attribute.Max != nil && n > *attribute.Max {
+		return fmt.Errorf("%w: %s cannot be greater than %s", model.ErrInvalidVacancyAttribute, attribute.Key, formatAttributeNumber(*attribute.Max))
+	}
+	return nil
+}
+
+// resolveAttributeFilters parses the attr[key] filters of the request by
+// the type of each key. Enums take repeated or comma-separated values;
+// numbers and ranges take "from..to" with either bound optional, or a
+// single number.
+func (s *VacancyService) resolveAttributeFilters(ctx context.Context, req *dto.ListVacancyRequest) error {
+	if len(req.Attributes) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(req.Attributes))
+	for key := range req.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	types, err := s.category.AttributeTypes(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	req.AttributeFilters = nil
+	for _, key := range keys {
+		attributeType, ok := types[key]
+		if !ok {
+			return fmt.Errorf("%w: unknown attribute %s", model.ErrInvalidAttributeFilter, key)
+		}
+		values := lib.ParseStringList(req.Attributes[key])
+		if len(values) == 0 {
+			continue
+		}
+
+		filter := dto.AttributeFilter{Key: key, Type: attributeType}
+		switch attributeType {
+		case model.AttributeTypeBoolean:
+			b, err := strconv.ParseBool(values[0])
+			if err != nil || len(values) > 1 {
+				return fmt.Errorf("%w: %s must be true or false", model.ErrInvalidAttributeFilter, key)
+			}
+			filter.Bool = b
+		case model.AttributeTypeEnum:
+			for _, value := range values {
+				filter.Values = append(filter.Values, strings.ToLower(value))
+			}
+		case model.AttributeTypeNumber, model.AttributeTypeRange:
+			from, to, err := parseAttributeRange(values[0])
+			if err != nil || len(values) > 1 || (from == nil && to == nil) {
+				return fmt.Errorf("%w: %s must be a number or a range like 1..5", model.ErrInvalidAttributeFilter, key)
+			}
+			filter.From, filter.To = from, to
+		}
+		req.AttributeFilters = append(req.AttributeFilters, filter)
+	}
+	return nil
+}
+
+// parseAttributeRange parses "from..to", "from..", "..to" or a single
+// number, which is both bounds.
+func parseAttributeRange(value string) (*float64, *float64, error) {
+	fromText, toText, isRange := strings.Cut(value, rangeSeparator)
+	if !isRange {
+		n, err := parseAttributeNumber(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &n, &n, nil
+	}
+
+	var from, to *float64
+	if fromText = strings.TrimSpace(fromText); fromText != "" {
+		n, err := parseAttributeNumber(fromText)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = &n
+	}
+	if toText = strings.TrimSpace(toText); toText != "" {
+		n, err := parseAttributeNumber(toText)
+		if err != nil {
+			return nil, nil, err
+		}
+		to = &n
+	}
+	return from, to, nil
+}
+
+// parseAttributeNumber parses a finite number.
+func parseAttributeNumber(value string) (float64, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("%s is not a finite number", value)
+	}
+	return n, nil
+}
+
+func formatAttributeNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
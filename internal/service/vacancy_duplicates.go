@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+// A fingerprint is a MinHash signature over word shingles of the vacancy
+// text, split into bands for the candidate lookup. With 16 bands of 4 rows
+// a pair 80% alike shares a band with a probability above 99.9%. Changing
+// any of these invalidates the stored fingerprints.
+const (
+	shingleSize  = 3
+	minhashSize  = 64
+	minhashBands = 16
+	minhashRows  = minhashSize / minhashBands
+)
+
+// minhashSeeds seed the hash functions of the signature.
+var minhashSeeds = func() [minhashSize]uint64 {
+	var seeds [minhashSize]uint64
+	state := uint64(0x5eed)
+	for i := range seeds {
+		state += 0x9e3779b97f4a7c15
+		seeds[i] = mix64(state)
+	}
+	return seeds
+}()
+
+// duplicateStatuses are the statuses of vacancies that new ones are
+// compared against. Closed, expired and rejected vacancies may be posted
+// again.
+var duplicateStatuses = []string{
+	model.VacancyStatusDraft,
+	model.VacancyStatusPendingModeration,
+	model.VacancyStatusChangesRequested,
+	model.VacancyStatusPublished,
+	model.VacancyStatusPaused,
+}
+
+// duplicateMatch is a candidate that is similar enough to be a duplicate.
+type duplicateMatch struct {
+	candidate  model.DuplicateCandidate
+	similarity float64
+}
+
+// clusterID is the cluster of the candidate, or its own ID when it is not
+// in one yet.
+func (m *duplicateMatch) clusterID() int64 {
+	if m.candidate.DuplicateClusterID != nil {
+		return *m.candidate.DuplicateClusterID
+	}
+	return m.candidate.VacancyID
+}
+
+// checkDuplicate fingerprints the vacancy and compares it with vacancies of
+// the same category in the same country, region and city. A duplicate of another vacancy of the
+// same organization is a *model.DuplicateVacancyError; otherwise the most
+// similar vacancy of another organization is returned, if any. The
+// fingerprint is nil when the vacancy has no text to compare.
+func (s *VacancyService) checkDuplicate(ctx context.Context, vacancy *model.Vacancy) (*model.VacancyFingerprint, *duplicateMatch, error) {
+	fingerprint := vacancyFingerprint(vacancy)
+	if fingerprint == nil {
+		return nil, nil, nil
+	}
+	own, other, err := s.findDuplicates(ctx, vacancy, fingerprint)
+	if err != nil {
+		return nil, nil, err
+	}
+	if own != nil {
+		return nil, nil, &model.DuplicateVacancyError{VacancyID: own.candidate.VacancyID, Similarity: own.similarity}
+	}
+	return fingerprint, other, nil
+}
+
+// findDuplicates returns the most similar duplicates of the vacancy within
+// its organization and among other organizations.
+func (s *VacancyService) findDuplicates(ctx context.Context, vacancy *model.Vacancy, fingerprint *model.VacancyFingerprint) (*duplicateMatch, *duplicateMatch, error) {
+	candidates, err := s.fingerprint.FindCandidates(ctx, fingerprint, vacancy, duplicateStatuses)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	threshold := s.cfg.Duplicates.Similarity
+	if threshold <= 0 {
+		threshold = 0.8
+	}
+	var own, other *duplicateMatch
+	for _, candidate := range candidates {
+		similarity := signatureSimilarity(fingerprint.Signature, candidate.Signature)
+		if similarity < threshold {
+			continue
+		}
+		match := &duplicateMatch{candidate: candidate, similarity: similarity}
+		if candidate.OrganizationID == vacancy.OrganizationID {
+			if own == nil || similarity > own.similarity {
+				own = match
+			}
+		} else if other == nil || similarity > other.similarity {
+			other = match
+		}
+	}
+	return own, other, nil
+}
+
+// saveFingerprint stores the fingerprint of a saved vacancy and clusters it
+// with its duplicate from another organization. Without a duplicate the
+// vacancy leaves any cluster it was in.
+func (s *VacancyService) saveFingerprint(ctx context.Context, vacancyID int64, fingerprint *model.VacancyFingerprint, duplicate *duplicateMatch) error {
+	if fingerprint == nil {
+		return nil
+	}
+	var write model.VacancyWrite
+	fingerprintWrite(&write, fingerprint, duplicate)
+	fingerprint.VacancyID = vacancyID
+	if err := s.fingerprint.Save(ctx, fingerprint, write.ClusterID, write.ClusterMembers...); err != nil {
+		return err
+	}
+	s.logDuplicate(vacancyID, duplicate)
+	return nil
+}
+
+// fingerprintWrite stores the fingerprint with the vacancy and puts it into
+// the cluster of its duplicate, if any. The duplicate starts the cluster
+// when it is not in one yet.
+func fingerprintWrite(write *model.VacancyWrite, fingerprint *model.VacancyFingerprint, duplicate *duplicateMatch) {
+	write.Fingerprint = fingerprint
+	if fingerprint == nil || duplicate == nil {
+		return
+	}
+	clusterID := duplicate.clusterID()
+	write.ClusterID = &clusterID
+	write.ClusterMembers = []int64{duplicate.candidate.VacancyID}
+}
+
+func (s *VacancyService) logDuplicate(vacancyID int64, duplicate *duplicateMatch) {
+	if duplicate == nil {
+		return
+	}
+	s.log.Info("Duplicate vacancy clustered", slog.Int64("id", vacancyID), slog.Int64("duplicate_of", duplicate.candidate.VacancyID),
+		slog.Int64("cluster_id", duplicate.clusterID()), slog.Float64("similarity", duplicate.similarity))
+}
+
+// FingerprintVacancies fingerprints and clusters a batch of vacancies
+// created before duplicate detection. Duplicates within an organization
+// are left alone.
+func (s *VacancyService) FingerprintVacancies(ctx context.Context) error {
+	limit := s.cfg.Duplicates.BackfillBatch
+	if limit <= 0 {
+		limit = 500
+	}
+	vacancies, err := s.fingerprint.ListUnfingerprinted(ctx, limit)
+	if err != nil {
+		return err
+	}
+
+	for i := range vacancies {
+		vacancy := &vacancies[i]
+		fingerprint := vacancyFingerprint(vacancy)
+		if fingerprint == nil {
+			fingerprint = &model.VacancyFingerprint{Signature: []int64{}, Bands: []int64{}}
+		}
+		var other *duplicateMatch
+		if len(fingerprint.Bands) > 0 {
+			if _, other, err = s.findDuplicates(ctx, vacancy, fingerprint); err != nil {
+				return err
+			}
+		}
+		if err := s.saveFingerprint(ctx, vacancy.ID, fingerprint, other); err != nil {
+			return err
+		}
+	}
+
+	if len(vacancies) > 0 {
+		s.log.Info("Vacancies fingerprinted", slog.Int("count", len(vacancies)))
+	}
+	return nil
+}
+
+// vacancyFingerprint returns the MinHash fingerprint of the normalized
+// title and description, or nil when they have no words.
+func vacancyFingerprint(vacancy *model.Vacancy) *model.VacancyFingerprint {
+	shingles := textShingles(vacancy.Title + " " + vacancy.Description)
+	if len(shingles) == 0 {
+		return nil
+	}
+
+	var signature [minhashSize]uint64
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	for _, shingle := range shingles {
+		for i, seed := range minhashSeeds {
+			if h := mix64(shingle ^ seed); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+
+	fingerprint := &model.VacancyFingerprint{
+		Signature: make([]int64, minhashSize),
+		Bands:     make([]int64, minhashBands),
+	}
+	for i, h := range signature {
+		fingerprint.Signature[i] = int64(h)
+	}
+	for band := range fingerprint.Bands {
+		// The band index is part of the hash so that equal rows in
+		// different bands do not match.
+		h := uint64(band)
+		for _, row := range signature[band*minhashRows : (band+1)*minhashRows] {
+			h = mix64(h ^ row)
+		}
+		fingerprint.Bands[band] = int64(h)
+	}
+	return fingerprint
+}
+
+// textShingles hashes every run of shingleSize consecutive words of the
+// lowercased text, with ё folded into е and punctuation dropped. Texts
+// shorter than a shingle are a single shingle.
+func textShingles(text string) []uint64 {
+	words := strings.FieldsFunc(strings.ReplaceAll(strings.ToLower(text), "ё", "е"), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+
+	size := min(shingleSize, len(words))
+	seen := make(map[uint64]bool)
+	shingles := make([]uint64, 0, len(words)-size+1)
+	for i := 0; i+size <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+size], " ")))
+		if sum := h.Sum64(); !seen[sum] {
+			seen[sum] = true
+			shingles = append(shingles, sum)
+		}
+	}
+	return shingles
+}
+
+// signatureSimilarity estimates the Jaccard similarity of two texts as the
+// share of equal signature values.
+func signatureSimilarity(a, b []int64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
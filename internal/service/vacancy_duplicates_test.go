@@ -0,0 +1,76 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+const duplicateTestDescription = `We are looking for an experienced warehouse operator to join our team in Almaty.
+You will receive goods, check deliveries against invoices, keep stock records up to date and prepare orders
+for shipment to our stores across the country. We offer an official contract, a monthly salary paid twice
+a month, free lunches, a company bus from the city centre and paid training for forklift certification.`
+
+func sharesBand(a, b *model.VacancyFingerprint) bool {
+	for i := range a.Bands {
+		if a.Bands[i] == b.Bands[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestVacancyFingerprintNormalizesText(t *testing.T) {
+	a := vacancyFingerprint(&model.Vacancy{Title: "Warehouse operator", Description: "Ёлка, склад и учёт!"})
+	b := vacancyFingerprint(&model.Vacancy{Title: "WAREHOUSE   operator", Description: "елка склад — и учет"})
+	if similarity := signatureSimilarity(a.Signature, b.Signature); similarity != 1 {
+		t.Fatalf("similarity %v, want 1", similarity)
+	}
+	for i := range a.Bands {
+		if a.Bands[i] != b.Bands[i] {
+			t.Fatalf("band %d differs", i)
+		}
+	}
+}
+
+func TestVacancyFingerprintWithoutWords(t *testing.T) {
+	if fingerprint := vacancyFingerprint(&model.Vacancy{Title: " ", Description: "!?"}); fingerprint != nil {
+		t.Fatalf("got %v, want nil", fingerprint)
+	}
+}
+
+func TestVacancyFingerprintBanding(t *testing.T) {
+	original := vacancyFingerprint(&model.Vacancy{Title: "Warehouse operator", Description: duplicateTestDescription})
+	if len(original.Signature) != minhashSize || len(original.Bands) != minhashBands {
+		t.Fatalf("got %d values in %d bands, want %d in %d", len(original.Signature), len(original.Bands), minhashSize, minhashBands)
+	}
+
+	edited := vacancyFingerprint(&model.Vacancy{
+		Title:       "Warehouse operator",
+		Description: strings.Replace(duplicateTestDescription, "Almaty", "Astana", 1),
+	})
+	if !sharesBand(original, edited) {
+		t.Fatal("a lightly edited vacancy shares no band with the original")
+	}
+	if similarity := signatureSimilarity(original.Signature, edited.Signature); similarity < 0.8 {
+		t.Fatalf("similarity of a lightly edited vacancy %v, want at least 0.8", similarity)
+	}
+
+	unrelated := vacancyFingerprint(&model.Vacancy{
+		Title:       "Senior Go developer",
+		Description: "Build payment services in Go and PostgreSQL, review code and mentor junior engineers in a remote team.",
+	})
+	if sharesBand(original, unrelated) {
+		t.Fatal("an unrelated vacancy shares a band with the original")
+	}
+	if similarity := signatureSimilarity(original.Signature, unrelated.Signature); similarity > 0.2 {
+		t.Fatalf("similarity of an unrelated vacancy %v, want at most 0.2", similarity)
+	}
+}
+
+func TestSignatureSimilarityOfDifferentLengths(t *testing.T) {
+	if similarity := signatureSimilarity([]int64{1, 2}, []int64{1}); similarity != 0 {
+		t.Fatalf("got %v, want 0", similarity)
+	}
+}
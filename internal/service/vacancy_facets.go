@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+// salaryFacetBounds split monthly salaries in the base currency into the
+// buckets of the salary facet.
+var salaryFacetBounds = []float64{500, 1000, 1500, 2000, 3000, 5000}
+
+// vacancyFacets counts the vacancies of the list by each filter option.
+// Salary buckets are converted into the display currency and rounded.
+func (s *VacancyService) vacancyFacets(ctx context.Context, req dto.ListVacancyRequest, display *model.Currency) (*dto.VacancyFacets, error) {
+	counts, err := s.vacancy.Facets(ctx, req, salaryFacetBounds)
+	if err != nil {
+		return nil, err
+	}
+
+	facets := &dto.VacancyFacets{
+		Categories: []dto.FacetCount{},
+		Countries:  []dto.FacetCount{},
+		Salaries:   []dto.SalaryFacet{},
+		Currencies: []dto.FacetCount{},
+		Verified:   []dto.FacetCount{},
+	}
+	for _, c := range counts {
+		switch c.Dimension {
+		case model.VacancyFacetCategory:
+			facet := dto.FacetCount{Value: c.Value, Count: c.Count}
+			id, _ := strconv.Atoi(c.Value)
+			if category, err := s.category.GetCategoryByID(ctx, id); err == nil {
+				facet.Name = category.Name
+			}
+			facets.Categories = append(facets.Categories, facet)
+		case model.VacancyFacetCountry:
+			location, err := s.location.DescribeLocation(ctx, c.Value, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			facets.Countries = append(facets.Countries, dto.FacetCount{Value: c.Value, Name: location.Country, Count: c.Count})
+		case model.VacancyFacetSalary:
+			bucket, err := strconv.Atoi(c.Value)
+			if err != nil {
+				return nil, err
+			}
+			facets.Salaries = append(facets.Salaries, salaryFacet(bucket, c.Count, display))
+		case model.VacancyFacetCurrency:
+			facets.Currencies = append(facets.Currencies, dto.FacetCount{Value: c.Value, Count: c.Count})
+		case model.VacancyFacetVerified:
+			facets.Verified = append(facets.Verified, dto.FacetCount{Value: c.Value, Count: c.Count})
+		}
+	}
+
+	for _, options := range [][]dto.FacetCount{facets.Categories, facets.Countries, facets.Currencies, facets.Verified} {
+		sortFacetCounts(options)
+	}
+	sort.Slice(facets.Salaries, func(i, j int) bool {
+		return facets.Salaries[i].From == nil || (facets.Salaries[j].From != nil && *facets.Salaries[i].From < *facets.Salaries[j].From)
+	})
+	return facets, nil
+}
+
+// salaryFacet describes a bucket numbered by width_bucket: 0 is below the
+// first bound and len(salaryFacetBounds) at or above the last.
+func salaryFacet(bucket, count int, display *model.Currency) dto.SalaryFacet {
+	facet := dto.SalaryFacet{Currency: display.Code, Count: count}
+	if bucket > 0 {
+		from := math.Round(salaryFacetBounds[bucket-1] * display.Rate)
+		facet.From = &from
+	}
+	if bucket < len(salaryFacetBounds) {
+		to := math.Round(salaryFacetBounds[bucket] * display.Rate)
+		facet.To = &to
+	}
+	return facet
+}
+
+// sortFacetCounts puts the options with the most vacancies first.
+func sortFacetCounts(options []dto.FacetCount) {
+	sort.Slice(options, func(i, j int) bool {
+		if options[i].Count != options[j].Count {
+			return options[i].Count > options[j].Count
+		}
+		return options[i].Value < options[j].Value
+	})
+}
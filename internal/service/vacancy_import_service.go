@@ -0,0 +1,547 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidosgal/alem.core-service/internal/config"
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/repository"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// vacancyImportDetailPrefix marks CSV and XLSX columns holding vacancy
+	// details, as "detail:<group>:<name>" or "detail:<name>".
+	vacancyImportDetailPrefix = "detail:"
+	// vacancyImportAttributePrefix marks columns holding a typed attribute
+	// of the category, as "attr:<key>". The detail takes the attribute name.
+	vacancyImportAttributePrefix = "attr:"
+)
+
+type VacancyImportService struct {
+	log          *slog.Logger
+	cfg          config.ImportConfig
+	repo         *repository.VacancyImportRepository
+	vacancy      *VacancyService
+	category     *CategoryService
+	notification *NotificationService
+}
+
+func NewVacancyImportService(log *slog.Logger, cfg config.ImportConfig, repo *repository.VacancyImportRepository, vacancy *VacancyService, category *CategoryService, notification *NotificationService) *VacancyImportService {
+	return &VacancyImportService{
+		log:          log,
+		cfg:          cfg,
+		repo:         repo,
+		vacancy:      vacancy,
+		category:     category,
+		notification: notification,
+	}
+}
+
+// UploadVacancies parses and validates a file of vacancies for the
+// organization without creating any of them. The returned report lists
+// every row that failed validation and previews the valid ones; the valid
+// rows are created once the import is committed. format may be empty to
+// detect it from the file name.
+func (s *VacancyImportService) UploadVacancies(ctx context.Context, organizationID, userID int64, fileName, format string, r io.Reader) (*dto.VacancyImport, error) {
+	if organizationID == 0 {
+		return nil, model.ErrVacancyForbidden
+	}
+	format, err := vacancyImportFormat(fileName, format)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, rowErrors, err := parseVacancyImport(format, r)
+	if err != nil {
+		return nil, err
+	}
+	total := len(rows) + len(rowErrors)
+	if total == 0 {
+		return nil, fmt.Errorf("%w: the file has no rows", model.ErrInvalidVacancyImport)
+	}
+	if s.cfg.MaxRows > 0 && total > s.cfg.MaxRows {
+		return nil, fmt.Errorf("%w: the file has %d rows, at most %d are allowed", model.ErrInvalidVacancyImport, total, s.cfg.MaxRows)
+	}
+
+	valid := make([]dto.VacancyImportRow, 0, len(rows))
+	for _, row := range rows {
+		if err := s.validateRow(ctx, organizationID, &row.Vacancy); err != nil {
+			rowErrors = append(rowErrors, dto.VacancyImportError{Line: row.Line, Error: err.Error()})
+			continue
+		}
+		valid = append(valid, row)
+	}
+	slices.SortStableFunc(rowErrors, func(a, b dto.VacancyImportError) int { return a.Line - b.Line })
+
+	payload, err := json.Marshal(valid)
+	if err != nil {
+		return nil, err
+	}
+	errorsJSON, err := json.Marshal(rowErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	vacancyImport := &model.VacancyImport{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		FileName:       filepath.Base(fileName),
+		Format:         format,
+		TotalRows:      total,
+		ValidRows:      len(valid),
+		Errors:         errorsJSON,
+		Payload:        payload,
+	}
+	if err := s.repo.Create(ctx, vacancyImport); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Vacancy import validated", slog.Int64("id", vacancyImport.ID), slog.Int("total", total), slog.Int("valid", len(valid)))
+	response := toVacancyImportResponse(*vacancyImport)
+	response.Preview = valid
+	return &response, nil
+}
+
+// GetVacancyImport returns the report and progress of the organization's
+// import.
+func (s *VacancyImportService) GetVacancyImport(ctx context.Context, id, organizationID int64) (*dto.VacancyImport, error) {
+	vacancyImport, err := s.repo.GetByID(ctx, id, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if vacancyImport == nil {
+		return nil, model.ErrVacancyImportNotFound
+	}
+	response := toVacancyImportResponse(*vacancyImport)
+	return &response, nil
+}
+
+// CommitVacancyImport queues the valid rows of a validated import for
+// creation by the import job.
+func (s *VacancyImportService) CommitVacancyImport(ctx context.Context, id, organizationID int64) (*dto.VacancyImport, error) {
+	queued, err := s.repo.Queue(ctx, id, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	vacancyImport, err := s.GetVacancyImport(ctx, id, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !queued {
+		return nil, fmt.Errorf("%w: %s", model.ErrVacancyImportState, vacancyImport.Status)
+	}
+	s.log.Info("Vacancy import queued", slog.Int64("id", id))
+	return vacancyImport, nil
+}
+
+// RunImports creates the vacancies of committed imports, checking for new
+// ones every poll interval until ctx is cancelled. Imports whose process
+// stopped saving progress for the lease, e.g. because it was restarted,
+// are queued again and continue after their last processed row.
+func (s *VacancyImportService) RunImports(ctx context.Context) {
+	interval := s.cfg.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	lease := s.cfg.Lease
+	if lease <= 0 {
+		lease = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if requeued, err := s.repo.RequeueStale(ctx, lease); err != nil {
+			s.log.Error("Failed to requeue vacancy imports", slog.Any("error", err))
+		} else if requeued > 0 {
+			s.log.Info("Interrupted vacancy imports requeued", slog.Int64("count", requeued))
+		}
+
+		for {
+			vacancyImport, err := s.repo.ClaimNext(ctx)
+			if err != nil {
+				s.log.Error("Failed to claim vacancy import", slog.Any("error", err))
+				break
+			}
+			if vacancyImport == nil {
+				break
+			}
+			s.runImport(ctx, vacancyImport)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runImport creates the remaining rows of the import one by one, saving the
+// progress after each row, which also renews the lease. Each row is created
+// in its own transaction. A row that fails is recorded in the import errors
+// and does not stop the others.
+func (s *VacancyImportService) runImport(ctx context.Context, vacancyImport *model.VacancyImport) {
+	log := s.log.With(slog.Int64("import_id", vacancyImport.ID))
+
+	var rows []dto.VacancyImportRow
+	var rowErrors []dto.VacancyImportError
+	if err := json.Unmarshal(vacancyImport.Payload, &rows); err != nil {
+		log.Error("Failed to decode vacancy import payload", slog.Any("error", err))
+		s.finishImport(ctx, vacancyImport, model.VacancyImportStatusFailed)
+		return
+	}
+	if err := json.Unmarshal(vacancyImport.Errors, &rowErrors); err != nil {
+		log.Error("Failed to decode vacancy import errors", slog.Any("error", err))
+		s.finishImport(ctx, vacancyImport, model.VacancyImportStatusFailed)
+		return
+	}
+
+	for i := vacancyImport.ProcessedRows; i < len(rows); i++ {
+		vacancy := rows[i].Vacancy
+		vacancy.OrganizationID = vacancyImport.OrganizationID
+		if _, err := s.vacancy.CreateVacancy(ctx, dto.CreateVacancyRequest{Vacancy: vacancy}); err != nil {
+			rowErrors = append(rowErrors, dto.VacancyImportError{Line: rows[i].Line, Error: err.Error()})
+		} else {
+			vacancyImport.CreatedRows++
+		}
+		vacancyImport.ProcessedRows = i + 1
+
+		errorsJSON, err := json.Marshal(rowErrors)
+		if err != nil {
+			log.Error("Failed to encode vacancy import errors", slog.Any("error", err))
+			s.finishImport(ctx, vacancyImport, model.VacancyImportStatusFailed)
+			return
+		}
+		vacancyImport.Errors = errorsJSON
+		if err := s.repo.SaveProgress(ctx, vacancyImport); err != nil {
+			// The import stays running and resumes from the last saved
+			// row after a restart, which may create this row again.
+			log.Error("Failed to save vacancy import progress", slog.Any("error", err))
+			return
+		}
+	}
+
+	s.finishImport(ctx, vacancyImport, model.VacancyImportStatusCompleted)
+	log.Info("Vacancy import finished", slog.Int("created", vacancyImport.CreatedRows), slog.Int("processed", vacancyImport.ProcessedRows))
+
+	payload := map[string]any{"import_id": vacancyImport.ID, "created": vacancyImport.CreatedRows}
+	body := fmt.Sprintf("%d of %d vacancies from %s were created.", vacancyImport.CreatedRows, vacancyImport.TotalRows, vacancyImport.FileName)
+	if err := s.notification.NotifyOrganization(ctx, vacancyImport.OrganizationID, model.NotificationVacancyImportFinished, "Vacancy import finished", body, payload); err != nil {
+		log.Error("Failed to notify organization", slog.Any("error", err))
+	}
+}
+
+func (s *VacancyImportService) finishImport(ctx context.Context, vacancyImport *model.VacancyImport, status string) {
+	if err := s.repo.Finish(ctx, vacancyImport, status); err != nil {
+		s.log.Error("Failed to finish vacancy import", slog.Int64("import_id", vacancyImport.ID), slog.Any("error", err))
+	}
+}
+
+// validateRow checks an imported vacancy the same way CreateVacancy would
+// and additionally requires a title, an existing category and complete
+// details.
+func (s *VacancyImportService) validateRow(ctx context.Context, organizationID int64, vacancy *dto.Vacancy) error {
+	vacancy.ID = 0
+	vacancy.OrganizationID = organizationID
+	vacancy.Title = strings.TrimSpace(vacancy.Title)
+	if vacancy.Title == "" {
+		return errors.New("title is required")
+	}
+	if vacancy.CategoryID == 0 {
+		return errors.New("category_id is required")
+	}
+	if _, err := s.category.GetCategoryByID(ctx, int(vacancy.CategoryID)); err != nil {
+		return fmt.Errorf("category_id %d: %w", vacancy.CategoryID, err)
+	}
+	for i := range vacancy.Details {
+		detail := &vacancy.Details[i]
+		detail.ID = 0
+		if strings.TrimSpace(detail.Value) == "" || (strings.TrimSpace(detail.Name) == "" && strings.TrimSpace(detail.Key) == "") {
+			return fmt.Errorf("detail %d: name and value are required", i+1)
+		}
+	}
+	if err := s.vacancy.ValidateVacancy(ctx, vacancy); err != nil {
+		return err
+	}
+	if err := s.vacancy.validateAttributes(ctx, vacancy.CategoryID, newVacancyDetails(0, vacancy.Details)); err != nil {
+		return err
+	}
+	if vacancy.Status == model.VacancyStatusPublished && vacancy.ExpiresAt != nil && !vacancy.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+	return nil
+}
+
+// vacancyImportFormat returns the requested format or the one matching the
+// file extension.
+func vacancyImportFormat(fileName, format string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(fileName), ".")
+	}
+	format = strings.ToLower(format)
+	switch format {
+	case model.VacancyImportFormatCSV, model.VacancyImportFormatXLSX, model.VacancyImportFormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported format %q, expected csv, xlsx or json", model.ErrInvalidVacancyImport, format)
+	}
+}
+
+// parseVacancyImport reads every row of the file. Rows that cannot be read
+// as a vacancy are returned as errors; an error is only returned when the
+// file as a whole cannot be read.
+func parseVacancyImport(format string, r io.Reader) ([]dto.VacancyImportRow, []dto.VacancyImportError, error) {
+	switch format {
+	case model.VacancyImportFormatJSON:
+		return parseVacancyJSON(r)
+	case model.VacancyImportFormatXLSX:
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", model.ErrInvalidVacancyImport, err)
+		}
+		defer file.Close()
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, nil, fmt.Errorf("%w: the workbook has no sheets", model.ErrInvalidVacancyImport)
+		}
+		records, err := file.GetRows(sheets[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", model.ErrInvalidVacancyImport, err)
+		}
+		return parseVacancyTable(records)
+	default:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", model.ErrInvalidVacancyImport, err)
+		}
+		return parseVacancyTable(records)
+	}
+}
+
+// parseVacancyJSON reads a JSON array of vacancies in the dto.Vacancy
+// shape.
+func parseVacancyJSON(r io.Reader) ([]dto.VacancyImportRow, []dto.VacancyImportError, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, nil, fmt.Errorf("%w: expected a JSON array of vacancies: %v", model.ErrInvalidVacancyImport, err)
+	}
+
+	var rows []dto.VacancyImportRow
+	var rowErrors []dto.VacancyImportError
+	for i, item := range items {
+		var vacancy dto.Vacancy
+		if err := json.Unmarshal(item, &vacancy); err != nil {
+			rowErrors = append(rowErrors, dto.VacancyImportError{Line: i + 1, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, dto.VacancyImportRow{Line: i + 1, Vacancy: vacancy})
+	}
+	return rows, rowErrors, nil
+}
+
+// parseVacancyTable reads CSV or XLSX records. The first record is the
+// header naming the dto.Vacancy JSON fields in any order, plus detail
+// columns. Blank records are skipped.
+func parseVacancyTable(records [][]string) ([]dto.VacancyImportRow, []dto.VacancyImportError, error) {
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%w: the file is empty", model.ErrInvalidVacancyImport)
+	}
+
+	columns := make(map[string]int, len(records[0]))
+	var details []importDetailColumn
+	for i, name := range records[0] {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if len(name) > len(vacancyImportAttributePrefix) && strings.EqualFold(name[:len(vacancyImportAttributePrefix)], vacancyImportAttributePrefix) {
+			details = append(details, importDetailColumn{index: i, key: strings.TrimSpace(name[len(vacancyImportAttributePrefix):])})
+			continue
+		}
+		if len(name) > len(vacancyImportDetailPrefix) && strings.EqualFold(name[:len(vacancyImportDetailPrefix)], vacancyImportDetailPrefix) {
+			column := importDetailColumn{index: i, name: name[len(vacancyImportDetailPrefix):]}
+			if group, detailName, ok := strings.Cut(column.name, ":"); ok {
+				column.group, column.name = strings.TrimSpace(group), detailName
+			}
+			column.name = strings.TrimSpace(column.name)
+			details = append(details, column)
+			continue
+		}
+		columns[strings.ToLower(name)] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, nil, fmt.Errorf("%w: the header must contain a title column", model.ErrInvalidVacancyImport)
+	}
+
+	var rows []dto.VacancyImportRow
+	var rowErrors []dto.VacancyImportError
+	for i, record := range records[1:] {
+		line := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+		vacancy, err := vacancyFromRecord(columns, details, record)
+		if err != nil {
+			rowErrors = append(rowErrors, dto.VacancyImportError{Line: line, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, dto.VacancyImportRow{Line: line, Vacancy: *vacancy})
+	}
+	return rows, rowErrors, nil
+}
+
+// importDetailColumn is a detail column of a CSV or XLSX header. Attribute
+// columns have a key instead of a group and name.
+type importDetailColumn struct {
+	index int
+	group string
+	name  string
+	key   string
+}
+
+func vacancyFromRecord(columns map[string]int, details []importDetailColumn, record []string) (*dto.Vacancy, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	float := func(name string) (*float64, error) {
+		value := field(name)
+		if value == "" {
+			return nil, nil
+		}
+		parsed, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", name, value)
+		}
+		return &parsed, nil
+	}
+	integer := func(name string) (*int, error) {
+		value := field(name)
+		if value == "" {
+			return nil, nil
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", name, value)
+		}
+		return &parsed, nil
+	}
+
+	vacancy := &dto.Vacancy{
+		Title:          field("title"),
+		Description:    field("description"),
+		SalaryType:     field("salary_type"),
+		SalaryCurrency: field("salary_currency"),
+		Country:        field("country"),
+		CountryCode:    field("country_code"),
+		Status:         field("status"),
+	}
+
+	var err error
+	if vacancy.SalaryFrom, err = float("salary_from"); err != nil {
+		return nil, err
+	}
+	if vacancy.SalaryTo, err = float("salary_to"); err != nil {
+		return nil, err
+	}
+	if vacancy.SalaryExact, err = float("salary_exact"); err != nil {
+		return nil, err
+	}
+	if vacancy.Latitude, err = float("latitude"); err != nil {
+		return nil, err
+	}
+	if vacancy.Longitude, err = float("longitude"); err != nil {
+		return nil, err
+	}
+	if vacancy.RegionID, err = integer("region_id"); err != nil {
+		return nil, err
+	}
+	if vacancy.CityID, err = integer("city_id"); err != nil {
+		return nil, err
+	}
+	categoryID, err := integer("category_id")
+	if err != nil {
+		return nil, err
+	}
+	if categoryID != nil {
+		vacancy.CategoryID = int64(*categoryID)
+	}
+	if value := field("expires_at"); value != "" {
+		expiresAt, err := parseImportTime(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_at %q", value)
+		}
+		vacancy.ExpiresAt = &expiresAt
+	}
+
+	for _, column := range details {
+		if column.index >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[column.index])
+		if value == "" {
+			continue
+		}
+		vacancy.Details = append(vacancy.Details, dto.VacancyDetailResponse{
+			GroupName: column.group,
+			Name:      column.name,
+			Value:     value,
+			Key:       column.key,
+		})
+	}
+	return vacancy, nil
+}
+
+// parseImportTime accepts RFC 3339 timestamps and plain dates.
+func parseImportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func toVacancyImportResponse(v model.VacancyImport) dto.VacancyImport {
+	response := dto.VacancyImport{
+		ID:            v.ID,
+		FileName:      v.FileName,
+		Format:        v.Format,
+		Status:        v.Status,
+		TotalRows:     v.TotalRows,
+		ValidRows:     v.ValidRows,
+		ProcessedRows: v.ProcessedRows,
+		CreatedRows:   v.CreatedRows,
+		Errors:        []dto.VacancyImportError{},
+		CreatedAt:     v.CreatedAt,
+		StartedAt:     v.StartedAt,
+		FinishedAt:    v.FinishedAt,
+	}
+	if len(bytes.TrimSpace(v.Errors)) > 0 {
+		json.Unmarshal(v.Errors, &response.Errors)
+	}
+	return response
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+)
+
+func TestParseVacancyTableDetailColumns(t *testing.T) {
+	rows, rowErrors, err := parseVacancyTable([][]string{
+		{"title", "detail:Schedule:Shift", "detail:Housing", "attr:experience_years", "ATTR:remote"},
+		{"Welder", "2/2", "provided", "3", ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rowErrors) != 0 || len(rows) != 1 {
+		t.Fatalf("got %d rows and errors %v, want one row", len(rows), rowErrors)
+	}
+
+	want := []dto.VacancyDetailResponse{
+		{GroupName: "Schedule", Name: "Shift", Value: "2/2"},
+		{Name: "Housing", Value: "provided"},
+		{Key: "experience_years", Value: "3"},
+	}
+	details := rows[0].Vacancy.Details
+	if len(details) != len(want) {
+		t.Fatalf("got %d details, want %d", len(details), len(want))
+	}
+	for i := range want {
+		if details[i].GroupName != want[i].GroupName || details[i].Name != want[i].Name ||
+			details[i].Value != want[i].Value || details[i].Key != want[i].Key {
+			t.Errorf("detail %d is %+v, want %+v", i, details[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+const (
+	defaultVacancyPageSize = 20
+	maxVacancyPageSize     = 100
+	// exactVacancyCountLimit is the largest approximate total that is
+	// counted exactly. Counting further on every request costs more than an
+	// exact total is worth, so larger lists get the planner's estimate.
+	exactVacancyCountLimit = 1000
+)
+
+var vacancySorts = map[string]bool{
+	dto.VacancySortNewest:     true,
+	dto.VacancySortPublished:  true,
+	dto.VacancySortSalaryAsc:  true,
+	dto.VacancySortSalaryDesc: true,
+	dto.VacancySortDistance:   true,
+	dto.VacancySortTrending:   true,
+	dto.VacancySortRelevance:  true,
+}
+
+// preparePage checks the sort, limit, cursor and total mode of the request
+// and fills in their defaults. Distance without Near and relevance without
+// Search fall back to the newest first.
+func preparePage(req *dto.ListVacancyRequest) error {
+	if req.Sort == "" {
+		req.Sort = dto.VacancySortNewest
+	}
+	if !vacancySorts[req.Sort] {
+		return fmt.Errorf("%w: %s", model.ErrInvalidVacancySort, req.Sort)
+	}
+	if (req.Sort == dto.VacancySortDistance && req.Near == nil) || (req.Sort == dto.VacancySortRelevance && req.Search == "") {
+		req.Sort = dto.VacancySortNewest
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = defaultVacancyPageSize
+	}
+	req.Limit = min(req.Limit, maxVacancyPageSize)
+	req.Offset = max(req.Offset, 0)
+
+	req.After = nil
+	if req.Cursor != "" {
+		cursor, err := decodeVacancyCursor(req.Cursor)
+		if err != nil {
+			return err
+		}
+		if cursor.Sort != req.Sort {
+			return fmt.Errorf("%w: the cursor is for another sort", model.ErrInvalidVacancyCursor)
+		}
+		req.After = cursor
+	}
+
+	switch req.Total {
+	case "":
+		req.Total = dto.VacancyTotalApproximate
+		if req.After != nil {
+			req.Total = dto.VacancyTotalNone
+		}
+	case dto.VacancyTotalExact, dto.VacancyTotalApproximate, dto.VacancyTotalNone:
+	default:
+		return fmt.Errorf("%w: %s", model.ErrInvalidVacancyTotal, req.Total)
+	}
+	return nil
+}
+
+// listPage lists a page of vacancies and the cursor of the next page, which
+// is empty on the last one.
+func (s *VacancyService) listPage(ctx context.Context, req dto.ListVacancyRequest) ([]model.Vacancy, string, error) {
+	limit := req.Limit
+	// One more vacancy than asked for tells whether there is a next page.
+	req.Limit++
+	vacancies, err := s.vacancy.List(ctx, req)
+	if err != nil || len(vacancies) <= limit {
+		return vacancies, "", err
+	}
+
+	vacancies = vacancies[:limit]
+	last := vacancies[limit-1]
+	cursor, err := encodeVacancyCursor(dto.VacancyCursor{Sort: req.Sort, Key: last.SortKey, ID: last.ID})
+	if err != nil {
+		return nil, "", err
+	}
+	return vacancies, cursor, nil
+}
+
+// countVacancies returns the total of the list in the requested mode and
+// whether it is approximate. It is nil with VacancyTotalNone. An
+// approximate total is exact up to exactVacancyCountLimit and the planner's
+// estimate, but never less than that, beyond it.
+func (s *VacancyService) countVacancies(ctx context.Context, req dto.ListVacancyRequest) (*int, bool, error) {
+	switch req.Total {
+	case dto.VacancyTotalNone:
+		return nil, false, nil
+	case dto.VacancyTotalApproximate:
+		total, err := s.vacancy.CountUpTo(ctx, req, exactVacancyCountLimit+1)
+		if err != nil {
+			return nil, false, err
+		}
+		if total <= exactVacancyCountLimit {
+			return &total, false, nil
+		}
+		estimate, err := s.vacancy.EstimateCount(ctx, req)
+		if err != nil {
+			return nil, false, err
+		}
+		estimate = max(estimate, total)
+		return &estimate, true, nil
+	}
+
+	total, err := s.vacancy.Count(ctx, req)
+	if err != nil {
+		return nil, false, err
+	}
+	return &total, false, nil
+}
+
+// encodeVacancyCursor makes an opaque cursor. Clients only pass it back.
+func encodeVacancyCursor(cursor dto.VacancyCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeVacancyCursor(value string) (*dto.VacancyCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, model.ErrInvalidVacancyCursor
+	}
+	var cursor dto.VacancyCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Key == "" || cursor.ID <= 0 {
+		return nil, model.ErrInvalidVacancyCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/testdb"
+)
+
+func TestVacancyCursorRoundTrip(t *testing.T) {
+	cursor := dto.VacancyCursor{Sort: dto.VacancySortSalaryDesc, Key: "1500.50", ID: 42}
+	value, err := encodeVacancyCursor(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeVacancyCursor(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decoded != cursor {
+		t.Fatalf("got %+v, want %+v", *decoded, cursor)
+	}
+}
+
+func TestDecodeVacancyCursorRejectsInvalid(t *testing.T) {
+	valid, err := encodeVacancyCursor(dto.VacancyCursor{Sort: dto.VacancySortNewest, Key: "2024-01-01T00:00:00Z", ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noKey, err := encodeVacancyCursor(dto.VacancyCursor{Sort: dto.VacancySortNewest, ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noID, err := encodeVacancyCursor(dto.VacancyCursor{Sort: dto.VacancySortNewest, Key: "2024-01-01T00:00:00Z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"not base64", "not a cursor!"},
+		{"not json", "bm90IGpzb24"},
+		{"truncated", valid[:len(valid)/2]},
+		{"without key", noKey},
+		{"without id", noID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeVacancyCursor(tt.value); !errors.Is(err, model.ErrInvalidVacancyCursor) {
+				t.Fatalf("got %v, want %v", err, model.ErrInvalidVacancyCursor)
+			}
+		})
+	}
+}
+
+func TestPreparePageWithCursor(t *testing.T) {
+	cursor, err := encodeVacancyCursor(dto.VacancyCursor{Sort: dto.VacancySortSalaryAsc, Key: "300", ID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := dto.ListVacancyRequest{Sort: dto.VacancySortSalaryAsc, Cursor: cursor, Limit: 500}
+	if err := preparePage(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.After == nil || req.After.ID != 7 || req.After.Key != "300" {
+		t.Fatalf("after %+v, want the decoded cursor", req.After)
+	}
+	if req.Limit != maxVacancyPageSize {
+		t.Fatalf("limit %d, want %d", req.Limit, maxVacancyPageSize)
+	}
+	if req.Total != dto.VacancyTotalNone {
+		t.Fatalf("total %q, want %q for a later page", req.Total, dto.VacancyTotalNone)
+	}
+
+	req = dto.ListVacancyRequest{Sort: dto.VacancySortNewest, Cursor: cursor}
+	if err := preparePage(&req); !errors.Is(err, model.ErrInvalidVacancyCursor) {
+		t.Fatalf("cursor of another sort: got %v, want %v", err, model.ErrInvalidVacancyCursor)
+	}
+}
+
+func TestCountVacanciesApproximate(t *testing.T) {
+	tests := []struct {
+		name            string
+		counted         int
+		estimate        int
+		want            int
+		wantApproximate bool
+	}{
+		{"small list is exact", 12, 50000, 12, false},
+		{"large list is estimated", exactVacancyCountLimit + 1, 50000, 50000, true},
+		{"estimate below the count", exactVacancyCountLimit + 1, 10, exactVacancyCountLimit + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testdb.Open(t, func(query string, _ []driver.Value) (*testdb.Result, error) {
+				switch {
+				case strings.HasPrefix(query, "SELECT COUNT(*) FROM (SELECT"):
+					return &testdb.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(tt.counted)}}}, nil
+				case strings.HasPrefix(query, "EXPLAIN"):
+					plan := fmt.Sprintf(`[{"Plan": {"Plan Rows": %d}}]`, tt.estimate)
+					return &testdb.Result{Columns: []string{"plan"}, Rows: [][]driver.Value{{plan}}}, nil
+				}
+				return nil, nil
+			})
+			service := newTestVacancyService(db)
+
+			total, approximate, err := service.countVacancies(context.Background(), dto.ListVacancyRequest{Total: dto.VacancyTotalApproximate})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *total != tt.want || approximate != tt.wantApproximate {
+				t.Fatalf("got %d (approximate %t), want %d (approximate %t)", *total, approximate, tt.want, tt.wantApproximate)
+			}
+		})
+	}
+}
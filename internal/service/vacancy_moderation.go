@@ -0,0 +1,624 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+// Scores of the risk rules. With the default review score of 50 a single
+// payment or passport phrase is enough to send a vacancy to moderation,
+// while a high salary or a contact link only is when combined with
+// something else.
+const (
+	upfrontPaymentScore    = 60
+	passportScore          = 60
+	unrealisticSalaryScore = 30
+	externalContactsScore  = 20
+	blacklistScore         = 100
+)
+
+// flaggedReason is shown to employers when the risk rules send their
+// vacancy to moderation. The matched rules are only shown to moderators.
+const flaggedReason = "Sent to moderation by automatic checks"
+
+// upfrontPaymentPhrases ask the candidate to pay before starting work.
+var upfrontPaymentPhrases = []string{
+	"предоплат", "оплата за оформление", "оплатить оформление", "оплата оформления",
+	"оплата за визу", "оплатить визу", "оплата визы за счет кандидата", "внести залог",
+	"залог за", "взнос за", "регистрационный взнос", "оплата услуг агентства",
+	"оплата за трудоустройство", "платное трудоустройство",
+	"алдын ала төлем", "oldindan to'lov", "алдын ала төлөм",
+	"upfront fee", "upfront payment", "registration fee", "processing fee",
+	"placement fee", "pay for your visa", "pay for the visa", "deposit required",
+}
+
+// passportPhrases announce that the employer keeps the worker's passport.
+var passportPhrases = []string{
+	"паспорт остается у работодателя", "паспорт хранится у работодателя",
+	"паспорт забирают", "паспорт забирается", "сдать паспорт", "паспорт сдается",
+	"оставить паспорт", "изъятие паспорта", "паспорт на хранение",
+	"keep your passport", "passport will be kept", "passport will be held",
+	"hand over your passport", "surrender your passport",
+}
+
+var (
+	urlPattern      = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+|\b(?:t\.me|wa\.me|telegram\.me)/[^\s<>"]+`)
+	emailPattern    = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`)
+	handlePattern   = regexp.MustCompile(`(?i)(?:^|\s)@[a-z0-9_]{5,}`)
+	nonDigitPattern = regexp.MustCompile(`\D`)
+	// phoneCandidatePattern finds runs of digits and phone punctuation.
+	// Salaries, ranges and dates match it too; isPhoneNumber tells them
+	// apart.
+	phoneCandidatePattern = regexp.MustCompile(`\+?\d[\d\s().-]{8,}\d`)
+)
+
+// phonePrefixes are the national prefixes of numbers written without a
+// "+", with the number of digits of a full number.
+var phonePrefixes = []struct {
+	prefix string
+	digits int
+}{
+	{"8", 11}, {"7", 11}, {"998", 12}, {"996", 12},
+}
+
+// riskRule scores one kind of risk in the vacancy text. text is the
+// normalized title, description and details.
+type riskRule func(ctx context.Context, vacancy *model.Vacancy, text string) ([]model.RiskFlag, error)
+
+func (s *VacancyService) riskRules() []riskRule {
+	return []riskRule{upfrontPaymentRule, passportRule, s.salaryRule, externalContactsRule, s.blacklistRule}
+}
+
+// assessRisk runs every risk rule on the vacancy and its detail texts.
+func (s *VacancyService) assessRisk(ctx context.Context, vacancy *model.Vacancy, details []string) (*model.RiskAssessment, error) {
+	text := normalizeRiskText(vacancy.Title + "\n" + vacancy.Description + "\n" + strings.Join(details, "\n"))
+
+	assessment := &model.RiskAssessment{}
+	for _, rule := range s.riskRules() {
+		flags, err := rule(ctx, vacancy, text)
+		if err != nil {
+			return nil, err
+		}
+		for _, flag := range flags {
+			assessment.Score += flag.Score
+			assessment.Flags = append(assessment.Flags, flag)
+		}
+	}
+	return assessment, nil
+}
+
+func (s *VacancyService) isHighRisk(assessment *model.RiskAssessment) bool {
+	threshold := s.cfg.Moderation.ReviewScore
+	if threshold <= 0 {
+		threshold = 50
+	}
+	return assessment.Score >= threshold
+}
+
+// assessmentWrite sets how the risk assessment of a created or edited
+// vacancy is stored. A high-risk live vacancy is taken off the site into
+// the moderation queue, and vacancies in the queue get a log entry for the
+// moderators. Other vacancies only get their risk score.
+func assessmentWrite(write *model.VacancyWrite, vacancy *model.Vacancy, assessment *model.RiskAssessment, flagged bool) {
+	write.RiskScore = assessment.Score
+	entry := &model.ModerationLogEntry{
+		VacancyID: vacancy.ID,
+		Action:    model.ModerationActionSubmitted,
+		RiskScore: assessment.Score,
+		Flags:     assessment.Flags,
+	}
+	if flagged {
+		entry.Action = model.ModerationActionFlagged
+		entry.Reason = flaggedReason
+	}
+
+	switch {
+	case flagged && (vacancy.Status == model.VacancyStatusPublished || vacancy.Status == model.VacancyStatusPaused):
+		write.Moderation = entry
+		write.ModerationFrom = vacancy.Status
+		write.ModerationTo = model.VacancyStatusPendingModeration
+	case vacancy.Status == model.VacancyStatusPendingModeration:
+		write.Moderation = entry
+	}
+}
+
+// reportModeration announces a vacancy the stored write sent to moderation.
+func (s *VacancyService) reportModeration(ctx context.Context, vacancy *model.Vacancy, write model.VacancyWrite) {
+	if write.ModerationTo == "" {
+		return
+	}
+	s.log.Info("Vacancy sent to moderation", slog.Int64("id", vacancy.ID), slog.Int("risk_score", write.RiskScore))
+	s.notifyModeration(ctx, vacancy, write.Moderation)
+}
+
+// submitVacancy publishes the vacancy or sends it to moderation as the
+// employer asked. High-risk vacancies always go to moderation.
+func (s *VacancyService) submitVacancy(ctx context.Context, vacancy *model.Vacancy, req dto.ChangeVacancyStatusRequest) (*dto.Vacancy, error) {
+	details, err := s.detail.GetByVacancyID(ctx, vacancy.ID)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, 0, len(details))
+	for _, detail := range details {
+		texts = append(texts, detail.GroupName, detail.Name, detail.Value)
+	}
+	assessment, err := s.assessRisk(ctx, vacancy, texts)
+	if err != nil {
+		return nil, err
+	}
+	flagged := s.isHighRisk(assessment)
+
+	to := req.Status
+	if flagged {
+		to = model.VacancyStatusPendingModeration
+	}
+
+	var changed bool
+	if to == model.VacancyStatusPublished {
+		publishedAt, expiresAt, err := s.publicationWindow(req.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		changed, err = s.vacancy.UpdateStatus(ctx, vacancy.ID, vacancy.Status, to, publishedAt, expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			if err := s.moderation.SetRiskScore(ctx, vacancy.ID, assessment.Score); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		entry := &model.ModerationLogEntry{
+			VacancyID: vacancy.ID,
+			Action:    model.ModerationActionSubmitted,
+			RiskScore: assessment.Score,
+			Flags:     assessment.Flags,
+		}
+		if flagged {
+			entry.Action = model.ModerationActionFlagged
+			entry.Reason = flaggedReason
+		}
+		changed, err = s.moderation.Record(ctx, entry, vacancy.Status, to, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !changed {
+		return nil, fmt.Errorf("%w: vacancy changed concurrently", model.ErrInvalidVacancyTransition)
+	}
+
+	s.log.Info("Vacancy status changed", slog.Int64("id", vacancy.ID), slog.String("from", vacancy.Status), slog.String("to", to), slog.Int("risk_score", assessment.Score))
+	return s.GetVacancyByID(ctx, vacancy.ID, VacancyViewer{OrganizationID: vacancy.OrganizationID})
+}
+
+// ListModerationQueue lists vacancies waiting for a moderator, riskiest
+// first, with the flags of their latest assessment.
+func (s *VacancyService) ListModerationQueue(ctx context.Context, limit, offset int) (*dto.ModerationQueue, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	vacancies, total, err := s.moderation.ListQueue(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.toVacancyResponses(ctx, vacancies, nil)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(vacancies))
+	for _, v := range vacancies {
+		ids = append(ids, v.ID)
+	}
+	assessments, err := s.moderation.LatestAssessments(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &dto.ModerationQueue{Items: make([]dto.ModerationQueueItem, 0, len(vacancies)), Total: total}
+	for i, v := range vacancies {
+		item := dto.ModerationQueueItem{Vacancy: responses[i], RiskScore: v.RiskScore, Flags: []dto.RiskFlag{}}
+		if assessment, ok := assessments[v.ID]; ok {
+			item.Flags = toRiskFlagResponses(assessment.Flags)
+		}
+		queue.Items = append(queue.Items, item)
+	}
+	return queue, nil
+}
+
+// ModerateVacancy applies a moderator decision to a vacancy in the queue:
+// approving publishes it, rejecting closes it for good and requesting
+// changes hands it back to the employer. The employer is notified with the
+// reason.
+func (s *VacancyService) ModerateVacancy(ctx context.Context, moderatorID, id int64, action string, req dto.ModerationDecision) (*dto.Vacancy, error) {
+	reason := strings.TrimSpace(req.Reason)
+
+	var to string
+	switch action {
+	case model.ModerationActionApproved:
+		to = model.VacancyStatusPublished
+	case model.ModerationActionRejected:
+		to = model.VacancyStatusRejected
+	case model.ModerationActionChangesRequested:
+		to = model.VacancyStatusChangesRequested
+	default:
+		return nil, fmt.Errorf("%w: %q", model.ErrInvalidModerationAction, action)
+	}
+	if reason == "" && action != model.ModerationActionApproved {
+		return nil, model.ErrModerationReasonMissing
+	}
+
+	vacancy, err := s.vacancy.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if vacancy == nil {
+		return nil, model.ErrVacancyNotFound
+	}
+	if vacancy.Status != model.VacancyStatusPendingModeration {
+		return nil, fmt.Errorf("%w: vacancy is %s, not pending moderation", model.ErrInvalidVacancyTransition, vacancy.Status)
+	}
+
+	publishedAt, expiresAt := vacancy.PublishedAt, vacancy.ExpiresAt
+	if to == model.VacancyStatusPublished {
+		publishedAt, expiresAt, err = s.publicationWindow(nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entry := &model.ModerationLogEntry{
+		VacancyID:   id,
+		ModeratorID: &moderatorID,
+		Action:      action,
+		Reason:      reason,
+		RiskScore:   vacancy.RiskScore,
+	}
+	changed, err := s.moderation.Record(ctx, entry, vacancy.Status, to, publishedAt, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, fmt.Errorf("%w: vacancy changed concurrently", model.ErrInvalidVacancyTransition)
+	}
+
+	s.log.Info("Vacancy moderated", slog.Int64("id", id), slog.Int64("moderator_id", moderatorID), slog.String("action", action))
+	s.notifyModeration(ctx, vacancy, entry)
+	return s.GetVacancyByID(ctx, id, VacancyViewer{OrganizationID: vacancy.OrganizationID})
+}
+
+// GetModerationLog returns the moderation history of a vacancy. Employers
+// see it for their own vacancies without the risk details; moderators see
+// everything.
+func (s *VacancyService) GetModerationLog(ctx context.Context, id, organizationID int64, moderator bool) ([]dto.ModerationLogEntry, error) {
+	if !moderator {
+		if _, err := s.ownedVacancy(ctx, id, organizationID); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := s.moderation.ListLog(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ModerationLogEntry, 0, len(entries))
+	for _, e := range entries {
+		entry := dto.ModerationLogEntry{
+			ID:        e.ID,
+			Action:    e.Action,
+			Reason:    e.Reason,
+			CreatedAt: e.CreatedAt,
+		}
+		if moderator {
+			score := e.RiskScore
+			entry.ModeratorID = e.ModeratorID
+			entry.RiskScore = &score
+			entry.Flags = toRiskFlagResponses(e.Flags)
+		}
+		response = append(response, entry)
+	}
+	return response, nil
+}
+
+func (s *VacancyService) ListBlacklist(ctx context.Context) ([]dto.BlacklistEntry, error) {
+	entries, err := s.moderation.ListBlacklist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]dto.BlacklistEntry, 0, len(entries))
+	for _, e := range entries {
+		response = append(response, toBlacklistEntryResponse(e))
+	}
+	return response, nil
+}
+
+// AddBlacklistEntry stores a phrase, link domain, phone number or e-mail
+// that flags every vacancy containing it. Values are normalized the way
+// they are matched.
+func (s *VacancyService) AddBlacklistEntry(ctx context.Context, req dto.CreateBlacklistEntry) (*dto.BlacklistEntry, error) {
+	value := normalizeBlacklistValue(req.Kind, req.Value)
+	if value == "" {
+		return nil, fmt.Errorf("%w: value is required", model.ErrInvalidBlacklistEntry)
+	}
+	switch req.Kind {
+	case model.BlacklistPhrase, model.BlacklistDomain, model.BlacklistPhone, model.BlacklistEmail:
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", model.ErrInvalidBlacklistEntry, req.Kind)
+	}
+
+	entry := &model.BlacklistEntry{Kind: req.Kind, Value: value, Reason: strings.TrimSpace(req.Reason)}
+	if err := s.moderation.AddBlacklistEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+	response := toBlacklistEntryResponse(*entry)
+	return &response, nil
+}
+
+func (s *VacancyService) DeleteBlacklistEntry(ctx context.Context, id int64) error {
+	deleted, err := s.moderation.DeleteBlacklistEntry(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return model.ErrBlacklistEntryNotFound
+	}
+	return nil
+}
+
+func (s *VacancyService) notifyModeration(ctx context.Context, vacancy *model.Vacancy, entry *model.ModerationLogEntry) {
+	var title, body string
+	switch entry.Action {
+	case model.ModerationActionFlagged:
+		title = "Vacancy sent to moderation"
+		body = fmt.Sprintf("Your vacancy %q will be visible again once a moderator has reviewed it.", vacancy.Title)
+	case model.ModerationActionApproved:
+		title = "Vacancy approved"
+		body = fmt.Sprintf("Your vacancy %q has been approved and published.", vacancy.Title)
+	case model.ModerationActionRejected:
+		title = "Vacancy rejected"
+		body = fmt.Sprintf("Your vacancy %q has been rejected: %s", vacancy.Title, entry.Reason)
+	case model.ModerationActionChangesRequested:
+		title = "Changes requested"
+		body = fmt.Sprintf("A moderator asks you to change your vacancy %q: %s", vacancy.Title, entry.Reason)
+	default:
+		return
+	}
+
+	payload := map[string]any{"vacancy_id": vacancy.ID, "action": entry.Action, "reason": entry.Reason}
+	if err := s.notification.NotifyOrganization(ctx, vacancy.OrganizationID, model.NotificationVacancyModerated, title, body, payload); err != nil {
+		s.log.Error("Failed to notify organization", slog.Int64("vacancy_id", vacancy.ID), slog.Any("error", err))
+	}
+}
+
+func upfrontPaymentRule(_ context.Context, _ *model.Vacancy, text string) ([]model.RiskFlag, error) {
+	if phrase := findPhrase(text, upfrontPaymentPhrases); phrase != "" {
+		return []model.RiskFlag{{Rule: model.RiskRuleUpfrontPayment, Score: upfrontPaymentScore, Detail: phrase}}, nil
+	}
+	return nil, nil
+}
+
+func passportRule(_ context.Context, _ *model.Vacancy, text string) ([]model.RiskFlag, error) {
+	if phrase := findPhrase(text, passportPhrases); phrase != "" {
+		return []model.RiskFlag{{Rule: model.RiskRulePassport, Score: passportScore, Detail: phrase}}, nil
+	}
+	return nil, nil
+}
+
+// salaryRule flags salaries far above what the category usually pays.
+func (s *VacancyService) salaryRule(ctx context.Context, vacancy *model.Vacancy, _ string) ([]model.RiskFlag, error) {
+	amount := vacancy.SalaryExact
+	if amount == nil {
+		amount = vacancy.SalaryTo
+	}
+	if amount == nil {
+		amount = vacancy.SalaryFrom
+	}
+	if amount == nil {
+		return nil, nil
+	}
+
+	base, median, samples, err := s.moderation.SalaryStats(ctx, *amount, vacancy.SalaryType, vacancy.SalaryCurrency, vacancy.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		return nil, nil
+	}
+
+	cfg := s.cfg.Moderation
+	if samples >= cfg.SalarySamples && median > 0 {
+		if *base > median*cfg.SalaryFactor {
+			detail := fmt.Sprintf("%.0f %s a month is %.1f times the category median", *base, model.BaseCurrency, *base/median)
+			return []model.RiskFlag{{Rule: model.RiskRuleUnrealisticSalary, Score: unrealisticSalaryScore, Detail: detail}}, nil
+		}
+		return nil, nil
+	}
+	if cfg.MaxSalary > 0 && *base > cfg.MaxSalary {
+		detail := fmt.Sprintf("%.0f %s a month is above %.0f", *base, model.BaseCurrency, cfg.MaxSalary)
+		return []model.RiskFlag{{Rule: model.RiskRuleUnrealisticSalary, Score: unrealisticSalaryScore, Detail: detail}}, nil
+	}
+	return nil, nil
+}
+
+// externalContactsRule flags links, messenger handles, e-mails and phone
+// numbers that take the conversation off the platform.
+func externalContactsRule(_ context.Context, _ *model.Vacancy, text string) ([]model.RiskFlag, error) {
+	for _, pattern := range []*regexp.Regexp{urlPattern, emailPattern, handlePattern} {
+		if match := pattern.FindString(text); match != "" {
+			return []model.RiskFlag{{Rule: model.RiskRuleExternalContacts, Score: externalContactsScore, Detail: strings.TrimSpace(match)}}, nil
+		}
+	}
+	if phones := findPhones(text); len(phones) > 0 {
+		return []model.RiskFlag{{Rule: model.RiskRuleExternalContacts, Score: externalContactsScore, Detail: phones[0]}}, nil
+	}
+	return nil, nil
+}
+
+// findPhones returns the phone numbers written in text. Candidates are
+// split at " - " so that ranges such as "1500 - 2000" are not read as one
+// number.
+func findPhones(text string) []string {
+	var phones []string
+	for _, candidate := range phoneCandidatePattern.FindAllString(text, -1) {
+		for _, part := range strings.Split(candidate, " - ") {
+			if part = strings.TrimSpace(part); isPhoneNumber(part) {
+				phones = append(phones, part)
+			}
+		}
+	}
+	return phones
+}
+
+// isPhoneNumber reports whether s has the shape of a phone number: 10 to 15
+// digits, starting with "+" or a known national prefix, and not two long
+// numbers joined by a hyphen like "70000-800000".
+func isPhoneNumber(s string) bool {
+	digits := nonDigitPattern.ReplaceAllString(s, "")
+	if len(digits) < 10 || len(digits) > 15 {
+		return false
+	}
+	if parts := strings.Split(s, "-"); len(parts) == 2 &&
+		len(nonDigitPattern.ReplaceAllString(parts[0], "")) >= 4 && len(nonDigitPattern.ReplaceAllString(parts[1], "")) >= 4 {
+		return false
+	}
+	if strings.HasPrefix(s, "+") {
+		return true
+	}
+	for _, p := range phonePrefixes {
+		if strings.HasPrefix(digits, p.prefix) && len(digits) == p.digits {
+			return true
+		}
+	}
+	return false
+}
+
+// blacklistRule flags every blacklisted phrase, domain, phone number and
+// e-mail found in the vacancy.
+func (s *VacancyService) blacklistRule(ctx context.Context, _ *model.Vacancy, text string) ([]model.RiskFlag, error) {
+	entries, err := s.moderation.ListBlacklist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	domains := map[string]bool{}
+	for _, link := range urlPattern.FindAllString(text, -1) {
+		domains[linkDomain(link)] = true
+	}
+	for _, email := range emailPattern.FindAllString(text, -1) {
+		_, domain, _ := strings.Cut(email, "@")
+		domains[domain] = true
+	}
+	phones := map[string]bool{}
+	for _, phone := range findPhones(text) {
+		phones[nonDigitPattern.ReplaceAllString(phone, "")] = true
+	}
+
+	var flags []model.RiskFlag
+	for _, entry := range entries {
+		var matched bool
+		switch entry.Kind {
+		case model.BlacklistPhrase, model.BlacklistEmail:
+			matched = strings.Contains(text, entry.Value)
+		case model.BlacklistDomain:
+			for domain := range domains {
+				if domain == entry.Value || strings.HasSuffix(domain, "."+entry.Value) {
+					matched = true
+					break
+				}
+			}
+		case model.BlacklistPhone:
+			for phone := range phones {
+				if strings.HasSuffix(phone, entry.Value) || strings.HasSuffix(entry.Value, phone) {
+					matched = true
+					break
+				}
+			}
+		}
+		if matched {
+			flags = append(flags, model.RiskFlag{Rule: model.RiskRuleBlacklist, Score: blacklistScore, Detail: entry.Kind + ": " + entry.Value})
+		}
+	}
+	return flags, nil
+}
+
+// normalizeRiskText lowercases the text, folds ё into е and collapses
+// whitespace so that phrases match however they are typed.
+func normalizeRiskText(text string) string {
+	text = strings.ToLower(text)
+	text = strings.ReplaceAll(text, "ё", "е")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func findPhrase(text string, phrases []string) string {
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return phrase
+		}
+	}
+	return ""
+}
+
+// linkDomain returns the lowercased host of a link without "www.".
+func linkDomain(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+func normalizeBlacklistValue(kind, value string) string {
+	switch kind {
+	case model.BlacklistPhone:
+		return nonDigitPattern.ReplaceAllString(value, "")
+	case model.BlacklistDomain:
+		value = strings.TrimSpace(value)
+		if domain := linkDomain(value); domain != "" {
+			return domain
+		}
+		return strings.ToLower(value)
+	default:
+		return normalizeRiskText(value)
+	}
+}
+
+// detailTexts returns the texts of the details for the risk rules.
+func detailTexts(details []dto.VacancyDetailResponse) []string {
+	texts := make([]string, 0, len(details)*3)
+	for _, detail := range details {
+		texts = append(texts, detail.GroupName, detail.Name, detail.Value)
+	}
+	return texts
+}
+
+func toRiskFlagResponses(flags []model.RiskFlag) []dto.RiskFlag {
+	response := make([]dto.RiskFlag, 0, len(flags))
+	for _, f := range flags {
+		response = append(response, dto.RiskFlag{Rule: f.Rule, Score: f.Score, Detail: f.Detail})
+	}
+	return response
+}
+
+func toBlacklistEntryResponse(e model.BlacklistEntry) dto.BlacklistEntry {
+	return dto.BlacklistEntry{
+		ID:        e.ID,
+		Kind:      e.Kind,
+		Value:     e.Value,
+		Reason:    e.Reason,
+		CreatedAt: e.CreatedAt,
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExternalContactsRulePhones(t *testing.T) {
+	tests := []struct {
+		text    string
+		flagged bool
+	}{
+		{"зарплата 1500 - 2000 usd", false},
+		{"оклад 300 000 - 450 000 тенге", false},
+		{"выход на работу 01.03.2025", false},
+		{"salary 70000-800000 per year", false},
+		{"смена 12 часов, 26 смен в месяц", false},
+		{"звоните +7 701 123 45 67", true},
+		{"звоните 8 (701) 123-45-67", true},
+		{"whatsapp 87011234567", true},
+		{"тел. 998 90 123 45 67", true},
+		{"+48 512 345 678 po godzinie 18", true},
+		{"оплата 1500 - 2000, звоните +7 701 123 45 67", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			flags, err := externalContactsRule(context.Background(), nil, normalizeRiskText(tt.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(flags) > 0; got != tt.flagged {
+				t.Fatalf("flagged %v, want %v (flags %v)", got, tt.flagged, flags)
+			}
+		})
+	}
+}
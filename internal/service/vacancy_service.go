@@ -2,33 +2,98 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"math"
+	"slices"
+	"time"
 
+	"github.com/aidosgal/alem.core-service/internal/config"
 	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/lib"
 	"github.com/aidosgal/alem.core-service/internal/model"
 	"github.com/aidosgal/alem.core-service/internal/repository"
 )
 
+const (
+	defaultSearchRadiusKm = 50
+	maxSearchRadiusKm     = 1000
+)
+
+// vacancyTransitions lists the statuses an employer may move a vacancy to
+// from each status. Expired is only ever set by the expiry job; leaving
+// moderation other than by withdrawing to draft is up to moderators.
+var vacancyTransitions = map[string][]string{
+	model.VacancyStatusDraft:             {model.VacancyStatusPendingModeration, model.VacancyStatusPublished, model.VacancyStatusClosed},
+	model.VacancyStatusPendingModeration: {model.VacancyStatusDraft},
+	model.VacancyStatusChangesRequested:  {model.VacancyStatusPendingModeration, model.VacancyStatusDraft, model.VacancyStatusClosed},
+	model.VacancyStatusRejected:          {model.VacancyStatusClosed},
+	model.VacancyStatusPublished:         {model.VacancyStatusPaused, model.VacancyStatusClosed},
+	model.VacancyStatusPaused:            {model.VacancyStatusPublished, model.VacancyStatusClosed},
+	model.VacancyStatusExpired:           {model.VacancyStatusPublished, model.VacancyStatusClosed},
+	model.VacancyStatusClosed:            {},
+}
+
+// VacancyViewer identifies who is looking at vacancies: their organization
+// sees its own unpublished vacancies and the user's favorites are flagged.
+type VacancyViewer struct {
+	UserID         int64
+	OrganizationID int64
+}
+
 type VacancyService struct {
 	log          *slog.Logger
+	cfg          config.VacancyConfig
 	vacancy      *repository.VacancyRepository
 	detail       *repository.VacancyDetailRepository
+	favorite     *repository.FavoriteRepository
+	stats        *repository.VacancyStatsRepository
+	moderation   *repository.ModerationRepository
+	fingerprint  *repository.VacancyFingerprintRepository
+	translation  *repository.VacancyTranslationRepository
+	translator   Translator
+	category     *CategoryService
 	organization *OrganizationService
+	notification *NotificationService
+	currency     *CurrencyService
+	location     *LocationService
 }
 
-func NewVacancyService(log *slog.Logger, vacancy *repository.VacancyRepository, detail *repository.VacancyDetailRepository, organization *OrganizationService) *VacancyService {
+func NewVacancyService(log *slog.Logger, cfg config.VacancyConfig, vacancy *repository.VacancyRepository, detail *repository.VacancyDetailRepository, favorite *repository.FavoriteRepository, stats *repository.VacancyStatsRepository, moderation *repository.ModerationRepository, fingerprint *repository.VacancyFingerprintRepository, translation *repository.VacancyTranslationRepository, translator Translator, category *CategoryService, organization *OrganizationService, notification *NotificationService, currency *CurrencyService, location *LocationService) *VacancyService {
 	return &VacancyService{
 		log:          log,
+		cfg:          cfg,
 		vacancy:      vacancy,
 		detail:       detail,
+		favorite:     favorite,
+		stats:        stats,
+		moderation:   moderation,
+		fingerprint:  fingerprint,
+		translation:  translation,
+		translator:   translator,
+		category:     category,
 		organization: organization,
+		notification: notification,
+		currency:     currency,
+		location:     location,
 	}
 }
 
+// CreateVacancy stores a new vacancy for the organization in
+// req.Vacancy.OrganizationID. Vacancies are published straight away unless
+// the request asks for a draft or for moderation, or the risk rules send
+// them to the moderation queue. A near-duplicate of another vacancy of the
+// organization is refused.
 func (s *VacancyService) CreateVacancy(ctx context.Context, req dto.CreateVacancyRequest) (*dto.CreateVacancyResponse, error) {
-	id, err := s.vacancy.Create(ctx, &model.Vacancy{
+	if err := s.ValidateVacancy(ctx, &req.Vacancy); err != nil {
+		return nil, err
+	}
+	status := req.Vacancy.Status
+
+	vacancy := &model.Vacancy{
 		Title:          req.Vacancy.Title,
 		Description:    req.Vacancy.Description,
+		PrimaryLocale:  req.Vacancy.PrimaryLocale,
 		SalaryFrom:     req.Vacancy.SalaryFrom,
 		SalaryTo:       req.Vacancy.SalaryTo,
 		SalaryExact:    req.Vacancy.SalaryExact,
@@ -37,64 +102,141 @@ func (s *VacancyService) CreateVacancy(ctx context.Context, req dto.CreateVacanc
 		OrganizationID: req.Vacancy.OrganizationID,
 		CategoryID:     req.Vacancy.CategoryID,
 		Country:        req.Vacancy.Country,
-	})
+		CountryCode:    req.Vacancy.CountryCode,
+		RegionID:       req.Vacancy.RegionID,
+		CityID:         req.Vacancy.CityID,
+		Latitude:       req.Vacancy.Latitude,
+		Longitude:      req.Vacancy.Longitude,
+	}
+	translations, err := newVacancyTranslations(vacancy.PrimaryLocale, req.Vacancy.Translations)
 	if err != nil {
 		return nil, err
 	}
+	details := newVacancyDetails(0, req.Vacancy.Details)
+	if err := s.validateAttributes(ctx, vacancy.CategoryID, details); err != nil {
+		return nil, err
+	}
+	fingerprint, duplicate, err := s.checkDuplicate(ctx, vacancy)
+	if err != nil {
+		return nil, err
+	}
+	texts := detailTexts(req.Vacancy.Details)
+	for _, t := range translations {
+		texts = append(texts, t.Title, t.Description)
+	}
+	assessment, err := s.assessRisk(ctx, vacancy, texts)
+	if err != nil {
+		return nil, err
+	}
+	flagged := s.isHighRisk(assessment)
+	if flagged && status == model.VacancyStatusPublished {
+		status = model.VacancyStatusPendingModeration
+	}
 
-	req.Vacancy.ID = id
-
-	// Create details
-	var details []dto.VacancyDetailResponse
-	for _, detail := range req.Vacancy.Details {
-		detailID, err := s.detail.Create(ctx, &model.VacancyDetail{
-			GroupName: detail.GroupName,
-			Name:      detail.Name,
-			Value:     detail.Value,
-			IconURL:   &detail.IconURL,
-			VacancyID: id,
-		})
+	var publishedAt, expiresAt *time.Time
+	if status == model.VacancyStatusPublished {
+		publishedAt, expiresAt, err = s.publicationWindow(req.Vacancy.ExpiresAt)
 		if err != nil {
 			return nil, err
 		}
-		detail.ID = detailID
-		details = append(details, detail)
 	}
+	vacancy.Status = status
+	vacancy.PublishedAt = publishedAt
+	vacancy.ExpiresAt = expiresAt
+
+	// The vacancy is stored with its details, translations, fingerprint and
+	// risk assessment in one transaction, so it is never left half-created.
+	write := model.VacancyWrite{Details: details, Translations: translations}
+	assessmentWrite(&write, vacancy, assessment, flagged)
+	fingerprintWrite(&write, fingerprint, duplicate)
+	if err := s.vacancy.Create(ctx, vacancy, write); err != nil {
+		return nil, err
+	}
+	s.logDuplicate(vacancy.ID, duplicate)
 
-	req.Vacancy.Details = details
+	req.Vacancy.ID = vacancy.ID
+	req.Vacancy.Locale = vacancy.PrimaryLocale
+	req.Vacancy.Status = status
+	req.Vacancy.PublishedAt = publishedAt
+	req.Vacancy.ExpiresAt = expiresAt
+	req.Vacancy.Details = toVacancyDetailResponses(details)
 	return &dto.CreateVacancyResponse{Vacancy: req.Vacancy}, nil
 }
 
-func (s *VacancyService) GetVacancyByID(ctx context.Context, id int64) (*dto.Vacancy, error) {
+// ValidateVacancy checks a vacancy about to be created and normalizes its
+// status, primary locale, salary and location in place.
+func (s *VacancyService) ValidateVacancy(ctx context.Context, vacancy *dto.Vacancy) error {
+	if vacancy.Status == "" {
+		vacancy.Status = model.VacancyStatusPublished
+	}
+	if vacancy.PrimaryLocale == "" {
+		vacancy.PrimaryLocale = lib.DefaultLocale
+	}
+	if !lib.IsSupportedLocale(vacancy.PrimaryLocale) {
+		return fmt.Errorf("%w: %s", model.ErrUnsupportedLocale, vacancy.PrimaryLocale)
+	}
+	switch vacancy.Status {
+	case model.VacancyStatusDraft, model.VacancyStatusPendingModeration, model.VacancyStatusPublished:
+	default:
+		return fmt.Errorf("%w: a new vacancy cannot be %q", model.ErrInvalidVacancyStatus, vacancy.Status)
+	}
+	if err := s.validateSalary(ctx, vacancy); err != nil {
+		return err
+	}
+	return s.location.ResolveVacancyLocation(ctx, vacancy)
+}
+
+// GetVacancyByID returns nil when the vacancy does not exist or is not
+// published and does not belong to the viewer's organization. Views by
+// users outside the owning organization are counted in the vacancy stats.
+func (s *VacancyService) GetVacancyByID(ctx context.Context, id int64, viewer VacancyViewer) (*dto.Vacancy, error) {
 	vacancy, err := s.vacancy.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	details, err := s.detail.GetByVacancyID(ctx, id)
+	if vacancy == nil || (!isVacancyLive(vacancy) && vacancy.OrganizationID != viewer.OrganizationID) {
+		return nil, nil
+	}
+	if viewer.UserID != 0 && vacancy.OrganizationID != viewer.OrganizationID {
+		if err := s.stats.RecordView(ctx, id, viewer.UserID); err != nil {
+			s.log.Error("Failed to record vacancy view", slog.Int64("id", id), slog.Any("error", err))
+		}
+	}
+	favorited, err := s.favorite.FindFavorited(ctx, viewer.UserID, []int64{id})
 	if err != nil {
 		return nil, err
 	}
-	organization, err := s.organization.GetOrganization(int(vacancy.OrganizationID))
+	return s.toVacancyResponse(ctx, vacancy, favorited[id])
+}
+
+// toVacancyResponse loads the details, organization and location of the
+// vacancy and picks its text in the locale preferred by the request.
+func (s *VacancyService) toVacancyResponse(ctx context.Context, vacancy *model.Vacancy, isFavorite bool) (*dto.Vacancy, error) {
+	localized, err := s.localizeVacancies(ctx, []model.Vacancy{*vacancy})
 	if err != nil {
 		return nil, err
 	}
+	text := localized[vacancy.ID]
 
-	var detailResponses []dto.VacancyDetailResponse
-	for _, d := range details {
-		detailResponses = append(detailResponses, dto.VacancyDetailResponse{
-			ID:        d.ID,
-			GroupName: d.GroupName,
-			Name:      d.Name,
-			Value:     d.Value,
-			IconURL:   *d.IconURL,
-			VacancyID: d.VacancyID,
-		})
+	details, err := s.detail.GetByVacancyID(ctx, vacancy.ID)
+	if err != nil {
+		return nil, err
 	}
+	organization, err := s.organization.GetOrganization(int(vacancy.OrganizationID))
+	if err != nil {
+		return nil, err
+	}
+	location, err := s.location.DescribeLocation(ctx, vacancy.CountryCode, vacancy.RegionID, vacancy.CityID)
+	if err != nil {
+		return nil, err
+	}
+
+	detailResponses := toVacancyDetailResponses(details)
 
 	return &dto.Vacancy{
 		ID:             vacancy.ID,
-		Title:          vacancy.Title,
-		Description:    vacancy.Description,
+		Title:          text.Title,
+		Description:    text.Description,
 		SalaryFrom:     vacancy.SalaryFrom,
 		SalaryTo:       vacancy.SalaryTo,
 		SalaryExact:    vacancy.SalaryExact,
@@ -105,92 +247,649 @@ func (s *VacancyService) GetVacancyByID(ctx context.Context, id int64) (*dto.Vac
 		Details:        detailResponses,
 		Organization:   *organization,
 		Country:        vacancy.Country,
+		CountryCode:    vacancy.CountryCode,
+		RegionID:       vacancy.RegionID,
+		CityID:         vacancy.CityID,
+		Location:       location,
+		Latitude:       vacancy.Latitude,
+		Longitude:      vacancy.Longitude,
+		IsFavorite:     isFavorite,
+		Status:         vacancy.Status,
+		PublishedAt:    vacancy.PublishedAt,
+		ExpiresAt:      vacancy.ExpiresAt,
 		CreatedAt:      vacancy.CreatedAt,
+
+		DuplicateClusterID: vacancy.DuplicateClusterID,
+		PrimaryLocale:      vacancy.PrimaryLocale,
+		Locale:             text.Locale,
+		Version:            vacancy.Version,
 	}, nil
 }
 
+// toVacancyResponses is toVacancyResponse for a page of vacancies. The
+// details, organizations and texts of all of them are loaded at once.
+func (s *VacancyService) toVacancyResponses(ctx context.Context, vacancies []model.Vacancy, favorited map[int64]bool) ([]dto.Vacancy, error) {
+	ids := make([]int64, 0, len(vacancies))
+	for _, v := range vacancies {
+		ids = append(ids, v.ID)
+	}
+	localized, err := s.localizeVacancies(ctx, vacancies)
+	if err != nil {
+		return nil, err
+	}
+	details, err := s.detail.GetByVacancyIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	organizationIDs := make([]int, 0, len(vacancies))
+	for _, v := range vacancies {
+		organizationIDs = append(organizationIDs, int(v.OrganizationID))
+	}
+	organizations, err := s.organization.GetOrganizations(ctx, organizationIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var responseVacancies []dto.Vacancy
+	for _, v := range vacancies {
+		detailResponses := toVacancyDetailResponses(details[v.ID])
+		organization := organizations[int(v.OrganizationID)]
+
+		location, err := s.location.DescribeLocation(ctx, v.CountryCode, v.RegionID, v.CityID)
+		if err != nil {
+			return nil, err
+		}
+
+		responseVacancies = append(responseVacancies, dto.Vacancy{
+			ID:             v.ID,
+			Title:          localized[v.ID].Title,
+			Description:    localized[v.ID].Description,
+			SalaryFrom:     v.SalaryFrom,
+			SalaryTo:       v.SalaryTo,
+			SalaryExact:    v.SalaryExact,
+			SalaryType:     v.SalaryType,
+			SalaryCurrency: v.SalaryCurrency,
+			OrganizationID: v.OrganizationID,
+			CategoryID:     v.CategoryID,
+			Details:        detailResponses,
+			Organization:   organization,
+			Country:        v.Country,
+			CountryCode:    v.CountryCode,
+			RegionID:       v.RegionID,
+			CityID:         v.CityID,
+			Location:       location,
+			Latitude:       v.Latitude,
+			Longitude:      v.Longitude,
+			DistanceKm:     v.DistanceKm,
+			IsFavorite:     favorited[v.ID],
+			Status:         v.Status,
+			PublishedAt:    v.PublishedAt,
+			ExpiresAt:      v.ExpiresAt,
+			CreatedAt:      v.CreatedAt,
+
+			DuplicateClusterID: v.DuplicateClusterID,
+			Duplicates:         v.Duplicates,
+			PrimaryLocale:      v.PrimaryLocale,
+			Locale:             localized[v.ID].Locale,
+			Version:            v.Version,
+		})
+	}
+	return responseVacancies, nil
+}
+
+// GetVacancySource returns the organization's vacancy as it was written:
+// in its primary locale, with its details and the translations saved by
+// the employer.
+func (s *VacancyService) GetVacancySource(ctx context.Context, id, organizationID int64) (*dto.Vacancy, error) {
+	vacancy, err := s.ownedVacancy(ctx, id, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	details, err := s.detail.GetByVacancyID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.translation.ListByVacancy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &dto.Vacancy{
+		ID:             vacancy.ID,
+		Title:          vacancy.Title,
+		Description:    vacancy.Description,
+		SalaryFrom:     vacancy.SalaryFrom,
+		SalaryTo:       vacancy.SalaryTo,
+		SalaryExact:    vacancy.SalaryExact,
+		SalaryType:     vacancy.SalaryType,
+		SalaryCurrency: vacancy.SalaryCurrency,
+		OrganizationID: vacancy.OrganizationID,
+		CategoryID:     vacancy.CategoryID,
+		Details:        toVacancyDetailResponses(details),
+		Country:        vacancy.Country,
+		CountryCode:    vacancy.CountryCode,
+		RegionID:       vacancy.RegionID,
+		CityID:         vacancy.CityID,
+		Latitude:       vacancy.Latitude,
+		Longitude:      vacancy.Longitude,
+		Status:         vacancy.Status,
+		CreatedAt:      vacancy.CreatedAt,
+
+		PrimaryLocale: vacancy.PrimaryLocale,
+		Locale:        vacancy.PrimaryLocale,
+		Version:       vacancy.Version,
+	}
+	for _, t := range translations {
+		if !t.Machine && t.Locale != vacancy.PrimaryLocale {
+			source.Translations = append(source.Translations, toVacancyTranslationResponse(t))
+		}
+	}
+	return source, nil
+}
+
+// UpdateVacancy saves the vacancy together with its details and returns the
+// stored result. Details are reconciled against what is stored: new ones are
+// inserted, known ones updated and missing ones deleted. Every update is
+// stored as a new version of the vacancy, authored by req.AuthorID.
 func (s *VacancyService) UpdateVacancy(ctx context.Context, req dto.UpdateVacancyRequest) (*dto.UpdateVacancyResponse, error) {
-	err := s.vacancy.Update(ctx, &model.Vacancy{
+	existing, err := s.ownedVacancy(ctx, req.Vacancy.ID, req.Vacancy.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateSalary(ctx, &req.Vacancy); err != nil {
+		return nil, err
+	}
+	if err := s.location.ResolveVacancyLocation(ctx, &req.Vacancy); err != nil {
+		return nil, err
+	}
+
+	details := newVacancyDetails(req.Vacancy.ID, req.Vacancy.Details)
+	if err := s.validateAttributes(ctx, req.Vacancy.CategoryID, details); err != nil {
+		return nil, err
+	}
+
+	updated := &model.Vacancy{
 		ID:             req.Vacancy.ID,
 		Title:          req.Vacancy.Title,
 		Description:    req.Vacancy.Description,
+		PrimaryLocale:  existing.PrimaryLocale,
 		SalaryFrom:     req.Vacancy.SalaryFrom,
 		SalaryTo:       req.Vacancy.SalaryTo,
 		SalaryExact:    req.Vacancy.SalaryExact,
 		SalaryType:     req.Vacancy.SalaryType,
 		SalaryCurrency: req.Vacancy.SalaryCurrency,
-		OrganizationID: req.Vacancy.OrganizationID,
 		CategoryID:     req.Vacancy.CategoryID,
 		Country:        req.Vacancy.Country,
-	})
+		CountryCode:    req.Vacancy.CountryCode,
+		RegionID:       req.Vacancy.RegionID,
+		CityID:         req.Vacancy.CityID,
+		Latitude:       req.Vacancy.Latitude,
+		Longitude:      req.Vacancy.Longitude,
+		Status:         existing.Status,
+		OrganizationID: existing.OrganizationID,
+	}
+	if req.Vacancy.PrimaryLocale != "" {
+		if !lib.IsSupportedLocale(req.Vacancy.PrimaryLocale) {
+			return nil, fmt.Errorf("%w: %s", model.ErrUnsupportedLocale, req.Vacancy.PrimaryLocale)
+		}
+		updated.PrimaryLocale = req.Vacancy.PrimaryLocale
+	}
+	fingerprint, duplicate, err := s.checkDuplicate(ctx, updated)
+	if err != nil {
+		return nil, err
+	}
+	assessment, err := s.assessRisk(ctx, updated, detailTexts(req.Vacancy.Details))
+	if err != nil {
+		return nil, err
+	}
+	change, err := s.newVacancyChange(ctx, existing, updated, details, req.AuthorID)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, detail := range req.Vacancy.Details {
-		err := s.detail.Update(ctx, &model.VacancyDetail{
-			ID:        detail.ID,
-			GroupName: detail.GroupName,
-			Name:      detail.Name,
-			Value:     detail.Value,
-			IconURL:   &detail.IconURL,
-			VacancyID: req.Vacancy.ID,
-		})
-		if err != nil {
-			return nil, err
-		}
+	// The content, the risk assessment and a move into the moderation queue
+	// are stored together, so a flagged edit never stays live.
+	write := model.VacancyWrite{Details: details, Change: change}
+	assessmentWrite(&write, updated, assessment, s.isHighRisk(assessment))
+	fingerprintWrite(&write, fingerprint, duplicate)
+	if updated.PrimaryLocale != existing.PrimaryLocale {
+		// The text in the new primary locale is the vacancy itself now.
+		write.DeleteTranslation = updated.PrimaryLocale
+	}
+	if err := s.vacancy.UpdateWithDetails(ctx, updated, write); err != nil {
+		return nil, err
 	}
+	s.logDuplicate(updated.ID, duplicate)
+	s.reportModeration(ctx, updated, write)
 
-	return &dto.UpdateVacancyResponse{Vacancy: req.Vacancy}, nil
+	vacancy, err := s.GetVacancyByID(ctx, req.Vacancy.ID, VacancyViewer{OrganizationID: req.Vacancy.OrganizationID})
+	if err != nil {
+		return nil, err
+	}
+	if vacancy == nil {
+		return nil, model.ErrVacancyNotFound
+	}
+	return &dto.UpdateVacancyResponse{Vacancy: *vacancy}, nil
 }
 
-func (s *VacancyService) DeleteVacancy(ctx context.Context, id int64) error {
+// DeleteVacancy soft-deletes one of the organization's vacancies. It can be
+// restored within the configured restore window.
+func (s *VacancyService) DeleteVacancy(ctx context.Context, id, organizationID int64) error {
+	if _, err := s.ownedVacancy(ctx, id, organizationID); err != nil {
+		return err
+	}
 	return s.vacancy.Delete(ctx, id)
 }
 
+// RestoreVacancy brings back a vacancy deleted less than the restore window
+// ago. It keeps the status it had when it was deleted.
+func (s *VacancyService) RestoreVacancy(ctx context.Context, id, organizationID int64) (*dto.Vacancy, error) {
+	vacancy, err := s.vacancy.GetDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if vacancy == nil {
+		return nil, model.ErrVacancyNotFound
+	}
+	if vacancy.OrganizationID != organizationID {
+		return nil, model.ErrVacancyForbidden
+	}
+
+	restored, err := s.vacancy.Restore(ctx, id, time.Now().Add(-s.cfg.RestoreWindow))
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, model.ErrVacancyRestoreExpired
+	}
+
+	s.log.Info("Vacancy restored", slog.Int64("id", id))
+	return s.GetVacancyByID(ctx, id, VacancyViewer{OrganizationID: organizationID})
+}
+
+// ListVacancies lists a page of vacancies matching req. Salary filters are
+// converted from req.Currency into the base currency, and every vacancy gets
+// its salary as a monthly amount in that currency. Pages follow each other
+// by the NextCursor of the response.
 func (s *VacancyService) ListVacancies(ctx context.Context, req dto.ListVacancyRequest) (*dto.ListVacancyResponse, error) {
-	vacancies, total, err := s.vacancy.List(ctx, req)
+	if req.Currency == "" {
+		req.Currency = model.BaseCurrency
+	}
+	display, err := s.currency.Find(ctx, req.Currency)
 	if err != nil {
 		return nil, err
 	}
+	req.SalaryFrom /= display.Rate
+	req.SalaryTo /= display.Rate
 
-	var responseVacancies []dto.Vacancy
-	for _, v := range vacancies {
-		details, _ := s.detail.GetByVacancyID(ctx, v.ID)
-		var detailResponses []dto.VacancyDetailResponse
-		for _, d := range details {
-			detailResponses = append(detailResponses, dto.VacancyDetailResponse{
-				ID:        d.ID,
-				GroupName: d.GroupName,
-				Name:      d.Name,
-				Value:     d.Value,
-				IconURL:   *d.IconURL,
-				VacancyID: d.VacancyID,
-			})
-		}
-
-		organization, err := s.organization.GetOrganization(int(v.OrganizationID))
-		if err != nil {
+	if req.Near != nil {
+		if err := ValidateCoordinates(&req.Near.Latitude, &req.Near.Longitude); err != nil {
 			return nil, err
 		}
+		if req.RadiusKm <= 0 {
+			req.RadiusKm = defaultSearchRadiusKm
+		}
+		req.RadiusKm = math.Min(req.RadiusKm, maxSearchRadiusKm)
+	}
+	if err := s.resolveAttributeFilters(ctx, &req); err != nil {
+		return nil, err
+	}
+	if err := preparePage(&req); err != nil {
+		return nil, err
+	}
 
-		responseVacancies = append(responseVacancies, dto.Vacancy{
-			ID:             v.ID,
-			Title:          v.Title,
-			Description:    v.Description,
-			SalaryFrom:     v.SalaryFrom,
-			SalaryTo:       v.SalaryTo,
-			SalaryExact:    v.SalaryExact,
-			SalaryType:     v.SalaryType,
-			SalaryCurrency: v.SalaryCurrency,
-			OrganizationID: v.OrganizationID,
-			CategoryID:     v.CategoryID,
-			Details:        detailResponses,
-			Organization:   *organization,
-			Country:        v.Country,
-			CreatedAt:      v.CreatedAt,
+	vacancies, nextCursor, err := s.listPage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	total, approximate, err := s.countVacancies(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var facets *dto.VacancyFacets
+	if req.Facets {
+		if facets, err = s.vacancyFacets(ctx, req, display); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int64, 0, len(vacancies))
+	for _, v := range vacancies {
+		ids = append(ids, v.ID)
+	}
+	favorited, err := s.favorite.FindFavorited(ctx, req.ViewerUserID, ids)
+	if err != nil {
+		return nil, err
+	}
+	responseVacancies, err := s.toVacancyResponses(ctx, vacancies, favorited)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range vacancies {
+		responseVacancies[i].DisplaySalary = displaySalary(v, display)
+	}
+
+	return &dto.ListVacancyResponse{
+		Vacancie:         responseVacancies,
+		Total:            total,
+		TotalApproximate: approximate,
+		NextCursor:       nextCursor,
+		Facets:           facets,
+	}, nil
+}
+
+// ChangeStatus moves an organization's vacancy to another status, enforcing
+// vacancyTransitions. Publishing sets a fresh expiry date.
+func (s *VacancyService) ChangeStatus(ctx context.Context, id, organizationID int64, req dto.ChangeVacancyStatusRequest) (*dto.Vacancy, error) {
+	vacancy, err := s.ownedVacancy(ctx, id, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransitionVacancy(vacancy.Status, req.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", model.ErrInvalidVacancyTransition, vacancy.Status, req.Status)
+	}
+
+	if req.Status == model.VacancyStatusPublished || req.Status == model.VacancyStatusPendingModeration {
+		return s.submitVacancy(ctx, vacancy, req)
+	}
+
+	changed, err := s.vacancy.UpdateStatus(ctx, id, vacancy.Status, req.Status, vacancy.PublishedAt, vacancy.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, fmt.Errorf("%w: vacancy changed concurrently", model.ErrInvalidVacancyTransition)
+	}
+
+	s.log.Info("Vacancy status changed", slog.Int64("id", id), slog.String("from", vacancy.Status), slog.String("to", req.Status))
+	return s.GetVacancyByID(ctx, id, VacancyViewer{OrganizationID: organizationID})
+}
+
+// RunJobs expires overdue vacancies, warns employers about vacancies that
+// are about to expire and purges deleted vacancies past retention, once per
+// interval until ctx is cancelled.
+func (s *VacancyService) RunJobs(ctx context.Context) {
+	interval := s.cfg.JobInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ExpireVacancies(ctx); err != nil {
+			s.log.Error("Vacancy expiry job failed", slog.Any("error", err))
+		}
+		if err := s.PurgeDeletedVacancies(ctx); err != nil {
+			s.log.Error("Vacancy purge job failed", slog.Any("error", err))
+		}
+		if err := s.FingerprintVacancies(ctx); err != nil {
+			s.log.Error("Vacancy fingerprint job failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *VacancyService) ExpireVacancies(ctx context.Context) error {
+	expired, err := s.vacancy.ExpireDue(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range expired {
+		s.notifyOrganization(ctx, v, model.NotificationVacancyExpired,
+			"Vacancy expired",
+			fmt.Sprintf("Your vacancy %q has expired and is no longer visible to job seekers.", v.Title))
+	}
+
+	expiring, err := s.vacancy.MarkExpiring(ctx, time.Now().Add(s.cfg.ExpiryWarning))
+	if err != nil {
+		return err
+	}
+	for _, v := range expiring {
+		s.notifyOrganization(ctx, v, model.NotificationVacancyExpiring,
+			"Vacancy expires soon",
+			fmt.Sprintf("Your vacancy %q expires on %s.", v.Title, v.ExpiresAt.Format("2006-01-02")))
+	}
+
+	if len(expired) > 0 || len(expiring) > 0 {
+		s.log.Info("Vacancy expiry job finished", slog.Int("expired", len(expired)), slog.Int("warned", len(expiring)))
+	}
+	return nil
+}
+
+// PurgeDeletedVacancies permanently removes vacancies deleted longer than the
+// retention period ago. Vacancies with applications are never purged.
+func (s *VacancyService) PurgeDeletedVacancies(ctx context.Context) error {
+	if s.cfg.Retention <= 0 {
+		return nil
+	}
+	purged, err := s.vacancy.Purge(ctx, time.Now().Add(-s.cfg.Retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		s.log.Info("Deleted vacancies purged", slog.Int64("count", purged))
+	}
+	return nil
+}
+
+func (s *VacancyService) notifyOrganization(ctx context.Context, vacancy model.Vacancy, kind, title, body string) {
+	payload := map[string]any{"vacancy_id": vacancy.ID, "expires_at": vacancy.ExpiresAt}
+	if err := s.notification.NotifyOrganization(ctx, vacancy.OrganizationID, kind, title, body, payload); err != nil {
+		s.log.Error("Failed to notify organization", slog.Int64("vacancy_id", vacancy.ID), slog.Any("error", err))
+	}
+}
+
+// ownedVacancy loads the vacancy and checks that it belongs to the
+// organization.
+func (s *VacancyService) ownedVacancy(ctx context.Context, id, organizationID int64) (*model.Vacancy, error) {
+	vacancy, err := s.vacancy.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if vacancy == nil {
+		return nil, model.ErrVacancyNotFound
+	}
+	if vacancy.OrganizationID != organizationID {
+		return nil, model.ErrVacancyForbidden
+	}
+	return vacancy, nil
+}
+
+// publicationWindow returns the publication time and the expiry date for a
+// vacancy published now. Without a requested date it expires after the
+// configured TTL.
+func (s *VacancyService) publicationWindow(requested *time.Time) (*time.Time, *time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.TTL)
+	if requested != nil {
+		if !requested.After(now) {
+			return nil, nil, fmt.Errorf("%w: expires_at must be in the future", model.ErrInvalidVacancyStatus)
+		}
+		expiresAt = *requested
+	}
+	return &now, &expiresAt, nil
+}
+
+// AddFavorite bookmarks a published vacancy for the user.
+func (s *VacancyService) AddFavorite(ctx context.Context, userID, vacancyID int64) error {
+	vacancy, err := s.vacancy.GetByID(ctx, vacancyID)
+	if err != nil {
+		return err
+	}
+	if vacancy == nil || !isVacancyLive(vacancy) {
+		return model.ErrVacancyNotFound
+	}
+	return s.favorite.Add(ctx, userID, vacancyID)
+}
+
+func (s *VacancyService) RemoveFavorite(ctx context.Context, userID, vacancyID int64) error {
+	return s.favorite.Remove(ctx, userID, vacancyID)
+}
+
+// ListFavorites lists the user's bookmarked vacancies that are still
+// published.
+func (s *VacancyService) ListFavorites(ctx context.Context, userID int64, limit, offset int, cursor string) (*dto.ListVacancyResponse, error) {
+	return s.ListVacancies(ctx, dto.ListVacancyRequest{
+		FavoritesOf:  userID,
+		ViewerUserID: userID,
+		Limit:        limit,
+		Offset:       offset,
+		Cursor:       cursor,
+	})
+}
+
+// Apply records the user's application to a published vacancy.
+func (s *VacancyService) Apply(ctx context.Context, userID, vacancyID int64, req dto.ApplyVacancyRequest) (*dto.VacancyApplication, error) {
+	vacancy, err := s.vacancy.GetByID(ctx, vacancyID)
+	if err != nil {
+		return nil, err
+	}
+	if vacancy == nil || !isVacancyLive(vacancy) {
+		return nil, model.ErrVacancyNotFound
+	}
+
+	application := &model.VacancyApplication{
+		VacancyID: vacancyID,
+		UserID:    userID,
+		ResumeID:  req.ResumeID,
+	}
+	if req.VacancyVersion != nil {
+		application.VacancyVersion = *req.VacancyVersion
+	}
+	if err := s.stats.CreateApplication(ctx, application); err != nil {
+		return nil, err
+	}
+	return &dto.VacancyApplication{
+		ID:        application.ID,
+		VacancyID: application.VacancyID,
+		ResumeID:  application.ResumeID,
+		CreatedAt: application.CreatedAt.Format(time.RFC3339),
+
+		VacancyVersion: application.VacancyVersion,
+	}, nil
+}
+
+// GetVacancyStats returns the views and applications of the organization's
+// vacancy over the last days, including today.
+func (s *VacancyService) GetVacancyStats(ctx context.Context, id, organizationID int64, days int) (*dto.VacancyStats, error) {
+	if _, err := s.ownedVacancy(ctx, id, organizationID); err != nil {
+		return nil, err
+	}
+	if days <= 0 {
+		days = 30
+	}
+	if days > 365 {
+		days = 365
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, time.UTC)
+	stats, err := s.stats.Stats(ctx, id, since)
+	if err != nil {
+		return nil, err
+	}
+
+	daily := make([]dto.VacancyDailyStats, 0, len(stats.Daily))
+	for _, day := range stats.Daily {
+		daily = append(daily, dto.VacancyDailyStats{
+			Day:          day.Day.Format("2006-01-02"),
+			Views:        day.Views,
+			Applications: day.Applications,
 		})
 	}
+	return &dto.VacancyStats{
+		VacancyID:     id,
+		Days:          days,
+		Views:         stats.Views,
+		UniqueViewers: stats.UniqueViewers,
+		Applications:  stats.Applications,
+		Daily:         daily,
+	}, nil
+}
+
+// validateSalary normalizes the salary type and currency of the vacancy and
+// checks that both are supported. An empty salary type means monthly.
+func (s *VacancyService) validateSalary(ctx context.Context, vacancy *dto.Vacancy) error {
+	if vacancy.SalaryType == "" {
+		vacancy.SalaryType = model.SalaryTypeMonth
+	}
+	if !slices.Contains(model.SalaryTypes, vacancy.SalaryType) {
+		return fmt.Errorf("%w: %q", model.ErrInvalidSalaryType, vacancy.SalaryType)
+	}
+	currency, err := s.currency.Find(ctx, vacancy.SalaryCurrency)
+	if err != nil {
+		return err
+	}
+	vacancy.SalaryCurrency = currency.Code
+	return nil
+}
+
+// displaySalary converts the normalized salary of the vacancy into the
+// display currency. It returns nil when the salary could not be normalized.
+func displaySalary(vacancy model.Vacancy, currency *model.Currency) *dto.Salary {
+	if vacancy.SalaryFromBase == nil && vacancy.SalaryToBase == nil && vacancy.SalaryExactBase == nil {
+		return nil
+	}
+	convert := func(amount *float64) *float64 {
+		if amount == nil {
+			return nil
+		}
+		converted := math.Round(*amount*currency.Rate*100) / 100
+		return &converted
+	}
+	return &dto.Salary{
+		From:     convert(vacancy.SalaryFromBase),
+		To:       convert(vacancy.SalaryToBase),
+		Exact:    convert(vacancy.SalaryExactBase),
+		Currency: currency.Code,
+		Period:   model.SalaryTypeMonth,
+	}
+}
+
+func toVacancyDetailResponses(details []model.VacancyDetail) []dto.VacancyDetailResponse {
+	responses := []dto.VacancyDetailResponse{}
+	for _, d := range details {
+		response := dto.VacancyDetailResponse{
+			ID:        d.ID,
+			GroupName: d.GroupName,
+			Name:      d.Name,
+			Value:     d.Value,
+			VacancyID: d.VacancyID,
+		}
+		if d.IconURL != nil {
+			response.IconURL = *d.IconURL
+		}
+		if d.AttributeKey != nil {
+			response.Key = *d.AttributeKey
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// iconURL stores an empty icon as NULL.
+func iconURL(url string) *string {
+	if url == "" {
+		return nil
+	}
+	return &url
+}
+
+func canTransitionVacancy(from, to string) bool {
+	for _, status := range vacancyTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
 
-	return &dto.ListVacancyResponse{Vacancie: responseVacancies, Total: total}, nil
+func isVacancyLive(vacancy *model.Vacancy) bool {
+	return vacancy.Status == model.VacancyStatusPublished && (vacancy.ExpiresAt == nil || vacancy.ExpiresAt.After(time.Now()))
 }
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aidosgal/alem.core-service/internal/config"
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/repository"
+	"github.com/aidosgal/alem.core-service/internal/testdb"
+)
+
+// listPageSizes are the page sizes the list query counts are compared at.
+var listPageSizes = []int{1, 10, 50}
+
+// vacancyRows answers the vacancy list with a page of size vacancies and
+// everything loaded for them with no rows, which sends the same queries as
+// rows would.
+func vacancyRows(size *int) testdb.Handler {
+	return func(query string, _ []driver.Value) (*testdb.Result, error) {
+		switch {
+		case strings.HasPrefix(query, "SELECT code, name, rate, updated_at FROM currencies"):
+			return &testdb.Result{
+				Columns: []string{"code", "name", "rate", "updated_at"},
+				Rows:    [][]driver.Value{{"KZT", "Tenge", 1.0, time.Now()}},
+			}, nil
+		case strings.HasPrefix(query, "SELECT COUNT(*) FROM vacancies"):
+			return &testdb.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(*size)}}}, nil
+		case strings.HasPrefix(query, "SELECT id, title,"):
+			result := &testdb.Result{Columns: selectedColumns(query)}
+			for i := 1; i <= *size; i++ {
+				values := map[string]driver.Value{
+					"id": int64(i), "title": "Title", "description": "Description", "primary_locale": "ru",
+					"salary_type": model.SalaryTypeMonth, "salary_currency": "KZT", "organization_id": int64(1),
+					"category_id": int64(1), "status": model.VacancyStatusPublished, "risk_score": int64(0),
+					"created_at": "2024-01-01T00:00:00Z", "version": int64(1), "sort_key": "2024-01-01T00:00:00Z",
+					"duplicates": int64(0),
+				}
+				row := make([]driver.Value, len(result.Columns))
+				for j, column := range result.Columns {
+					row[j] = values[column]
+				}
+				result.Rows = append(result.Rows, row)
+			}
+			return result, nil
+		case strings.HasPrefix(query, "SELECT"):
+			return &testdb.Result{}, nil
+		}
+		return nil, nil
+	}
+}
+
+// selectedColumns names the columns of the outermost SELECT of query: the
+// alias of an expression, or the column itself.
+func selectedColumns(query string) []string {
+	list := strings.TrimPrefix(query, "SELECT ")
+	var columns []string
+	depth, start := 0, 0
+	for i := 0; i < len(list); i++ {
+		switch {
+		case list[i] == '(':
+			depth++
+		case list[i] == ')':
+			depth--
+		case depth == 0 && list[i] == ',':
+			columns = append(columns, list[start:i])
+			start = i + 1
+		case depth == 0 && strings.HasPrefix(list[i:], " FROM "):
+			columns = append(columns, list[start:i])
+			for j, column := range columns {
+				fields := strings.Fields(column)
+				columns[j] = fields[len(fields)-1]
+			}
+			return columns
+		}
+	}
+	return nil
+}
+
+func newTestVacancyService(db *testdb.DB) *VacancyService {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	vacancy := repository.NewVacancyRepository(log, db.DB)
+	resume := repository.NewResumeRepository(log, db.DB)
+	category := NewCategoryService(repository.NewCategoryRepository(db.DB), repository.NewCategoryAttributeRepository(log, db.DB), vacancy, resume, log)
+	user := NewUserService(log, repository.NewUserRepository(log, db.DB))
+	return NewVacancyService(log, config.VacancyConfig{}, vacancy,
+		repository.NewVacancyDetailRepository(log, db.DB),
+		repository.NewFavoriteRepository(log, db.DB),
+		repository.NewVacancyStatsRepository(log, db.DB),
+		repository.NewModerationRepository(log, db.DB),
+		repository.NewVacancyFingerprintRepository(log, db.DB),
+		repository.NewVacancyTranslationRepository(log, db.DB),
+		NoopTranslator{},
+		category,
+		NewOrganizationService(log, repository.NewOrganizationRepository(log, db.DB), user),
+		NewNotificationService(log, repository.NewNotificationRepository(log, db.DB)),
+		NewCurrencyService(log, repository.NewCurrencyRepository(log, db.DB)),
+		NewLocationService(log, repository.NewLocationRepository(log, db.DB), vacancy),
+	)
+}
+
+// TestListVacanciesQueryCount checks that a page of vacancies is loaded
+// with the same number of queries whatever its size.
+func TestListVacanciesQueryCount(t *testing.T) {
+	var size int
+	db := testdb.Open(t, vacancyRows(&size))
+	service := newTestVacancyService(db)
+	ctx := context.Background()
+	req := dto.ListVacancyRequest{Total: dto.VacancyTotalExact, ViewerUserID: 1}
+
+	want := -1
+	for _, size = range listPageSizes {
+		db.Reset()
+		req.Limit = size
+		response, err := service.ListVacancies(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Vacancie) != size {
+			t.Fatalf("listed %d vacancies, want %d", len(response.Vacancie), size)
+		}
+		queries := len(db.Queries())
+		if want < 0 {
+			want = queries
+		}
+		if queries != want {
+			t.Fatalf("page of %d sent %d queries, want %d:\n%s", size, queries, want, strings.Join(db.Queries(), "\n"))
+		}
+	}
+}
+
+func BenchmarkListVacancies(b *testing.B) {
+	for _, pageSize := range listPageSizes {
+		b.Run(strconv.Itoa(pageSize), func(b *testing.B) {
+			size := pageSize
+			db := testdb.Open(b, vacancyRows(&size))
+			service := newTestVacancyService(db)
+			req := dto.ListVacancyRequest{Total: dto.VacancyTotalExact, Limit: size}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				db.Reset()
+				if _, err := service.ListVacancies(context.Background(), req); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(db.Queries())), "queries/op")
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+	"github.com/aidosgal/alem.core-service/internal/repository"
+)
+
+// VacancyTemplateService keeps reusable vacancies of organizations. A
+// template is visible to its author and, once shared, to everyone in the
+// organization; only the author changes or deletes it.
+type VacancyTemplateService struct {
+	log     *slog.Logger
+	repo    *repository.VacancyTemplateRepository
+	vacancy *VacancyService
+}
+
+func NewVacancyTemplateService(log *slog.Logger, repo *repository.VacancyTemplateRepository, vacancy *VacancyService) *VacancyTemplateService {
+	return &VacancyTemplateService{
+		log:     log,
+		repo:    repo,
+		vacancy: vacancy,
+	}
+}
+
+func (s *VacancyTemplateService) CreateTemplate(ctx context.Context, organizationID, userID int64, req dto.CreateVacancyTemplate) (*dto.VacancyTemplate, error) {
+	if organizationID == 0 {
+		return nil, model.ErrVacancyForbidden
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", model.ErrInvalidVacancyTemplate)
+	}
+	content, err := json.Marshal(templateVacancy(req.Vacancy))
+	if err != nil {
+		return nil, err
+	}
+
+	template := &model.VacancyTemplate{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Name:           name,
+		Shared:         req.Shared,
+		Content:        content,
+	}
+	if err := s.repo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Vacancy template created", slog.Int64("id", template.ID), slog.Int64("organization_id", organizationID))
+	return toVacancyTemplateResponse(*template)
+}
+
+// SaveVacancyAsTemplate creates a template from one of the organization's
+// vacancies, as it was written in its primary locale.
+func (s *VacancyTemplateService) SaveVacancyAsTemplate(ctx context.Context, vacancyID, organizationID, userID int64, req dto.SaveVacancyAsTemplate) (*dto.VacancyTemplate, error) {
+	vacancy, err := s.vacancy.GetVacancySource(ctx, vacancyID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.CreateTemplate(ctx, organizationID, userID, dto.CreateVacancyTemplate{
+		Name:    req.Name,
+		Shared:  req.Shared,
+		Vacancy: *vacancy,
+	})
+}
+
+func (s *VacancyTemplateService) ListTemplates(ctx context.Context, organizationID, userID int64) ([]dto.VacancyTemplate, error) {
+	templates, err := s.repo.List(ctx, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.VacancyTemplate{}
+	for _, t := range templates {
+		response, err := toVacancyTemplateResponse(t)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *response)
+	}
+	return result, nil
+}
+
+// GetTemplate returns the template in the given version, or in its latest
+// one when version is 0.
+func (s *VacancyTemplateService) GetTemplate(ctx context.Context, id, organizationID, userID int64, version int) (*dto.VacancyTemplate, error) {
+	template, err := s.visibleTemplate(ctx, id, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.selectVersion(ctx, template, version); err != nil {
+		return nil, err
+	}
+	return toVacancyTemplateResponse(*template)
+}
+
+// UpdateTemplate renames, shares or unshares the template. A new vacancy
+// is saved as the next version; earlier versions stay available.
+func (s *VacancyTemplateService) UpdateTemplate(ctx context.Context, id, organizationID, userID int64, req dto.UpdateVacancyTemplate) (*dto.VacancyTemplate, error) {
+	template, err := s.ownTemplate(ctx, id, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name is required", model.ErrInvalidVacancyTemplate)
+		}
+		template.Name = name
+	}
+	if req.Shared != nil {
+		template.Shared = *req.Shared
+	}
+	var content []byte
+	if req.Vacancy != nil {
+		if content, err = json.Marshal(templateVacancy(*req.Vacancy)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Update(ctx, template, userID, content); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Vacancy template updated", slog.Int64("id", id), slog.Int("version", template.Version))
+	return toVacancyTemplateResponse(*template)
+}
+
+func (s *VacancyTemplateService) ListTemplateVersions(ctx context.Context, id, organizationID, userID int64) ([]dto.VacancyTemplateVersion, error) {
+	if _, err := s.visibleTemplate(ctx, id, organizationID, userID); err != nil {
+		return nil, err
+	}
+	versions, err := s.repo.ListVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.VacancyTemplateVersion{}
+	for _, v := range versions {
+		result = append(result, dto.VacancyTemplateVersion{Version: v.Version, AuthorID: v.UserID, CreatedAt: v.CreatedAt})
+	}
+	return result, nil
+}
+
+func (s *VacancyTemplateService) DeleteTemplate(ctx context.Context, id, organizationID, userID int64) error {
+	if _, err := s.ownTemplate(ctx, id, organizationID, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// CreateVacancyFromTemplate creates a vacancy of the organization from a
+// version of the template with the overrides merged in. The vacancy goes
+// through the same checks as one created directly.
+func (s *VacancyTemplateService) CreateVacancyFromTemplate(ctx context.Context, id, organizationID, userID int64, req dto.CreateVacancyFromTemplate) (*dto.CreateVacancyResponse, error) {
+	template, err := s.visibleTemplate(ctx, id, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.selectVersion(ctx, template, req.Version); err != nil {
+		return nil, err
+	}
+
+	content := []byte(template.Content)
+	if len(req.Overrides) > 0 && string(req.Overrides) != "null" {
+		if content, err = mergeJSONPatch(content, req.Overrides); err != nil {
+			return nil, fmt.Errorf("%w: %v", model.ErrInvalidVacancyTemplate, err)
+		}
+	}
+	var vacancy dto.Vacancy
+	if err := json.Unmarshal(content, &vacancy); err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidVacancyTemplate, err)
+	}
+	vacancy.ID = 0
+	vacancy.OrganizationID = organizationID
+	for i := range vacancy.Details {
+		vacancy.Details[i].ID = 0
+	}
+
+	response, err := s.vacancy.CreateVacancy(ctx, dto.CreateVacancyRequest{Vacancy: vacancy})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Vacancy created from template", slog.Int64("template_id", id), slog.Int("version", template.Version),
+		slog.Int64("vacancy_id", response.Vacancy.ID))
+	return response, nil
+}
+
+// visibleTemplate returns a template of the organization that the user
+// wrote or that is shared.
+func (s *VacancyTemplateService) visibleTemplate(ctx context.Context, id, organizationID, userID int64) (*model.VacancyTemplate, error) {
+	if organizationID == 0 {
+		return nil, model.ErrVacancyForbidden
+	}
+	template, err := s.repo.GetByID(ctx, id, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil || (!template.Shared && template.UserID != userID) {
+		return nil, model.ErrVacancyTemplateNotFound
+	}
+	return template, nil
+}
+
+func (s *VacancyTemplateService) ownTemplate(ctx context.Context, id, organizationID, userID int64) (*model.VacancyTemplate, error) {
+	template, err := s.visibleTemplate(ctx, id, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, model.ErrVacancyTemplateForbidden
+	}
+	return template, nil
+}
+
+// selectVersion replaces the content of the template with an earlier
+// version. 0 keeps the latest one.
+func (s *VacancyTemplateService) selectVersion(ctx context.Context, template *model.VacancyTemplate, version int) error {
+	if version == 0 || version == template.Version {
+		return nil
+	}
+	v, err := s.repo.GetVersion(ctx, template.ID, version)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return fmt.Errorf("%w: version %d", model.ErrVacancyTemplateNotFound, version)
+	}
+	template.Version = v.Version
+	template.Content = v.Content
+	return nil
+}
+
+// templateVacancy keeps the fields of a vacancy that are copied into new
+// ones. Identifiers, status and dates belong to a single vacancy.
+func templateVacancy(v dto.Vacancy) dto.Vacancy {
+	template := dto.Vacancy{
+		Title:          v.Title,
+		Description:    v.Description,
+		SalaryFrom:     v.SalaryFrom,
+		SalaryTo:       v.SalaryTo,
+		SalaryExact:    v.SalaryExact,
+		SalaryType:     v.SalaryType,
+		SalaryCurrency: v.SalaryCurrency,
+		CategoryID:     v.CategoryID,
+		Country:        v.Country,
+		CountryCode:    v.CountryCode,
+		RegionID:       v.RegionID,
+		CityID:         v.CityID,
+		Latitude:       v.Latitude,
+		Longitude:      v.Longitude,
+		Details:        []dto.VacancyDetailResponse{},
+
+		PrimaryLocale: v.PrimaryLocale,
+	}
+	for _, d := range v.Details {
+		template.Details = append(template.Details, dto.VacancyDetailResponse{
+			GroupName: d.GroupName,
+			Name:      d.Name,
+			Value:     d.Value,
+			IconURL:   d.IconURL,
+			Key:       d.Key,
+		})
+	}
+	for _, t := range v.Translations {
+		template.Translations = append(template.Translations, dto.VacancyTranslation{
+			Locale:      t.Locale,
+			Title:       t.Title,
+			Description: t.Description,
+		})
+	}
+	return template
+}
+
+// mergeJSONPatch applies patch to the JSON document as described in
+// RFC 7396.
+func mergeJSONPatch(document, patch []byte) ([]byte, error) {
+	var target, changes any
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatchValue(target, changes))
+}
+
+func mergePatchValue(target, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]any)
+	if !ok {
+		result = map[string]any{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = mergePatchValue(result[key], value)
+	}
+	return result
+}
+
+func toVacancyTemplateResponse(t model.VacancyTemplate) (*dto.VacancyTemplate, error) {
+	response := &dto.VacancyTemplate{
+		ID:        t.ID,
+		Name:      t.Name,
+		Shared:    t.Shared,
+		AuthorID:  t.UserID,
+		Version:   t.Version,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+	if err := json.Unmarshal(t.Content, &response.Vacancy); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/lib"
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+// ListVacancyTranslations returns the organization's vacancy in every
+// locale it is available in, starting with the primary one, including
+// machine drafts.
+func (s *VacancyService) ListVacancyTranslations(ctx context.Context, id, organizationID int64) ([]dto.VacancyTranslation, error) {
+	vacancy, err := s.ownedVacancy(ctx, id, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.translation.ListByVacancy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []dto.VacancyTranslation{{
+		Locale:      vacancy.PrimaryLocale,
+		Title:       vacancy.Title,
+		Description: vacancy.Description,
+		Primary:     true,
+		UpdatedAt:   vacancy.UpdatedAt,
+	}}
+	for _, t := range translations {
+		if t.Locale == vacancy.PrimaryLocale {
+			continue
+		}
+		result = append(result, toVacancyTranslationResponse(t))
+	}
+	return result, nil
+}
+
+// UpsertVacancyTranslation saves the employer's text of the vacancy in a
+// locale, replacing any machine draft. Translations go through the risk
+// rules together with the vacancy text; a high-risk translation sends a
+// live vacancy to moderation in the same transaction as it is saved.
+func (s *VacancyService) UpsertVacancyTranslation(ctx context.Context, id, organizationID int64, locale string, req dto.UpsertVacancyTranslation) (*dto.VacancyTranslation, error) {
+	vacancy, err := s.ownedVacancy(ctx, id, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := newVacancyTranslations(vacancy.PrimaryLocale, []dto.VacancyTranslation{{
+		Locale:      locale,
+		Title:       req.Title,
+		Description: req.Description,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	translations[0].VacancyID = id
+
+	assessment, err := s.assessRisk(ctx, vacancy, []string{translations[0].Title, translations[0].Description})
+	if err != nil {
+		return nil, err
+	}
+	// The stored score also covers the details, which are not assessed
+	// again here, so the riskier of the two is kept.
+	assessment.Score = max(assessment.Score, vacancy.RiskScore)
+
+	write := model.VacancyWrite{Translations: translations, RiskScore: vacancy.RiskScore}
+	if s.isHighRisk(assessment) {
+		assessmentWrite(&write, vacancy, assessment, true)
+	}
+	if err := s.vacancy.Write(ctx, vacancy, write); err != nil {
+		return nil, err
+	}
+	s.reportModeration(ctx, vacancy, write)
+
+	s.log.Info("Vacancy translation saved", slog.Int64("id", id), slog.String("locale", locale))
+	return s.vacancyTranslation(ctx, id, locale)
+}
+
+// DraftVacancyTranslation fills in a machine translation of the vacancy
+// for the employer to review. Translations the employer has saved are not
+// replaced.
+func (s *VacancyService) DraftVacancyTranslation(ctx context.Context, id, organizationID int64, locale string) (*dto.VacancyTranslation, error) {
+	vacancy, err := s.ownedVacancy(ctx, id, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !lib.IsSupportedLocale(locale) {
+		return nil, fmt.Errorf("%w: %s", model.ErrUnsupportedLocale, locale)
+	}
+	if locale == vacancy.PrimaryLocale {
+		return nil, model.ErrPrimaryLocaleTranslation
+	}
+
+	existing, err := s.vacancyTranslation(ctx, id, locale)
+	if err != nil && !errors.Is(err, model.ErrVacancyTranslationNotFound) {
+		return nil, err
+	}
+	if existing != nil && !existing.Machine {
+		return nil, model.ErrVacancyTranslationEdited
+	}
+
+	texts, err := s.translator.Translate(ctx, vacancy.PrimaryLocale, locale, []string{vacancy.Title, vacancy.Description})
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate vacancy: %w", err)
+	}
+	if len(texts) != 2 {
+		return nil, fmt.Errorf("failed to translate vacancy: got %d texts", len(texts))
+	}
+
+	draft := model.VacancyTranslation{
+		VacancyID:   id,
+		Locale:      locale,
+		Title:       texts[0],
+		Description: texts[1],
+		Machine:     true,
+	}
+	if err := s.translation.Upsert(ctx, []model.VacancyTranslation{draft}); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Vacancy translation drafted", slog.Int64("id", id), slog.String("from", vacancy.PrimaryLocale), slog.String("to", locale))
+	return s.vacancyTranslation(ctx, id, locale)
+}
+
+func (s *VacancyService) DeleteVacancyTranslation(ctx context.Context, id, organizationID int64, locale string) error {
+	vacancy, err := s.ownedVacancy(ctx, id, organizationID)
+	if err != nil {
+		return err
+	}
+	if locale == vacancy.PrimaryLocale {
+		return model.ErrPrimaryLocaleTranslation
+	}
+	deleted, err := s.translation.Delete(ctx, id, locale)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return model.ErrVacancyTranslationNotFound
+	}
+	return nil
+}
+
+func (s *VacancyService) vacancyTranslation(ctx context.Context, id int64, locale string) (*dto.VacancyTranslation, error) {
+	translations, err := s.translation.ListByVacancy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range translations {
+		if t.Locale == locale {
+			response := toVacancyTranslationResponse(t)
+			return &response, nil
+		}
+	}
+	return nil, model.ErrVacancyTranslationNotFound
+}
+
+// localizedText is the title and description of a vacancy in Locale.
+type localizedText struct {
+	Locale      string
+	Title       string
+	Description string
+}
+
+// localizeVacancies picks the text of each vacancy in the first locale
+// preferred by the request that it is available in, falling back to its
+// primary locale. Machine drafts are never shown.
+func (s *VacancyService) localizeVacancies(ctx context.Context, vacancies []model.Vacancy) (map[int64]localizedText, error) {
+	locales := lib.LocalesFromContext(ctx)
+	ids := make([]int64, 0, len(vacancies))
+	for _, v := range vacancies {
+		ids = append(ids, v.ID)
+	}
+	translations, err := s.translation.FindByVacancies(ctx, ids, locales)
+	if err != nil {
+		return nil, err
+	}
+
+	localized := make(map[int64]localizedText, len(vacancies))
+	for _, v := range vacancies {
+		text := localizedText{Locale: v.PrimaryLocale, Title: v.Title, Description: v.Description}
+		for _, locale := range locales {
+			if locale == v.PrimaryLocale {
+				break
+			}
+			if t, ok := translations[v.ID][locale]; ok && !t.Machine {
+				text = localizedText{Locale: t.Locale, Title: t.Title, Description: t.Description}
+				break
+			}
+		}
+		localized[v.ID] = text
+	}
+	return localized, nil
+}
+
+// newVacancyTranslations validates translations written by the employer.
+func newVacancyTranslations(primaryLocale string, translations []dto.VacancyTranslation) ([]model.VacancyTranslation, error) {
+	result := make([]model.VacancyTranslation, 0, len(translations))
+	seen := make(map[string]bool)
+	for _, t := range translations {
+		if !lib.IsSupportedLocale(t.Locale) {
+			return nil, fmt.Errorf("%w: %s", model.ErrUnsupportedLocale, t.Locale)
+		}
+		if t.Locale == primaryLocale {
+			return nil, model.ErrPrimaryLocaleTranslation
+		}
+		if seen[t.Locale] {
+			return nil, fmt.Errorf("%w: %s is translated twice", model.ErrInvalidVacancyTranslation, t.Locale)
+		}
+		seen[t.Locale] = true
+
+		title, description := strings.TrimSpace(t.Title), strings.TrimSpace(t.Description)
+		if title == "" || description == "" {
+			return nil, fmt.Errorf("%w: %s needs a title and a description", model.ErrInvalidVacancyTranslation, t.Locale)
+		}
+		result = append(result, model.VacancyTranslation{Locale: t.Locale, Title: title, Description: description})
+	}
+	return result, nil
+}
+
+func toVacancyTranslationResponse(t model.VacancyTranslation) dto.VacancyTranslation {
+	updatedAt := t.UpdatedAt
+	return dto.VacancyTranslation{
+		Locale:      t.Locale,
+		Title:       t.Title,
+		Description: t.Description,
+		Machine:     t.Machine,
+		UpdatedAt:   &updatedAt,
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/aidosgal/alem.core-service/internal/dto"
+	"github.com/aidosgal/alem.core-service/internal/model"
+)
+
+// ListVacancyVersions returns the change history of a vacancy, oldest first.
+// Employers see it for their own vacancies; moderators for every vacancy.
+func (s *VacancyService) ListVacancyVersions(ctx context.Context, id, organizationID int64, moderator bool) ([]dto.VacancyVersion, error) {
+	if err := s.versionedVacancy(ctx, id, organizationID, moderator); err != nil {
+		return nil, err
+	}
+	versions, err := s.vacancy.ListVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.VacancyVersion, 0, len(versions))
+	var previous []byte
+	for _, v := range versions {
+		version, err := toVacancyVersionResponse(v, previous)
+		if err != nil {
+			return nil, err
+		}
+		response = append(response, *version)
+		previous = v.Content
+	}
+	return response, nil
+}
+
+// GetVacancyVersion returns a version of a vacancy with its content and the
+// changes since the version before it.
+func (s *VacancyService) GetVacancyVersion(ctx context.Context, id, organizationID int64, version int, moderator bool) (*dto.VacancyVersionResponse, error) {
+	if err := s.versionedVacancy(ctx, id, organizationID, moderator); err != nil {
+		return nil, err
+	}
+	current, err := s.vacancy.GetVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	var previous []byte
+	if version > 1 {
+		before, err := s.vacancy.GetVersion(ctx, id, version-1)
+		if err != nil && !errors.Is(err, model.ErrVacancyVersionNotFound) {
+			return nil, err
+		}
+		if before != nil {
+			previous = before.Content
+		}
+	}
+
+	response, err := toVacancyVersionResponse(*current, previous)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.VacancyVersionResponse{VacancyVersion: *response, Vacancy: current.Content}, nil
+}
+
+func (s *VacancyService) versionedVacancy(ctx context.Context, id, organizationID int64, moderator bool) error {
+	if moderator {
+		vacancy, err := s.vacancy.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if vacancy == nil {
+			return model.ErrVacancyNotFound
+		}
+		return nil
+	}
+	_, err := s.ownedVacancy(ctx, id, organizationID)
+	return err
+}
+
+// newVacancyChange snapshots the vacancy before and after an update.
+func (s *VacancyService) newVacancyChange(ctx context.Context, existing, updated *model.Vacancy, details []model.VacancyDetail, authorID int64) (*model.VacancyChange, error) {
+	stored, err := s.detail.GetByVacancyID(ctx, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := newVacancySnapshot(existing, stored)
+	if err != nil {
+		return nil, err
+	}
+	current, err := newVacancySnapshot(updated, details)
+	if err != nil {
+		return nil, err
+	}
+	return &model.VacancyChange{UserID: authorID, Previous: previous, Current: current}, nil
+}
+
+// newVacancySnapshot encodes the content of the vacancy and its details for
+// the version history.
+func newVacancySnapshot(vacancy *model.Vacancy, details []model.VacancyDetail) ([]byte, error) {
+	snapshot := model.VacancySnapshot{
+		Title:          vacancy.Title,
+		Description:    vacancy.Description,
+		PrimaryLocale:  vacancy.PrimaryLocale,
+		SalaryFrom:     vacancy.SalaryFrom,
+		SalaryTo:       vacancy.SalaryTo,
+		SalaryExact:    vacancy.SalaryExact,
+		SalaryType:     vacancy.SalaryType,
+		SalaryCurrency: vacancy.SalaryCurrency,
+		CategoryID:     vacancy.CategoryID,
+		Country:        vacancy.Country,
+		CountryCode:    vacancy.CountryCode,
+		RegionID:       vacancy.RegionID,
+		CityID:         vacancy.CityID,
+		Latitude:       vacancy.Latitude,
+		Longitude:      vacancy.Longitude,
+		Details:        make([]model.VacancySnapshotDetail, 0, len(details)),
+	}
+	for _, d := range details {
+		snapshot.Details = append(snapshot.Details, model.VacancySnapshotDetail{
+			GroupName: d.GroupName,
+			Name:      d.Name,
+			Value:     d.Value,
+			Key:       d.AttributeKey,
+		})
+	}
+	return json.Marshal(snapshot)
+}
+
+func toVacancyVersionResponse(version model.VacancyVersion, previous []byte) (*dto.VacancyVersion, error) {
+	changes := []dto.VacancyFieldChange{}
+	if previous != nil {
+		var err error
+		changes, err = diffVacancySnapshots(previous, version.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &dto.VacancyVersion{
+		Version:   version.Version,
+		AuthorID:  version.UserID,
+		CreatedAt: version.CreatedAt,
+		Changes:   changes,
+	}, nil
+}
+
+// diffVacancySnapshots lists the fields that differ between two snapshots,
+// sorted by field name.
+func diffVacancySnapshots(from, to []byte) ([]dto.VacancyFieldChange, error) {
+	before, err := flattenVacancySnapshot(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flattenVacancySnapshot(to)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(before)+len(after))
+	for field := range before {
+		fields = append(fields, field)
+	}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []dto.VacancyFieldChange{}
+	for _, field := range fields {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			changes = append(changes, dto.VacancyFieldChange{Field: field, From: before[field], To: after[field]})
+		}
+	}
+	return changes, nil
+}
+
+// flattenVacancySnapshot maps the fields of a snapshot by name. Details are
+// named after their attribute key or their group and name; repeated names
+// get a #n suffix.
+func flattenVacancySnapshot(content []byte) (map[string]any, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "details")
+
+	var snapshot model.VacancySnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, err
+	}
+	seen := map[string]int{}
+	for _, d := range snapshot.Details {
+		field := "details." + d.GroupName + "." + d.Name
+		if d.Key != nil && *d.Key != "" {
+			field = "details." + *d.Key
+		}
+		seen[field]++
+		if n := seen[field]; n > 1 {
+			field = fmt.Sprintf("%s#%d", field, n)
+		}
+		fields[field] = d.Value
+	}
+	return fields, nil
+}
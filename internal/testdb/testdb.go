@@ -0,0 +1,138 @@
+// Package testdb is an in-memory database/sql driver for tests. Every
+// statement is answered by a Handler, so tests can fake the few queries a
+// repository sends and count how many were sent.
+package testdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Result answers a statement: Columns and Rows for queries, RowsAffected
+// for other statements.
+type Result struct {
+	Columns      []string
+	Rows         [][]driver.Value
+	RowsAffected int64
+}
+
+// Handler answers a statement. The query has its whitespace collapsed to
+// single spaces.
+type Handler func(query string, args []driver.Value) (*Result, error)
+
+type DB struct {
+	*sql.DB
+
+	mu      sync.Mutex
+	handler Handler
+	queries []string
+}
+
+// Open returns a database answered by handler. It is closed with the test.
+func Open(t testing.TB, handler Handler) *DB {
+	db := &DB{handler: handler}
+	db.DB = sql.OpenDB(connector{db})
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// Queries returns the statements sent so far, without transaction control.
+func (db *DB) Queries() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.queries...)
+}
+
+// Reset forgets the statements sent so far.
+func (db *DB) Reset() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.queries = nil
+}
+
+func (db *DB) run(query string, args []driver.NamedValue) (*Result, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	db.mu.Lock()
+	db.queries = append(db.queries, query)
+	db.mu.Unlock()
+
+	result, err := db.handler(query, values)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("testdb: unexpected query: %s", query)
+	}
+	return result, nil
+}
+
+type connector struct{ db *DB }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return conn{c.db}, nil }
+func (c connector) Driver() driver.Driver                        { return nil }
+
+type conn struct{ db *DB }
+
+func (c conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("testdb: prepared statements are not supported")
+}
+func (c conn) Close() error              { return nil }
+func (c conn) Begin() (driver.Tx, error) { return tx{}, nil }
+
+func (c conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{result: result}, nil
+}
+
+func (c conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result, err := c.db.run(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(result.RowsAffected), nil
+}
+
+// CheckNamedValue converts arguments like a real driver would and passes
+// anything else through unchanged, so tests see what a repository sends.
+func (c conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value); err == nil {
+		nv.Value = value
+	}
+	return nil
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type rows struct {
+	result *Result
+	next   int
+}
+
+func (r *rows) Columns() []string { return r.result.Columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.Rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.Rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_categories_parent_id;
+DROP INDEX IF EXISTS idx_categories_lft_rgt;
+
+ALTER TABLE categories
+DROP COLUMN parent_id;
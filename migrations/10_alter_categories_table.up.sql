@@ -0,0 +1,16 @@
+ALTER TABLE categories
+ADD COLUMN parent_id INT NULL REFERENCES categories(id);
+
+-- Derive the adjacency list from the existing nested set: the parent is the
+-- closest enclosing node.
+UPDATE categories c
+SET parent_id = (
+    SELECT p.id
+    FROM categories p
+    WHERE p.lft < c.lft AND p.rgt > c.rgt
+    ORDER BY p.lft DESC
+    LIMIT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_lft_rgt ON categories (lft, rgt);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
@@ -0,0 +1,12 @@
+-- categories.name keeps the name in the default locale (ru); this table holds
+-- the other languages.
+CREATE TABLE IF NOT EXISTS category_translations (
+    category_id INT NOT NULL,
+    locale VARCHAR(8) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (category_id, locale),
+    CONSTRAINT fk_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_vacancies_organization_id;
+DROP INDEX IF EXISTS idx_vacancies_status_expires_at;
+
+ALTER TABLE vacancies
+DROP CONSTRAINT chk_vacancy_status,
+DROP COLUMN expiry_warned_at,
+DROP COLUMN expires_at,
+DROP COLUMN published_at,
+DROP COLUMN status;
@@ -0,0 +1,16 @@
+ALTER TABLE vacancies
+ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'draft',
+ADD COLUMN published_at TIMESTAMP NULL,
+ADD COLUMN expires_at TIMESTAMP NULL,
+ADD COLUMN expiry_warned_at TIMESTAMP NULL;
+
+ALTER TABLE vacancies
+ADD CONSTRAINT chk_vacancy_status CHECK (
+    status IN ('draft', 'pending_moderation', 'published', 'paused', 'closed', 'expired')
+);
+
+-- Everything created before statuses existed was live.
+UPDATE vacancies SET status = 'published', published_at = created_at;
+
+CREATE INDEX IF NOT EXISTS idx_vacancies_status_expires_at ON vacancies (status, expires_at);
+CREATE INDEX IF NOT EXISTS idx_vacancies_organization_id ON vacancies (organization_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_vacancies_deleted_at;
+
+ALTER TABLE vacancies
+DROP COLUMN IF EXISTS deleted_at;
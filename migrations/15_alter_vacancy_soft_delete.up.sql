@@ -0,0 +1,4 @@
+ALTER TABLE vacancies
+ADD COLUMN deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_vacancies_deleted_at ON vacancies (deleted_at) WHERE deleted_at IS NOT NULL;
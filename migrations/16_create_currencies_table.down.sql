@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_vacancies_salary_to_base;
+DROP INDEX IF EXISTS idx_vacancies_salary_from_base;
+
+ALTER TABLE vacancies
+DROP COLUMN IF EXISTS salary_exact_base,
+DROP COLUMN IF EXISTS salary_to_base,
+DROP COLUMN IF EXISTS salary_from_base,
+DROP CONSTRAINT IF EXISTS chk_vacancy_salary_type,
+ALTER COLUMN salary_type DROP DEFAULT;
+
+DROP FUNCTION IF EXISTS monthly_salary_base(NUMERIC, VARCHAR, VARCHAR);
+
+DROP TABLE IF EXISTS currencies;
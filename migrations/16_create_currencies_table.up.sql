@@ -0,0 +1,63 @@
+-- rate is the number of currency units worth one unit of the base currency
+-- (EUR), so amount / rate converts into the base currency.
+CREATE TABLE currencies (
+    code CHAR(3) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    rate NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO currencies (code, name, rate) VALUES
+    ('EUR', 'Euro', 1),
+    ('USD', 'US Dollar', 1.08),
+    ('GBP', 'Pound Sterling', 0.85),
+    ('PLN', 'Polish Zloty', 4.30),
+    ('TRY', 'Turkish Lira', 37.00),
+    ('RUB', 'Russian Ruble', 100.00),
+    ('KZT', 'Kazakhstani Tenge', 520.00),
+    ('UZS', 'Uzbekistani Som', 13800.00),
+    ('KGS', 'Kyrgyzstani Som', 94.00);
+
+-- salary_type used to be free text; fold the spellings we have seen into the
+-- pay periods we support and treat everything else as monthly.
+UPDATE vacancies SET salary_type = CASE
+    WHEN LOWER(TRIM(salary_type)) IN ('hour', 'hourly', 'per_hour', 'час') THEN 'hour'
+    WHEN LOWER(TRIM(salary_type)) IN ('day', 'daily', 'per_day', 'день') THEN 'day'
+    WHEN LOWER(TRIM(salary_type)) IN ('week', 'weekly', 'per_week', 'неделя') THEN 'week'
+    WHEN LOWER(TRIM(salary_type)) IN ('year', 'yearly', 'annual', 'per_year', 'год') THEN 'year'
+    ELSE 'month'
+END;
+
+UPDATE vacancies SET salary_currency = UPPER(TRIM(salary_currency));
+
+ALTER TABLE vacancies
+ALTER COLUMN salary_type SET DEFAULT 'month',
+ADD CONSTRAINT chk_vacancy_salary_type CHECK (salary_type IN ('hour', 'day', 'week', 'month', 'year')),
+ADD COLUMN salary_from_base NUMERIC(14, 2) NULL,
+ADD COLUMN salary_to_base NUMERIC(14, 2) NULL,
+ADD COLUMN salary_exact_base NUMERIC(14, 2) NULL;
+
+-- monthly_salary_base converts an amount paid per salary_type in currency into
+-- a monthly amount in the base currency. It returns NULL for unknown
+-- currencies.
+CREATE OR REPLACE FUNCTION monthly_salary_base(amount NUMERIC, salary_type VARCHAR, currency VARCHAR)
+RETURNS NUMERIC AS $$
+    SELECT ROUND(amount * CASE salary_type
+        WHEN 'hour' THEN 160
+        WHEN 'day' THEN 21
+        WHEN 'week' THEN 4.33
+        WHEN 'year' THEN 1.0 / 12
+        ELSE 1
+    END / c.rate, 2)
+    FROM currencies c
+    WHERE c.code = currency
+$$ LANGUAGE SQL STABLE;
+
+UPDATE vacancies SET
+    salary_from_base = monthly_salary_base(salary_from, salary_type, salary_currency),
+    salary_to_base = monthly_salary_base(salary_to, salary_type, salary_currency),
+    salary_exact_base = monthly_salary_base(salary_exact, salary_type, salary_currency);
+
+CREATE INDEX IF NOT EXISTS idx_vacancies_salary_from_base ON vacancies (salary_from_base);
+CREATE INDEX IF NOT EXISTS idx_vacancies_salary_to_base ON vacancies (salary_to_base);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_vacancies_city_id;
+DROP INDEX IF EXISTS idx_vacancies_region_id;
+DROP INDEX IF EXISTS idx_vacancies_country_code;
+
+ALTER TABLE vacancies
+DROP COLUMN IF EXISTS city_id,
+DROP COLUMN IF EXISTS region_id,
+DROP COLUMN IF EXISTS country_code;
+
+DROP TABLE IF EXISTS cities;
+DROP TABLE IF EXISTS regions;
+DROP TABLE IF EXISTS countries;
@@ -0,0 +1,35 @@
+-- Reference data is seeded at startup from internal/service/data/locations.json.
+CREATE TABLE countries (
+    code CHAR(2) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE regions (
+    id SERIAL PRIMARY KEY,
+    country_code CHAR(2) NOT NULL REFERENCES countries(code) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_regions_country_name UNIQUE (country_code, name)
+);
+
+CREATE TABLE cities (
+    id SERIAL PRIMARY KEY,
+    region_id INT NOT NULL REFERENCES regions(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    latitude DOUBLE PRECISION NULL,
+    longitude DOUBLE PRECISION NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_cities_region_name UNIQUE (region_id, name)
+);
+
+ALTER TABLE vacancies
+ADD COLUMN country_code CHAR(2) NULL REFERENCES countries(code),
+ADD COLUMN region_id INT NULL REFERENCES regions(id),
+ADD COLUMN city_id INT NULL REFERENCES cities(id);
+
+CREATE INDEX IF NOT EXISTS idx_vacancies_country_code ON vacancies (country_code);
+CREATE INDEX IF NOT EXISTS idx_vacancies_region_id ON vacancies (region_id);
+CREATE INDEX IF NOT EXISTS idx_vacancies_city_id ON vacancies (city_id);
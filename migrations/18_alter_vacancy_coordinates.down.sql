@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_vacancies_location;
+
+ALTER TABLE vacancies
+DROP CONSTRAINT IF EXISTS chk_vacancy_coordinates,
+DROP COLUMN IF EXISTS longitude,
+DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,21 @@
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+ALTER TABLE vacancies
+ADD COLUMN latitude DOUBLE PRECISION NULL,
+ADD COLUMN longitude DOUBLE PRECISION NULL,
+ADD CONSTRAINT chk_vacancy_coordinates CHECK (
+    (latitude IS NULL AND longitude IS NULL)
+    OR (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180)
+);
+
+-- Vacancies in a known city start out at the city's coordinates.
+UPDATE vacancies v SET latitude = c.latitude, longitude = c.longitude
+FROM cities c
+WHERE v.city_id = c.id AND v.latitude IS NULL;
+
+-- Radius search filters with earth_box(...) @> ll_to_earth(latitude, longitude),
+-- which this index serves.
+CREATE INDEX IF NOT EXISTS idx_vacancies_location ON vacancies
+USING gist (ll_to_earth(latitude, longitude))
+WHERE latitude IS NOT NULL;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}',
+    frequency VARCHAR(16) NOT NULL DEFAULT 'daily',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    -- The newest vacancy already reported, compared as (published_at, id).
+    last_published_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_vacancy_id BIGINT NOT NULL DEFAULT 0,
+    last_checked_at TIMESTAMP NULL,
+    unsubscribe_token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_saved_search_frequency CHECK (frequency IN ('instant', 'daily'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches (user_id);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_active ON saved_searches (frequency, last_checked_at) WHERE active;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS hidden_organizations;
+DROP TABLE IF EXISTS vacancy_favorites;
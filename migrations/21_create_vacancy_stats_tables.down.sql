@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS vacancy_daily_stats;
+DROP TABLE IF EXISTS vacancy_applications;
+DROP TABLE IF EXISTS vacancy_views;
@@ -0,0 +1,38 @@
+-- One row per user per vacancy per day; repeated views on the same day are
+-- not counted again.
+CREATE TABLE IF NOT EXISTS vacancy_views (
+    vacancy_id INT NOT NULL,
+    user_id INT NOT NULL,
+    viewed_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (vacancy_id, user_id, viewed_on),
+    CONSTRAINT fk_vacancy FOREIGN KEY (vacancy_id) REFERENCES vacancies(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS vacancy_applications (
+    id SERIAL PRIMARY KEY,
+    vacancy_id INT NOT NULL,
+    user_id INT NOT NULL,
+    resume_id INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_vacancy_applications_user UNIQUE (vacancy_id, user_id),
+    CONSTRAINT fk_vacancy FOREIGN KEY (vacancy_id) REFERENCES vacancies(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_resume FOREIGN KEY (resume_id) REFERENCES resumes(id) ON DELETE SET NULL
+);
+
+-- Daily counters kept up to date as views and applications are recorded.
+CREATE TABLE IF NOT EXISTS vacancy_daily_stats (
+    vacancy_id INT NOT NULL,
+    day DATE NOT NULL,
+    views INT NOT NULL DEFAULT 0,
+    applications INT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (vacancy_id, day),
+    CONSTRAINT fk_vacancy FOREIGN KEY (vacancy_id) REFERENCES vacancies(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_vacancy_daily_stats_day ON vacancy_daily_stats (day);
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS vacancy_imports (
+    id SERIAL PRIMARY KEY,
+    organization_id INT NOT NULL,
+    user_id INT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    format VARCHAR(8) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'validated',
+    total_rows INT NOT NULL DEFAULT 0,
+    valid_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    created_rows INT NOT NULL DEFAULT 0,
+    -- Per-row validation and commit errors.
+    errors JSONB NOT NULL DEFAULT '[]',
+    -- The valid rows waiting to be committed, in file order.
+    payload JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP NULL,
+    finished_at TIMESTAMP NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_vacancy_import_status CHECK (status IN ('validated', 'queued', 'running', 'completed', 'failed')),
+    CONSTRAINT chk_vacancy_import_format CHECK (format IN ('csv', 'xlsx', 'json'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_vacancy_imports_organization_id ON vacancy_imports (organization_id);
+CREATE INDEX IF NOT EXISTS idx_vacancy_imports_queued ON vacancy_imports (id) WHERE status IN ('queued', 'running');
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS moderation_blacklist;
+DROP TABLE IF EXISTS vacancy_moderation_log;
+
+DROP INDEX IF EXISTS idx_vacancies_moderation_queue;
+ALTER TABLE vacancies DROP COLUMN risk_score;
+
+UPDATE vacancies SET status = 'closed' WHERE status IN ('changes_requested', 'rejected');
+ALTER TABLE vacancies DROP CONSTRAINT chk_vacancy_status;
+ALTER TABLE vacancies
+ADD CONSTRAINT chk_vacancy_status CHECK (
+    status IN ('draft', 'pending_moderation', 'published', 'paused', 'closed', 'expired')
+);
@@ -0,0 +1,42 @@
+ALTER TABLE vacancies DROP CONSTRAINT chk_vacancy_status;
+ALTER TABLE vacancies
+ADD CONSTRAINT chk_vacancy_status CHECK (
+    status IN ('draft', 'pending_moderation', 'changes_requested', 'rejected', 'published', 'paused', 'closed', 'expired')
+);
+
+-- Risk score of the last automatic assessment of the vacancy.
+ALTER TABLE vacancies ADD COLUMN risk_score INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_vacancies_moderation_queue ON vacancies (risk_score DESC, created_at)
+    WHERE status = 'pending_moderation' AND deleted_at IS NULL;
+
+-- Every automatic flag and moderator decision, shown to the employer.
+CREATE TABLE IF NOT EXISTS vacancy_moderation_log (
+    id SERIAL PRIMARY KEY,
+    vacancy_id INT NOT NULL,
+    moderator_id INT NULL,
+    action VARCHAR(32) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    risk_score INT NOT NULL DEFAULT 0,
+    flags JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_vacancy FOREIGN KEY (vacancy_id) REFERENCES vacancies(id) ON DELETE CASCADE,
+    CONSTRAINT fk_moderator FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_moderation_action CHECK (action IN ('flagged', 'submitted', 'approved', 'rejected', 'changes_requested'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_vacancy_moderation_log_vacancy_id ON vacancy_moderation_log (vacancy_id, created_at);
+
+-- Phrases, link domains, phone numbers and e-mails that always flag a
+-- vacancy.
+CREATE TABLE IF NOT EXISTS moderation_blacklist (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_moderation_blacklist UNIQUE (kind, value),
+    CONSTRAINT chk_moderation_blacklist_kind CHECK (kind IN ('phrase', 'domain', 'phone', 'email'))
+);
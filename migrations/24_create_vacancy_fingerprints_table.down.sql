@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS vacancy_fingerprints;
+
+DROP INDEX IF EXISTS idx_vacancies_duplicate_cluster_id;
+ALTER TABLE vacancies DROP COLUMN duplicate_cluster_id;
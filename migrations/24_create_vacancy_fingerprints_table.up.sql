@@ -0,0 +1,20 @@
+-- Near-duplicate vacancies of different organizations share a cluster,
+-- named after the first vacancy that joined it.
+ALTER TABLE vacancies ADD COLUMN duplicate_cluster_id INT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_vacancies_duplicate_cluster_id ON vacancies (duplicate_cluster_id)
+    WHERE duplicate_cluster_id IS NOT NULL;
+
+-- MinHash signature of the normalized title and description. bands holds a
+-- hash of each band of the signature; vacancies sharing a band are
+-- candidate duplicates.
+CREATE TABLE IF NOT EXISTS vacancy_fingerprints (
+    vacancy_id INT PRIMARY KEY,
+    signature BIGINT[] NOT NULL,
+    bands BIGINT[] NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_vacancy FOREIGN KEY (vacancy_id) REFERENCES vacancies(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_vacancy_fingerprints_bands ON vacancy_fingerprints USING GIN (bands);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS vacancy_translations;
+
+ALTER TABLE vacancies DROP COLUMN primary_locale;
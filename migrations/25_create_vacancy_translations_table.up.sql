@@ -0,0 +1,18 @@
+-- vacancies.title and description are written in primary_locale; this
+-- table holds the other languages.
+ALTER TABLE vacancies ADD COLUMN primary_locale VARCHAR(8) NOT NULL DEFAULT 'ru';
+
+-- machine marks drafts filled in by the translator that the employer has
+-- not edited yet.
+CREATE TABLE IF NOT EXISTS vacancy_translations (
+    vacancy_id INT NOT NULL,
+    locale VARCHAR(8) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    machine BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (vacancy_id, locale),
+    CONSTRAINT fk_vacancy FOREIGN KEY (vacancy_id) REFERENCES vacancies(id) ON DELETE CASCADE
+);
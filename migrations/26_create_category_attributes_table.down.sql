@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_vacancy_details_attribute;
+DROP INDEX IF EXISTS uq_vacancy_details_attribute;
+ALTER TABLE vacancy_details
+    DROP COLUMN attribute_key,
+    DROP COLUMN bool_value,
+    DROP COLUMN number_value,
+    DROP COLUMN number_to;
+
+DROP TABLE IF EXISTS category_attributes;
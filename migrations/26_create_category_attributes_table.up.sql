@@ -0,0 +1,37 @@
+-- Typed attributes of the vacancies in a category and its subcategories.
+-- Keys have the same type in every category. options lists the values of
+-- an enum; min_value and max_value bound numbers and ranges.
+CREATE TABLE IF NOT EXISTS category_attributes (
+    id SERIAL PRIMARY KEY,
+    category_id INT NOT NULL,
+    key VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    options TEXT[] NOT NULL DEFAULT '{}',
+    unit VARCHAR(32) NOT NULL DEFAULT '',
+    min_value DOUBLE PRECISION NULL,
+    max_value DOUBLE PRECISION NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_category_attributes_key UNIQUE (category_id, key),
+    CONSTRAINT fk_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    CONSTRAINT chk_category_attribute_type CHECK (type IN ('boolean', 'enum', 'number', 'range'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_attributes_key ON category_attributes (key);
+
+-- A detail with an attribute_key holds a typed attribute. value keeps its
+-- text; the parsed value is stored in the typed columns for filtering, with
+-- number_value and number_to as the bounds of a range.
+ALTER TABLE vacancy_details
+    ADD COLUMN attribute_key VARCHAR(64) NULL,
+    ADD COLUMN bool_value BOOLEAN NULL,
+    ADD COLUMN number_value DOUBLE PRECISION NULL,
+    ADD COLUMN number_to DOUBLE PRECISION NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_vacancy_details_attribute ON vacancy_details (vacancy_id, attribute_key)
+    WHERE attribute_key IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_vacancy_details_attribute ON vacancy_details (attribute_key, LOWER(value))
+    WHERE attribute_key IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vacancy_template_versions;
+DROP TABLE IF EXISTS vacancy_templates;
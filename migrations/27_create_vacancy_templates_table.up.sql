@@ -0,0 +1,31 @@
+-- A template is a reusable vacancy of an organization. It is visible to its
+-- author and, when shared, to the whole organization. Every change of its
+-- content is kept as a new version; version points to the latest one.
+CREATE TABLE IF NOT EXISTS vacancy_templates (
+    id SERIAL PRIMARY KEY,
+    organization_id INT NOT NULL,
+    user_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_vacancy_templates_organization_id ON vacancy_templates (organization_id);
+
+-- content is the vacancy in the dto.Vacancy JSON shape.
+CREATE TABLE IF NOT EXISTS vacancy_template_versions (
+    template_id INT NOT NULL,
+    version INT NOT NULL,
+    user_id INT NOT NULL,
+    content JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (template_id, version),
+    CONSTRAINT fk_template FOREIGN KEY (template_id) REFERENCES vacancy_templates(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_vacancies_sort_salary_asc;
+DROP INDEX IF EXISTS idx_vacancies_sort_salary;
+DROP INDEX IF EXISTS idx_vacancies_sort_published;
+DROP INDEX IF EXISTS idx_vacancies_sort_newest;
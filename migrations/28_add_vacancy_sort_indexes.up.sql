@@ -0,0 +1,11 @@
+-- Keyset pagination walks the vacancy list by (sort key, id). The indexes
+-- repeat the sort keys of VacancyRepository.List exactly so that a page
+-- starts with an index scan instead of sorting every match.
+CREATE INDEX IF NOT EXISTS idx_vacancies_sort_newest ON vacancies
+    (COALESCE(created_at, '-infinity'::timestamp) DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_vacancies_sort_published ON vacancies
+    (COALESCE(published_at, '-infinity'::timestamp) DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_vacancies_sort_salary ON vacancies
+    (COALESCE((COALESCE(salary_exact_base, salary_to_base, salary_from_base))::float8, '-Infinity'::float8) DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_vacancies_sort_salary_asc ON vacancies
+    (COALESCE((COALESCE(salary_exact_base, salary_to_base, salary_from_base))::float8, 'Infinity'::float8), id) WHERE deleted_at IS NULL;
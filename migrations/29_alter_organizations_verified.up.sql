@@ -0,0 +1,4 @@
+-- Verified organizations have been checked by an administrator. Job seekers
+-- can narrow vacancy searches to them.
+ALTER TABLE organizations
+ADD COLUMN verified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,7 @@
+ALTER TABLE vacancy_applications
+DROP COLUMN IF EXISTS vacancy_version;
+
+DROP TABLE IF EXISTS vacancy_versions;
+
+ALTER TABLE vacancies
+DROP COLUMN IF EXISTS version;
@@ -0,0 +1,21 @@
+-- version counts the content changes of a vacancy. Every change stores the
+-- vacancy with its details as a snapshot; the first change also stores the
+-- state before it, without an author.
+ALTER TABLE vacancies
+ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS vacancy_versions (
+    vacancy_id INT NOT NULL,
+    version INT NOT NULL,
+    user_id INT NULL,
+    content JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (vacancy_id, version),
+    CONSTRAINT fk_vacancy FOREIGN KEY (vacancy_id) REFERENCES vacancies(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- The version of the vacancy the candidate saw when applying.
+ALTER TABLE vacancy_applications
+ADD COLUMN vacancy_version INT NULL;